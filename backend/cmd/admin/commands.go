@@ -0,0 +1,268 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/services"
+)
+
+func (a *app) run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "create-admin":
+		return a.createAdmin(ctx, args)
+	case "reset-password":
+		return a.resetPassword(ctx, args)
+	case "set-role":
+		return a.setRole(ctx, args)
+	case "list-users":
+		return a.listUsers(ctx, args)
+	case "list-tokens":
+		return a.listTokens(ctx, args)
+	case "revoke-token":
+		return a.revokeToken(ctx, args)
+	case "import":
+		return a.importArchive(ctx, args)
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+func (a *app) createAdmin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "email of the new admin")
+	password := fs.String("password", "", "password; generated when empty")
+	_ = fs.Parse(args)
+
+	if *email == "" {
+		return errors.New("create-admin: -email is required")
+	}
+	pw, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.auth.CreateAdmin(ctx, *email, pw)
+	if err != nil {
+		return fmt.Errorf("create-admin: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "created admin %s (%s)\n", user.Email, user.ID)
+	if generated {
+		fmt.Fprintf(a.out, "password: %s\n", pw)
+	}
+	return nil
+}
+
+func (a *app) resetPassword(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	email := fs.String("email", "", "email of the user")
+	password := fs.String("password", "", "new password; generated when empty")
+	_ = fs.Parse(args)
+
+	user, err := a.userByEmail(ctx, "reset-password", *email)
+	if err != nil {
+		return err
+	}
+	pw, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+
+	if err := a.auth.ResetPassword(ctx, user.ID, pw); err != nil {
+		return fmt.Errorf("reset-password: %w", err)
+	}
+	// a reset usually means the account was compromised, and its access
+	// tokens would outlive the old password
+	n, err := a.tokens.RevokeAllForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("reset-password: revoke access tokens: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "password reset for %s, revoked %d access token(s)\n", user.Email, n)
+	if generated {
+		fmt.Fprintf(a.out, "password: %s\n", pw)
+	}
+	return nil
+}
+
+func (a *app) setRole(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("set-role", flag.ExitOnError)
+	email := fs.String("email", "", "email of the user")
+	role := fs.String("role", "", "user or admin")
+	_ = fs.Parse(args)
+
+	user, err := a.userByEmail(ctx, "set-role", *email)
+	if err != nil {
+		return err
+	}
+
+	user, err = a.userSvc.SetRole(ctx, user.ID, *role)
+	if err != nil {
+		return fmt.Errorf("set-role: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "%s is now %s\n", user.Email, user.Role)
+	return nil
+}
+
+func (a *app) listUsers(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list-users", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	users, err := a.userSvc.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tROLE\tKIND\t2FA\tCREATED")
+	for _, u := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\n",
+			u.ID, u.Email, u.Role, u.Kind, u.TOTPEnabled, u.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func (a *app) listTokens(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list-tokens", flag.ExitOnError)
+	email := fs.String("email", "", "email of the token owner")
+	_ = fs.Parse(args)
+
+	user, err := a.userByEmail(ctx, "list-tokens", *email)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := a.tokens.ListByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES\tLAST USED\tSTATE")
+	for _, t := range tokens {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%s\t%s\n",
+			t.ID, t.Name, t.Prefix, []string(t.Scopes),
+			t.ExpiresAt.Format(time.RFC3339), formatOptionalTime(t.LastUsedAt), tokenState(t))
+	}
+	return w.Flush()
+}
+
+func (a *app) revokeToken(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("revoke-token", flag.ExitOnError)
+	id := fs.String("id", "", "token id to revoke")
+	email := fs.String("email", "", "owner whose tokens to revoke, with -all")
+	all := fs.Bool("all", false, "revoke every active token of -email")
+	_ = fs.Parse(args)
+
+	switch {
+	case *id != "" && !*all:
+		if err := a.tokens.Revoke(ctx, *id); err != nil {
+			return fmt.Errorf("revoke-token: %w", err)
+		}
+		fmt.Fprintf(a.out, "revoked token %s\n", *id)
+		return nil
+
+	case *id == "" && *all:
+		user, err := a.userByEmail(ctx, "revoke-token", *email)
+		if err != nil {
+			return err
+		}
+		n, err := a.tokens.RevokeAllForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("revoke-token: %w", err)
+		}
+		fmt.Fprintf(a.out, "revoked %d token(s) of %s\n", n, user.Email)
+		return nil
+
+	default:
+		return errors.New("revoke-token: pass either -id or -email with -all")
+	}
+}
+
+func (a *app) importArchive(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "export ZIP to import")
+	mode := fs.String("mode", string(services.ImportSkip), "skip or upsert rows whose id exists")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("import: -file is required")
+	}
+	zr, err := zip.OpenReader(*file)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer zr.Close()
+
+	archive, err := services.ParseExportArchive(&zr.Reader)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	report, err := a.importer.Import(ctx, archive, services.ImportOptions{
+		Mode:   services.ImportMode(*mode),
+		DryRun: *dryRun,
+	})
+	if report != nil {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	}
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	return nil
+}
+
+func (a *app) userByEmail(ctx context.Context, command, email string) (*models.User, error) {
+	if email == "" {
+		return nil, fmt.Errorf("%s: -email is required", command)
+	}
+	user, err := a.users.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: user %s: %w", command, email, err)
+	}
+	return user, nil
+}
+
+func passwordOrRandom(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), true, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func tokenState(t models.AccessToken) string {
+	switch {
+	case t.RevokedAt != nil:
+		return "revoked"
+	case !time.Now().Before(t.ExpiresAt):
+		return "expired"
+	default:
+		return "active"
+	}
+}
@@ -0,0 +1,134 @@
+// Command admin operates the platform directly against the database: it
+// bootstraps the first admin and handles account recovery when nobody can
+// sign in to do it through the API.
+//
+//	admin create-admin -email EMAIL [-password PASSWORD]
+//	admin reset-password -email EMAIL [-password PASSWORD]
+//	admin set-role -email EMAIL -role user|admin
+//	admin list-users
+//	admin list-tokens -email EMAIL
+//	admin revoke-token (-id TOKEN_ID | -email EMAIL -all)
+//	admin import -file EXPORT.zip [-mode skip|upsert] [-dry-run]
+//
+// Without -password a random password is generated and printed. The
+// configuration is loaded like the API's, from the environment or
+// CONFIG_FILE, and must pass the same validation.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+
+	"task-management-platform/backend/internal/cache"
+	"task-management-platform/backend/internal/config"
+	"task-management-platform/backend/internal/migrate"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/services"
+)
+
+const usage = `usage: admin <command> [flags]
+
+commands:
+  create-admin    create an admin account
+  reset-password  set a new password for a user
+  set-role        change a user's role
+  list-users      list all users
+  list-tokens     list a user's personal access tokens
+  revoke-token    revoke one token, or all tokens of a user
+  import          load an admin export ZIP
+
+run "admin <command> -h" for the flags of a command`
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	db, err := repository.NewDB(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := migrate.Check(ctx, db.DB); err != nil {
+		log.Fatal(err)
+	}
+
+	a := newApp(cfg, db)
+	if err := a.run(ctx, os.Args[1], os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type app struct {
+	users    *repository.UserRepository
+	tokens   repository.AccessTokenRepository
+	auth     *services.AuthService
+	userSvc  *services.UserService
+	importer *services.ImportService
+	out      *os.File
+}
+
+func newApp(cfg config.Config, db *sqlx.DB) *app {
+	client := newRedisClient(cfg)
+	loader := newLoader(cfg, client)
+	users := repository.NewUserRepository(db)
+
+	// with Redis, reset-password also clears the lockout the API tracks
+	var guard services.LoginGuard
+	if client != nil {
+		guard = services.NewRedisLoginLockout(client, services.LockoutRedisNamespace, services.DefaultEmailLockoutPolicy, services.DefaultAccountLockoutPolicy, services.DefaultIPLockoutPolicy)
+	}
+
+	return &app{
+		users:    users,
+		tokens:   repository.NewAccessTokenRepository(db),
+		auth:     services.NewAuthService(users, loader, guard, nil),
+		userSvc:  services.NewUserService(users, loader),
+		importer: services.NewImportService(repository.NewImportRepository(db), loader),
+		out:      os.Stdout,
+	}
+}
+
+// newRedisClient connects to the API's Redis when configured.
+func newRedisClient(cfg config.Config) *redis.Client {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := cache.NewRedisClient(ctx, cfg.RedisURL)
+	if err != nil {
+		log.Printf("redis unavailable, cached data expires on its own and lockouts stay: %v", err)
+		return nil
+	}
+	return client
+}
+
+// newLoader shares the API's Redis cache when configured, so changes made
+// here invalidate what the API has cached. The in-process fallback only
+// satisfies the services; the API's own copy expires after CACHE_TTL_SECONDS.
+func newLoader(cfg config.Config, client *redis.Client) *cache.Loader {
+	if client != nil {
+		return cache.NewLoader(cache.NewRedisCache(client, cache.RedisNamespace), cfg.CacheTTL)
+	}
+	return cache.NewLoader(cache.NewMemoryCache(cfg.CacheMaxEntries), cfg.CacheTTL)
+}
@@ -1,10 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
 
 	"task-management-platform/backend/internal/config"
+	"task-management-platform/backend/internal/logging"
+	"task-management-platform/backend/internal/migrate"
+	"task-management-platform/backend/internal/repository"
 	"task-management-platform/backend/internal/server"
+	"task-management-platform/backend/internal/tracing"
 )
 
 func main() {
@@ -13,11 +25,72 @@ func main() {
 		log.Fatal(err)
 	}
 
-	r := server.New(cfg)
+	logger, err := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// also routes the standard library's log package through slog
+	slog.SetDefault(logger)
+	if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// `api config` prints the effective configuration, secrets redacted
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	log.Printf("api starting on port %s", cfg.Port)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	if err := r.Run(":" + cfg.Port); err != nil {
-		log.Fatal(err)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TraceExporter,
+		ServiceName: cfg.TraceServiceName,
+		SampleRatio: cfg.TraceSampleRatio,
+		Stdout:      os.Stdout,
+	})
+	if err != nil {
+		slog.Error("tracing setup failed", "error", err)
+		os.Exit(1)
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		slog.Error("startup failed", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runErr := srv.Run(ctx)
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("flush traces", "error", err)
+	}
+
+	if runErr != nil {
+		slog.Error("server failed", "error", runErr)
+		os.Exit(1)
+	}
+}
+
+// runMigrate handles `api migrate up|down [N]|status|force VERSION`.
+func runMigrate(cfg config.Config, args []string) error {
+	db, err := repository.NewDB(cfg)
+	if err != nil {
+		return err
 	}
+	defer db.Close()
+
+	return migrate.Run(context.Background(), db.DB, args, os.Stdout)
 }
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache stores opaque byte values under string keys. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// loadTimeout bounds a shared load, which no longer follows any one
+// caller's context.
+const loadTimeout = 10 * time.Second
+
+// Loader reads through a Cache and collapses concurrent misses for the same
+// key into a single load, so a cold or just-invalidated key does not send a
+// burst of identical queries to the database.
+type Loader struct {
+	cache Cache
+	ttl   time.Duration
+	group singleflight.Group
+	// gen changes on every invalidation. A load only stores its result when
+	// no invalidation happened while it ran, so a write that lands during the
+	// load is not hidden behind the value read before it.
+	gen atomic.Uint64
+}
+
+func NewLoader(c Cache, ttl time.Duration) *Loader {
+	return &Loader{cache: c, ttl: ttl}
+}
+
+func (l *Loader) Cache() Cache {
+	return l.cache
+}
+
+// Fetch returns the cached value for key, or calls load, stores its result and
+// returns it. Cache backend errors are not fatal: the value is loaded from the
+// source instead.
+//
+// The shared load runs detached from the callers' cancellation, bounded by
+// loadTimeout, so one caller giving up does not fail the others; each caller
+// still stops waiting when its own ctx ends.
+func Fetch[T any](ctx context.Context, l *Loader, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	if raw, ok, err := l.cache.Get(ctx, key); err == nil && ok {
+		var v T
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return v, nil
+		}
+	}
+
+	// callers arriving after an invalidation start a fresh load instead of
+	// joining one that may have read the old data
+	gen := l.gen.Load()
+	ch := l.group.DoChan(key+"#"+strconv.FormatUint(gen, 10), func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		v, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		if raw, err := json.Marshal(v); err == nil && l.gen.Load() == gen {
+			_ = l.cache.Set(loadCtx, key, raw, l.ttl)
+		}
+		return v, nil
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if res.Err != nil {
+		return zero, res.Err
+	}
+
+	v, ok := res.Val.(T)
+	if !ok {
+		return zero, errors.New("cache: unexpected value type")
+	}
+	return v, nil
+}
+
+// Invalidate drops the given keys. It is a no-op on a nil Loader so callers can
+// leave caching disabled. Failures are logged rather than returned: the write
+// that triggered the invalidation has already succeeded, and a stale entry
+// expires with its TTL.
+func (l *Loader) Invalidate(ctx context.Context, keys ...string) {
+	if l == nil {
+		return
+	}
+	l.gen.Add(1)
+	if err := l.cache.Delete(ctx, keys...); err != nil {
+		slog.WarnContext(ctx, "cache invalidation failed", "keys", keys, "error", err)
+	}
+}
+
+// InvalidatePrefix drops every key starting with one of the given prefixes.
+func (l *Loader) InvalidatePrefix(ctx context.Context, prefixes ...string) {
+	if l == nil {
+		return
+	}
+	l.gen.Add(1)
+	for _, p := range prefixes {
+		if err := l.cache.DeletePrefix(ctx, p); err != nil {
+			slog.WarnContext(ctx, "cache invalidation failed", "prefix", p, "error", err)
+		}
+	}
+}
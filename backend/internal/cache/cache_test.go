@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetch_LoadsOnceAndServesFromCache(t *testing.T) {
+	ctx := context.Background()
+	l := NewLoader(NewMemoryCache(10), time.Minute)
+
+	var calls int32
+	load := func(ctx context.Context) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		return []string{"a", "b"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := Fetch(ctx, l, "k", load)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if len(got) != 2 || got[0] != "a" {
+			t.Fatalf("Fetch() = %v, want [a b]", got)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("load called %d times, want 1", calls)
+	}
+}
+
+func TestFetch_CollapsesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	l := NewLoader(NewMemoryCache(10), time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := Fetch(ctx, l, "hot", load); err != nil || v != 42 {
+				t.Errorf("Fetch() = %v, %v; want 42, nil", v, err)
+			}
+		}()
+	}
+
+	// give the goroutines time to pile up on the in-flight load
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("load called %d times, want 1", calls)
+	}
+}
+
+func TestFetch_DoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	l := NewLoader(NewMemoryCache(10), time.Minute)
+
+	boom := errors.New("boom")
+	if _, err := Fetch(ctx, l, "k", func(ctx context.Context) (int, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Fatalf("Fetch() error = %v, want boom", err)
+	}
+
+	v, err := Fetch(ctx, l, "k", func(ctx context.Context) (int, error) { return 7, nil })
+	if err != nil || v != 7 {
+		t.Fatalf("Fetch() = %v, %v; want 7, nil", v, err)
+	}
+}
+
+func TestLoader_InvalidateForcesReload(t *testing.T) {
+	ctx := context.Background()
+	l := NewLoader(NewMemoryCache(10), time.Minute)
+
+	n := 0
+	load := func(ctx context.Context) (int, error) {
+		n++
+		return n, nil
+	}
+
+	first, _ := Fetch(ctx, l, "projects:all", load)
+	l.InvalidatePrefix(ctx, "projects:")
+	second, _ := Fetch(ctx, l, "projects:all", load)
+
+	if first != 1 || second != 2 {
+		t.Fatalf("got %d then %d, want 1 then 2", first, second)
+	}
+}
+
+func TestLoader_NilIsNoop(t *testing.T) {
+	var l *Loader
+	l.Invalidate(context.Background(), "k")
+	l.InvalidatePrefix(context.Background(), "p")
+}
+
+func TestFetch_CallerCancelDoesNotFailOthers(t *testing.T) {
+	l := NewLoader(NewMemoryCache(10), time.Minute)
+
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		select {
+		case <-release:
+			return 42, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := Fetch(first, l, "k", load)
+		firstErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan int, 1)
+	go func() {
+		v, err := Fetch(context.Background(), l, "k", load)
+		if err != nil {
+			t.Errorf("Fetch() error = %v", err)
+		}
+		second <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first Fetch() error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if v := <-second; v != 42 {
+		t.Fatalf("second Fetch() = %d, want 42", v)
+	}
+}
+
+func TestFetch_InvalidationDuringLoadIsNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+	l := NewLoader(NewMemoryCache(10), time.Minute)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan int, 1)
+	go func() {
+		v, _ := Fetch(ctx, l, "k", func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		done <- v
+	}()
+
+	<-started
+	// a write lands while the load that read the old value is in flight
+	l.Invalidate(ctx, "k")
+	close(release)
+	if v := <-done; v != 1 {
+		t.Fatalf("in-flight Fetch() = %d, want 1", v)
+	}
+
+	v, err := Fetch(ctx, l, "k", func(ctx context.Context) (int, error) { return 2, nil })
+	if err != nil || v != 2 {
+		t.Fatalf("Fetch() = %v, %v; want the fresh value 2", v, err)
+	}
+}
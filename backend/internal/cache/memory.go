@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is a size-bounded LRU cache with per-entry expiry. It is the
+// fallback used when no Redis URL is configured.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+
+	now func() time.Time
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := el.Value.(*memoryEntry)
+	if !e.expiresAt.IsZero() && m.now().After(e.expiresAt) {
+		m.removeElement(el)
+		return nil, false, nil
+	}
+
+	m.ll.MoveToFront(el)
+	return e.value, true, nil
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = m.now().Add(ttl)
+	}
+
+	if el, ok := m.items[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.value = value
+		e.expiresAt = expiresAt
+		m.ll.MoveToFront(el)
+		return nil
+	}
+
+	el := m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	m.items[key] = el
+
+	for m.ll.Len() > m.maxEntries {
+		m.removeElement(m.ll.Back())
+	}
+	return nil
+}
+
+func (m *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, k := range keys {
+		if el, ok := m.items[k]; ok {
+			m.removeElement(el)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryCache) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, el := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			m.removeElement(el)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+func (m *MemoryCache) removeElement(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
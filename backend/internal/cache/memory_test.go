@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+
+	// touch "a" so "b" becomes the eviction candidate
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatalf("expected a to survive eviction")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("len = %d, want 2", c.Len())
+	}
+}
+
+func TestMemoryCache_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "k", []byte("v"), time.Minute)
+
+	now = now.Add(2 * time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Fatalf("expected k to be expired")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("len = %d, want 0", c.Len())
+	}
+}
+
+func TestMemoryCache_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	_ = c.Set(ctx, "projects:all", []byte("1"), 0)
+	_ = c.Set(ctx, "projects:id:1", []byte("2"), 0)
+	_ = c.Set(ctx, "tasks:id:1", []byte("3"), 0)
+
+	if err := c.DeletePrefix(ctx, "projects:"); err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+
+	if _, ok, _ := c.Get(ctx, "projects:all"); ok {
+		t.Fatalf("expected projects:all to be deleted")
+	}
+	if _, ok, _ := c.Get(ctx, "tasks:id:1"); !ok {
+		t.Fatalf("expected tasks:id:1 to be kept")
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisNamespace is where the API and the admin CLI keep cached entries, so
+// an invalidation from either reaches both.
+const RedisNamespace = "taskmgr:cache:"
+
+// RedisCache stores entries in Redis so every API replica shares them. All
+// keys are namespaced with a prefix to keep DeletePrefix scans scoped.
+type RedisCache struct {
+	client    redis.UniversalClient
+	namespace string
+}
+
+func NewRedisCache(client redis.UniversalClient, namespace string) *RedisCache {
+	return &RedisCache{client: client, namespace: namespace}
+}
+
+// NewRedisClient parses a redis:// URL and verifies the server is reachable.
+func NewRedisClient(ctx context.Context, url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, err := r.client.Get(ctx, r.namespace+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.namespace+key, value, ttl).Err()
+}
+
+func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, 0, len(keys))
+	for _, k := range keys {
+		full = append(full, r.namespace+k)
+	}
+	return r.client.Del(ctx, full...).Err()
+}
+
+func (r *RedisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, r.namespace+prefix+"*", 200).Iterator()
+
+	batch := make([]string, 0, 200)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := r.client.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return r.client.Del(ctx, batch...).Err()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisCache(client, "test:"), mr
+}
+
+func TestRedisCache_SetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestRedisCache(t)
+
+	if err := c.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !mr.Exists("test:k") {
+		t.Fatalf("expected namespaced key test:k in redis")
+	}
+
+	got, ok, err := c.Get(ctx, "k")
+	if err != nil || !ok || string(got) != "v" {
+		t.Fatalf("Get() = %q, %v, %v; want v, true, nil", got, ok, err)
+	}
+
+	if err := c.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Fatalf("expected k to be deleted")
+	}
+}
+
+func TestRedisCache_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestRedisCache(t)
+
+	_ = c.Set(ctx, "k", []byte("v"), time.Second)
+	mr.FastForward(2 * time.Second)
+
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Fatalf("expected k to be expired")
+	}
+}
+
+func TestRedisCache_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestRedisCache(t)
+
+	_ = c.Set(ctx, "tasks:id:1", []byte("1"), 0)
+	_ = c.Set(ctx, "tasks:list:x", []byte("2"), 0)
+	_ = c.Set(ctx, "projects:all", []byte("3"), 0)
+
+	if err := c.DeletePrefix(ctx, "tasks:"); err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+
+	if _, ok, _ := c.Get(ctx, "tasks:id:1"); ok {
+		t.Fatalf("expected tasks:id:1 to be deleted")
+	}
+	if _, ok, _ := c.Get(ctx, "projects:all"); !ok {
+		t.Fatalf("expected projects:all to be kept")
+	}
+}
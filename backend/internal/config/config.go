@@ -1,64 +1,326 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"task-management-platform/backend/pkg/seal"
 )
 
 type Config struct {
 	Port string
 
+	// LogFormat is "json" or "text"; LogLevel is debug, info, warn or error.
+	LogFormat string
+	LogLevel  slog.Level
+
+	// HTTP server timeouts; zero disables one. WriteTimeout also bounds
+	// synchronous exports, which stream for as long as the response lasts.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// On SIGTERM the server reports not ready for ShutdownDelay so load
+	// balancers stop routing to it, then drains in-flight requests and
+	// background exports for up to ShutdownTimeout.
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+
+	// CORSOrigins are the browser origins allowed to call the API.
+	CORSOrigins []string
+
+	// JWTSecret signs access and challenge tokens with HS256 unless
+	// JWTPrivateKey, a PEM RSA or Ed25519 key, is set; then tokens are
+	// signed with it and JWTSecret only seeds the defaults of other secrets.
+	// JWTVerificationKeys holds PEM public keys still accepted after a
+	// rotation. JWTKeyID is the kid of HS256 tokens. Access tokens expire
+	// after JWTTTL.
+	JWTSecret           string
+	JWTKeyID            string
+	JWTPrivateKey       string
+	JWTVerificationKeys string
+	JWTTTL              time.Duration
+	// JWTIssuer and JWTAudience are the iss and aud claims tokens carry and
+	// must present.
+	JWTIssuer   string
+	JWTAudience string
+
+	// MetricsToken must be sent as a bearer token to read /metrics. When
+	// it is empty the endpoint is not served.
+	MetricsToken string
+
+	// TraceExporter is "none", "otlp" or "stdout"; the OTLP endpoint comes
+	// from the standard OTEL_EXPORTER_OTLP_* variables. TraceSampleRatio is
+	// the share of new traces recorded.
+	TraceExporter    string
+	TraceServiceName string
+	TraceSampleRatio float64
+
+	// OpenAPIValidate checks requests against the OpenAPI document before
+	// they reach a handler.
+	OpenAPIValidate bool
+
 	DBHost     string
 	DBPort     int
 	DBUser     string
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+	// Connection pool limits of the database handle.
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+
+	// RedisURL selects the Redis cache backend; when empty an in-process LRU
+	// is used instead.
+	RedisURL        string
+	CacheTTL        time.Duration
+	CacheMaxEntries int
+
+	// Token-bucket limits: RateLimitIP applies to every request by client IP,
+	// RateLimitUser to authenticated requests by user id and RateLimitAuth to
+	// login and registration by client IP.
+	RateLimitIP     int
+	RateLimitUser   int
+	RateLimitAuth   int
+	RateLimitWindow time.Duration
+
+	// TrustedProxies lists the proxy CIDRs allowed to set X-Forwarded-For.
+	// Empty means the socket address is the client IP, so the per-IP limit
+	// cannot be dodged with a forged header.
+	TrustedProxies []string
+
+	// TOTPIssuer is the account label shown in authenticator apps.
+	TOTPIssuer string
+	// TOTPEncryptionKey seals the stored TOTP secrets. Changing it makes
+	// enrolled secrets unreadable, so users would have to enroll again.
+	TOTPEncryptionKey string
+	// RequireAdminMFA restricts admin-only routes to sessions established
+	// with a second factor.
+	RequireAdminMFA bool
+
+	// OIDC single sign-on is enabled when OIDCIssuerURL is set.
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       []string
+	OIDCGroupsClaim  string
+	// OIDCRoleMapping maps IdP groups to roles, from "group=role,...".
+	OIDCRoleMapping map[string]string
+	// OIDCPostLoginURL is the frontend page the callback redirects to.
+	OIDCPostLoginURL string
+	// OIDCStateSecret signs the login state cookie. It has no default so the
+	// cookie key is never a token signing key.
+	OIDCStateSecret string
+
+	// StorageDir holds the files the API writes; ExportDir defaults to a
+	// directory inside it.
+	StorageDir string
+
+	// Background exports are written to ExportDir and kept for
+	// ExportRetention; their download links expire after ExportLinkTTL.
+	ExportDir        string
+	ExportLinkTTL    time.Duration
+	ExportRetention  time.Duration
+	ExportLinkSecret string
+
+	// Deleted projects and tasks stay in the trash for TrashRetention
+	// before they are purged for good.
+	TrashRetention time.Duration
+
+	// File is the config file read, if any.
+	File string
+	// settings records every value read, for Dump.
+	settings []Setting
 }
 
+// Load reads the configuration. Each setting comes from the environment
+// variable of its name, a file named by NAME_FILE, or the YAML or TOML file
+// named by CONFIG_FILE, in that order.
 func Load() (Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(env func(string) (string, bool)) (Config, error) {
+	src, err := newSource(env)
+	if err != nil {
+		return Config{}, err
+	}
+	r := &reader{src: src}
+
 	cfg := Config{
-		Port:      getEnv("PORT", "8080"),
-		DBHost:    os.Getenv("DB_HOST"),
-		DBUser:    os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBName:    os.Getenv("DB_NAME"),
-		DBSSLMode: getEnv("DB_SSLMODE", "disable"),
+		File: src.fileName,
+
+		Port:      r.str("PORT", "8080"),
+		LogFormat: r.oneOf("LOG_FORMAT", "json", "json", "text"),
+
+		ReadTimeout:       r.duration("HTTP_READ_TIMEOUT_SECONDS", 30, time.Second, 0),
+		ReadHeaderTimeout: r.duration("HTTP_READ_HEADER_TIMEOUT_SECONDS", 10, time.Second, 0),
+		WriteTimeout:      r.duration("HTTP_WRITE_TIMEOUT_SECONDS", 300, time.Second, 0),
+		IdleTimeout:       r.duration("HTTP_IDLE_TIMEOUT_SECONDS", 120, time.Second, 0),
+		ShutdownDelay:     r.duration("SHUTDOWN_DELAY_SECONDS", 0, time.Second, 0),
+		ShutdownTimeout:   r.duration("SHUTDOWN_TIMEOUT_SECONDS", 30, time.Second, 0),
+
+		CORSOrigins: r.list("CORS_ORIGINS", "http://localhost:5173,http://localhost:3000"),
+
+		JWTSecret:           r.secret("JWT_SECRET", ""),
+		JWTKeyID:            r.str("JWT_KEY_ID", "hs256"),
+		JWTPrivateKey:       r.secret("JWT_PRIVATE_KEY", ""),
+		JWTVerificationKeys: r.str("JWT_VERIFICATION_KEYS", ""),
+		JWTTTL:              r.duration("JWT_TTL_MINUTES", 60, time.Minute, 1),
+		JWTIssuer:           r.str("JWT_ISSUER", "task-management-api"),
+		JWTAudience:         r.str("JWT_AUDIENCE", "task-management-api"),
+
+		MetricsToken: r.secret("METRICS_TOKEN", ""),
+
+		TraceExporter:    r.oneOf("OTEL_TRACES_EXPORTER", "none", "none", "otlp", "stdout"),
+		TraceServiceName: r.str("OTEL_SERVICE_NAME", "task-management-api"),
+		TraceSampleRatio: r.float("OTEL_TRACES_SAMPLER_ARG", 1, 0, 1),
+
+		OpenAPIValidate: r.bool("OPENAPI_VALIDATE", false),
+
+		DBHost:            r.str("DB_HOST", ""),
+		DBPort:            r.int("DB_PORT", 5432, 1),
+		DBUser:            r.str("DB_USER", ""),
+		DBPassword:        r.secret("DB_PASSWORD", ""),
+		DBName:            r.str("DB_NAME", ""),
+		DBSSLMode:         r.str("DB_SSLMODE", "disable"),
+		DBMaxOpenConns:    r.int("DB_MAX_OPEN_CONNS", 10, 1),
+		DBMaxIdleConns:    r.int("DB_MAX_IDLE_CONNS", 5, 0),
+		DBConnMaxLifetime: r.duration("DB_CONN_MAX_LIFETIME_MINUTES", 30, time.Minute, 0),
+
+		RedisURL:        r.url("REDIS_URL", ""),
+		CacheTTL:        r.duration("CACHE_TTL_SECONDS", 30, time.Second, 0),
+		CacheMaxEntries: r.int("CACHE_MAX_ENTRIES", 10000, 1),
+
+		RateLimitIP:     r.int("RATE_LIMIT_IP", 120, 1),
+		RateLimitUser:   r.int("RATE_LIMIT_USER", 120, 1),
+		RateLimitAuth:   r.int("RATE_LIMIT_AUTH", 10, 1),
+		RateLimitWindow: r.duration("RATE_LIMIT_WINDOW_SECONDS", 60, time.Second, 1),
+
+		TrustedProxies: r.list("TRUSTED_PROXIES", ""),
+
+		TOTPIssuer:        r.str("TOTP_ISSUER", "Task Manager"),
+		TOTPEncryptionKey: r.secret("TOTP_ENCRYPTION_KEY", ""),
+		RequireAdminMFA:   r.bool("REQUIRE_2FA_FOR_ADMIN", false),
+
+		OIDCIssuerURL:    r.str("OIDC_ISSUER_URL", ""),
+		OIDCClientID:     r.str("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: r.secret("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  r.str("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:       r.list("OIDC_SCOPES", "openid email profile"),
+		OIDCGroupsClaim:  r.str("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCPostLoginURL: r.str("OIDC_POST_LOGIN_URL", ""),
+		OIDCStateSecret:  r.secret("OIDC_STATE_SECRET", ""),
+
+		StorageDir: r.str("STORAGE_DIR", filepath.Join(os.TempDir(), "taskmgr")),
+
+		ExportLinkTTL:   r.duration("EXPORT_LINK_TTL_MINUTES", 15, time.Minute, 1),
+		ExportRetention: r.duration("EXPORT_RETENTION_MINUTES", 60, time.Minute, 1),
+
+		TrashRetention: r.duration("TRASH_RETENTION_DAYS", 30, 24*time.Hour, 1),
 	}
 
-	dbPortStr := getEnv("DB_PORT", "5432")
-	dbPort, err := strconv.Atoi(dbPortStr)
+	if level := r.str("LOG_LEVEL", "info"); cfg.LogLevel.UnmarshalText([]byte(level)) != nil {
+		r.invalid("LOG_LEVEL", level)
+	}
+
+	// these default to other settings, so they are read after them
+	cfg.ExportDir = r.str("EXPORT_DIR", filepath.Join(cfg.StorageDir, "exports"))
+	cfg.ExportLinkSecret = r.secret("EXPORT_LINK_SECRET", cfg.JWTSecret)
+
+	roleMapping, err := parseRoleMapping(r.str("OIDC_ROLE_MAPPING", ""))
 	if err != nil {
-		return Config{}, fmt.Errorf("invalid DB_PORT: %q", dbPortStr)
+		r.errs = append(r.errs, err)
 	}
-	cfg.DBPort = dbPort
+	cfg.OIDCRoleMapping = roleMapping
 
-	missing := []string{}
-	if cfg.DBHost == "" {
-		missing = append(missing, "DB_HOST")
+	if err := src.unused(); err != nil {
+		r.errs = append(r.errs, err)
 	}
-	if cfg.DBUser == "" {
-		missing = append(missing, "DB_USER")
+	if len(r.errs) > 0 {
+		return Config{}, errors.Join(r.errs...)
 	}
-	if cfg.DBPassword == "" {
-		missing = append(missing, "DB_PASSWORD")
+	cfg.settings = r.settings
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// validate checks required settings and the ones that depend on each
+// other, reporting every problem at once.
+func (c Config) validate() error {
+	var errs []error
+
+	missing := []string{}
+	require := func(value, key string) {
+		if value == "" {
+			missing = append(missing, key)
+		}
 	}
-	if cfg.DBName == "" {
-		missing = append(missing, "DB_NAME")
+	require(c.DBHost, "DB_HOST")
+	require(c.DBUser, "DB_USER")
+	require(c.DBPassword, "DB_PASSWORD")
+	require(c.DBName, "DB_NAME")
+	if c.JWTPrivateKey == "" {
+		require(c.JWTSecret, "JWT_SECRET")
 	}
+	// defaults to JWT_SECRET, which key-signed deployments may not set
+	require(c.ExportLinkSecret, "EXPORT_LINK_SECRET")
+	require(c.TOTPEncryptionKey, "TOTP_ENCRYPTION_KEY")
 
+	if c.OIDCIssuerURL != "" {
+		require(c.OIDCClientID, "OIDC_CLIENT_ID")
+		require(c.OIDCRedirectURL, "OIDC_REDIRECT_URL")
+		require(c.OIDCStateSecret, "OIDC_STATE_SECRET")
+	}
 	if len(missing) > 0 {
-		return Config{}, fmt.Errorf("missing required env vars: %v", missing)
+		errs = append(errs, fmt.Errorf("missing required settings: %v", missing))
 	}
 
-	return cfg, nil
+	if c.TOTPEncryptionKey != "" && len(c.TOTPEncryptionKey) < seal.MinKeyLength {
+		errs = append(errs, fmt.Errorf("TOTP_ENCRYPTION_KEY must be at least %d characters", seal.MinKeyLength))
+	}
+	if c.DBMaxIdleConns > c.DBMaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) exceeds DB_MAX_OPEN_CONNS (%d)", c.DBMaxIdleConns, c.DBMaxOpenConns))
+	}
+	for _, origin := range c.CORSOrigins {
+		if u, err := url.Parse(origin); origin != "*" && (err != nil || u.Scheme == "" || u.Host == "") {
+			errs = append(errs, fmt.Errorf("invalid CORS_ORIGINS entry: %q", origin))
+		}
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("invalid TRUSTED_PROXIES entry: %q", proxy))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func getEnv(key, def string) string {
-	v := os.Getenv(key)
-	if v == "" {
-		return def
+// parseRoleMapping reads "group=role" pairs separated by commas.
+func parseRoleMapping(v string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || (role != "admin" && role != "user") {
+			return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING entry: %q", pair)
+		}
+		mapping[group] = role
 	}
-	return v
+	return mapping, nil
 }
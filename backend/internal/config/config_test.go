@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envOf(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func requiredEnv() map[string]string {
+	return map[string]string{
+		"DB_HOST":     "db",
+		"DB_USER":     "app",
+		"DB_PASSWORD": "db-password",
+		"DB_NAME":     "tasks",
+		"JWT_SECRET":  "jwt-secret",
+
+		"TOTP_ENCRYPTION_KEY": "totp-key-0123456789abcdef0123456",
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(envOf(requiredEnv()))
+	if err != nil {
+		t.Fatalf("load error = %v", err)
+	}
+
+	if cfg.JWTTTL != time.Hour {
+		t.Fatalf("JWTTTL = %v, want 1h", cfg.JWTTTL)
+	}
+	if cfg.DBMaxOpenConns != 10 || cfg.DBMaxIdleConns != 5 || cfg.DBConnMaxLifetime != 30*time.Minute {
+		t.Fatalf("pool = %d/%d/%v, want 10/5/30m", cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime)
+	}
+	if want := []string{"http://localhost:5173", "http://localhost:3000"}; strings.Join(cfg.CORSOrigins, " ") != strings.Join(want, " ") {
+		t.Fatalf("CORSOrigins = %v, want %v", cfg.CORSOrigins, want)
+	}
+	if cfg.ExportLinkSecret != "jwt-secret" {
+		t.Fatalf("ExportLinkSecret = %q, want the JWT secret", cfg.ExportLinkSecret)
+	}
+	if cfg.ExportDir != filepath.Join(cfg.StorageDir, "exports") {
+		t.Fatalf("ExportDir = %q, want inside %q", cfg.ExportDir, cfg.StorageDir)
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	env := map[string]string{
+		"RATE_LIMIT_IP":     "-1",
+		"LOG_FORMAT":        "xml",
+		"DB_MAX_IDLE_CONNS": "50",
+	}
+
+	_, err := load(envOf(env))
+	if err == nil {
+		t.Fatalf("load error = nil, want an error")
+	}
+	for _, want := range []string{`invalid RATE_LIMIT_IP: "-1"`, `invalid LOG_FORMAT: "xml"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %v, want it to contain %s", err, want)
+		}
+	}
+
+	env = requiredEnv()
+	delete(env, "JWT_SECRET")
+	env["DB_MAX_IDLE_CONNS"] = "50"
+	env["CORS_ORIGINS"] = "localhost:3000"
+	env["TOTP_ENCRYPTION_KEY"] = "too-short"
+	_, err = load(envOf(env))
+	if err == nil {
+		t.Fatalf("load error = nil, want an error")
+	}
+	for _, want := range []string{"JWT_SECRET", "DB_MAX_IDLE_CONNS (50) exceeds", `invalid CORS_ORIGINS entry: "localhost:3000"`, "TOTP_ENCRYPTION_KEY must be at least 32"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %v, want it to contain %s", err, want)
+		}
+	}
+}
+
+func TestLoad_OIDCNeedsItsOwnStateSecret(t *testing.T) {
+	env := requiredEnv()
+	env["OIDC_ISSUER_URL"] = "https://idp.example.com"
+	env["OIDC_CLIENT_ID"] = "tasks"
+	env["OIDC_REDIRECT_URL"] = "https://tasks.example.com/auth/oidc/callback"
+
+	if _, err := load(envOf(env)); err == nil || !strings.Contains(err.Error(), "OIDC_STATE_SECRET") {
+		t.Fatalf("load error = %v, want OIDC_STATE_SECRET required even with JWT_SECRET set", err)
+	}
+
+	env["OIDC_STATE_SECRET"] = "state-secret"
+	cfg, err := load(envOf(env))
+	if err != nil {
+		t.Fatalf("load error = %v", err)
+	}
+	if cfg.OIDCStateSecret != "state-secret" {
+		t.Fatalf("OIDCStateSecret = %q, want state-secret", cfg.OIDCStateSecret)
+	}
+}
+
+func TestLoad_ConfigFileUnderEnvironment(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.yaml": `
+db:
+  host: file-db
+  user: app
+  name: tasks
+  max-open-conns: 20
+rate_limit:
+  ip: 300
+cors_origins:
+  - https://app.example.com
+  - https://admin.example.com
+`,
+		"config.toml": `
+cors_origins = ["https://app.example.com", "https://admin.example.com"]
+
+[db]
+host = "file-db"
+user = "app"
+name = "tasks"
+max_open_conns = 20
+
+[rate_limit]
+ip = 300
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := load(envOf(map[string]string{
+				"CONFIG_FILE":   writeFile(t, name, content),
+				"DB_PASSWORD":   "db-password",
+				"JWT_SECRET":    "jwt-secret",
+				"RATE_LIMIT_IP": "500",
+
+				"TOTP_ENCRYPTION_KEY": "totp-key-0123456789abcdef0123456",
+			}))
+			if err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+
+			if cfg.DBHost != "file-db" || cfg.DBMaxOpenConns != 20 {
+				t.Fatalf("DBHost, DBMaxOpenConns = %q, %d, want the file's", cfg.DBHost, cfg.DBMaxOpenConns)
+			}
+			if cfg.RateLimitIP != 500 {
+				t.Fatalf("RateLimitIP = %d, want the environment's 500", cfg.RateLimitIP)
+			}
+			if len(cfg.CORSOrigins) != 2 || cfg.CORSOrigins[1] != "https://admin.example.com" {
+				t.Fatalf("CORSOrigins = %v", cfg.CORSOrigins)
+			}
+		})
+	}
+}
+
+func TestLoad_RejectsUnknownFileSettings(t *testing.T) {
+	env := requiredEnv()
+	env["CONFIG_FILE"] = writeFile(t, "config.yaml", "db:\n  hots: db\n")
+
+	_, err := load(envOf(env))
+	if err == nil || !strings.Contains(err.Error(), "unknown settings [DB_HOTS]") {
+		t.Fatalf("load error = %v, want the unknown DB_HOTS", err)
+	}
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	env := requiredEnv()
+	delete(env, "DB_PASSWORD")
+	env["DB_PASSWORD_FILE"] = writeFile(t, "db_password", "from-file\n")
+
+	cfg, err := load(envOf(env))
+	if err != nil {
+		t.Fatalf("load error = %v", err)
+	}
+	if cfg.DBPassword != "from-file" {
+		t.Fatalf("DBPassword = %q, want %q", cfg.DBPassword, "from-file")
+	}
+
+	env["DB_PASSWORD"] = "also-set"
+	if _, err := load(envOf(env)); err == nil || !strings.Contains(err.Error(), "both DB_PASSWORD and DB_PASSWORD_FILE") {
+		t.Fatalf("load error = %v, want a conflict", err)
+	}
+}
+
+func TestDump_RedactsSecrets(t *testing.T) {
+	env := requiredEnv()
+	env["REDIS_URL"] = "redis://:redis-password@redis:6379/0"
+	cfg, err := load(envOf(env))
+	if err != nil {
+		t.Fatalf("load error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.Dump(&out); err != nil {
+		t.Fatalf("Dump error = %v", err)
+	}
+	dump := out.String()
+
+	for _, secret := range []string{"db-password", "jwt-secret", "redis-password"} {
+		if strings.Contains(dump, secret) {
+			t.Fatalf("dump leaks %q:\n%s", secret, dump)
+		}
+	}
+	for _, want := range []string{"DB_HOST=db", "JWT_SECRET=" + Redacted, "REDIS_URL=redis://:xxxxx@redis:6379/0", "METRICS_TOKEN= "} {
+		if !strings.Contains(dump, want) {
+			t.Fatalf("dump is missing %q:\n%s", want, dump)
+		}
+	}
+}
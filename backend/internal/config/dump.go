@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Setting is one effective configuration value and where it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Origin string
+	Secret bool
+}
+
+// Redacted replaces secret values that are set.
+const Redacted = "<redacted>"
+
+// Settings lists the values Load read, in reading order, with secrets
+// redacted.
+func (c Config) Settings() []Setting {
+	out := make([]Setting, len(c.settings))
+	for i, s := range c.settings {
+		switch {
+		case s.Secret && s.Value != "":
+			s.Value = Redacted
+		case strings.Contains(s.Value, "\n"):
+			// PEM keys and the like
+			s.Value = fmt.Sprintf("<%d lines>", strings.Count(s.Value, "\n")+1)
+		}
+		out[i] = s
+	}
+	return out
+}
+
+// Dump writes the effective configuration as NAME=value lines annotated
+// with their origin. Secrets are redacted, so the output is safe to paste
+// into a bug report.
+func (c Config) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if c.File != "" {
+		fmt.Fprintf(tw, "# config file: %s\n", c.File)
+	}
+	for _, s := range c.Settings() {
+		fmt.Fprintf(tw, "%s=%s\t# %s\n", s.Key, s.Value, s.Origin)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Where a setting's value came from, as shown by Dump.
+const (
+	OriginDefault    = "default"
+	OriginEnv        = "env"
+	OriginSecretFile = "secret file"
+	OriginConfigFile = "config file"
+)
+
+// source resolves settings by name. The environment wins over the config
+// file, and in either place NAME_FILE may name a file holding the value, as
+// Docker and Kubernetes mount secrets. Empty values count as unset.
+type source struct {
+	env      func(string) (string, bool)
+	fileName string
+	// file holds the config file flattened to setting names.
+	file map[string]string
+	used map[string]bool
+}
+
+func newSource(env func(string) (string, bool)) (*source, error) {
+	s := &source{env: env, file: map[string]string{}, used: map[string]bool{}}
+	if name, _ := env("CONFIG_FILE"); name != "" {
+		file, err := readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		s.fileName, s.file = name, file
+	}
+	return s, nil
+}
+
+func (s *source) lookup(key string) (value, origin string, err error) {
+	s.used[key], s.used[key+"_FILE"] = true, true
+
+	inEnv, _ := s.env(key)
+	envFile, _ := s.env(key + "_FILE")
+	switch {
+	case inEnv != "" && envFile != "":
+		return "", "", fmt.Errorf("both %s and %s_FILE are set", key, key)
+	case inEnv != "":
+		return inEnv, OriginEnv, nil
+	case envFile != "":
+		v, err := readSecret(key, envFile)
+		return v, OriginSecretFile, err
+	}
+
+	inFile, fileFile := s.file[key], s.file[key+"_FILE"]
+	switch {
+	case inFile != "" && fileFile != "":
+		return "", "", fmt.Errorf("%s: both %s and %s_FILE are set", s.fileName, key, key)
+	case inFile != "":
+		return inFile, OriginConfigFile, nil
+	case fileFile != "":
+		v, err := readSecret(key, fileFile)
+		return v, OriginSecretFile, err
+	}
+	return "", OriginDefault, nil
+}
+
+// unused reports config file entries no setting read, which are most likely
+// typos.
+func (s *source) unused() error {
+	var keys []string
+	for k := range s.file {
+		if !s.used[k] {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("%s: unknown settings %v", s.fileName, keys)
+}
+
+func readSecret(key, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s_FILE: %w", key, err)
+	}
+	// editors and `echo` leave a trailing newline
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// readFile parses a YAML (.yaml, .yml) or TOML (.toml) config file into
+// setting names: keys are upper-cased with dashes turned into underscores,
+// and nested tables join their keys with "_", so db.host and DB_HOST name
+// the same setting. Lists become comma-separated values.
+func readFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	doc := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(bytes.NewReader(b)).Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			// an empty file
+			err = nil
+		}
+	case ".toml":
+		err = toml.Unmarshal(b, &doc)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, want .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	out := map[string]string{}
+	if err := flatten("", doc, out); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return out, nil
+}
+
+func flatten(key string, v any, out map[string]string) error {
+	if m, ok := v.(map[string]any); ok {
+		for k, child := range m {
+			name := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+			if key != "" {
+				name = key + "_" + name
+			}
+			if err := flatten(name, child, out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, dup := out[key]; dup {
+		return fmt.Errorf("%s is set twice", key)
+	}
+	if list, ok := v.([]any); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			s, err := scalar(key, item)
+			if err != nil {
+				return err
+			}
+			items = append(items, s)
+		}
+		out[key] = strings.Join(items, ",")
+		return nil
+	}
+	s, err := scalar(key, v)
+	if err != nil {
+		return err
+	}
+	out[key] = s
+	return nil
+}
+
+func scalar(key string, v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("%s: unsupported value %v", key, v)
+}
+
+// reader converts settings to their Go types, collecting every invalid
+// value rather than stopping at the first.
+type reader struct {
+	src      *source
+	errs     []error
+	settings []Setting
+}
+
+func (r *reader) read(key, def string, secret bool) string {
+	v, origin, err := r.src.lookup(key)
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+	if v == "" {
+		v, origin = def, OriginDefault
+	}
+	r.settings = append(r.settings, Setting{Key: key, Value: v, Origin: origin, Secret: secret})
+	return v
+}
+
+func (r *reader) invalid(key, value string) {
+	r.errs = append(r.errs, fmt.Errorf("invalid %s: %q", key, value))
+}
+
+func (r *reader) str(key, def string) string {
+	return r.read(key, def, false)
+}
+
+// secret is str for values Dump must not show.
+func (r *reader) secret(key, def string) string {
+	return r.read(key, def, true)
+}
+
+func (r *reader) oneOf(key, def string, allowed ...string) string {
+	v := r.str(key, def)
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	r.invalid(key, v)
+	return def
+}
+
+func (r *reader) int(key string, def, min int) int {
+	v := r.str(key, strconv.Itoa(def))
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min {
+		r.invalid(key, v)
+		return def
+	}
+	return n
+}
+
+// duration reads a whole number of units, such as the seconds of
+// HTTP_IDLE_TIMEOUT_SECONDS.
+func (r *reader) duration(key string, def int, unit time.Duration, min int) time.Duration {
+	return time.Duration(r.int(key, def, min)) * unit
+}
+
+func (r *reader) float(key string, def, min, max float64) float64 {
+	v := r.str(key, strconv.FormatFloat(def, 'f', -1, 64))
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < min || f > max {
+		r.invalid(key, v)
+		return def
+	}
+	return f
+}
+
+func (r *reader) bool(key string, def bool) bool {
+	v := r.str(key, strconv.FormatBool(def))
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		r.invalid(key, v)
+		return def
+	}
+	return b
+}
+
+// list splits on commas and whitespace; none of the listed values contain
+// either.
+func (r *reader) list(key, def string) []string {
+	return strings.FieldsFunc(r.str(key, def), func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t' || c == '\n'
+	})
+}
+
+// url reads a URL that may carry a password, which Dump hides.
+func (r *reader) url(key, def string) string {
+	v := r.str(key, def)
+	if v == "" {
+		return v
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		// the message would echo the password
+		r.errs = append(r.errs, fmt.Errorf("invalid %s", key))
+		return v
+	}
+	if _, ok := u.User.Password(); ok {
+		r.settings[len(r.settings)-1].Value = u.Redacted()
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/services"
+)
+
+type AccessTokenHandler struct {
+	service *services.AccessTokenService
+}
+
+func NewAccessTokenHandler(service *services.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{service: service}
+}
+
+type createAccessTokenResponse struct {
+	// Token is the plaintext secret. It is only ever returned here.
+	Token       string              `json:"token"`
+	AccessToken *models.AccessToken `json:"accessToken"`
+}
+
+// Create issues a token for the caller, or for the service account in the
+// :id path parameter on admin routes.
+func (h *AccessTokenHandler) Create(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	var req dto.CreateAccessTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	plaintext, token, err := h.service.Create(c.Request.Context(), actor, ownerID(c, actor), services.CreateAccessTokenInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ProjectID: req.ProjectID,
+		TTL:       time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		writeAccessTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createAccessTokenResponse{Token: plaintext, AccessToken: token})
+}
+
+func (h *AccessTokenHandler) List(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	tokens, err := h.service.List(c.Request.Context(), actor, ownerID(c, actor))
+	if err != nil {
+		writeAccessTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AccessTokenHandler) Revoke(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	if err := h.service.Revoke(c.Request.Context(), actor, c.Param("tokenId")); err != nil {
+		writeAccessTokenError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func ownerID(c *gin.Context, actor models.User) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	return actor.ID
+}
+
+func writeAccessTokenError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "access token not found")
+		return
+	}
+	writeServiceError(c, err)
+}
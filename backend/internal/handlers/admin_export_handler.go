@@ -1,124 +1,146 @@
 package handlers
 
 import (
-	"archive/zip"
-	"encoding/csv"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
-	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/services"
 )
 
 type AdminExportHandler struct {
-	userRepo    *repository.UserRepository
-	projectRepo repository.ProjectRepository
-	taskRepo    repository.TaskRepository
+	exports *services.ExportService
+	jobs    *services.ExportJobs
 }
 
-func NewAdminExportHandler(
-	userRepo *repository.UserRepository,
-	projectRepo repository.ProjectRepository,
-	taskRepo repository.TaskRepository,
-) *AdminExportHandler {
-	return &AdminExportHandler{
-		userRepo:    userRepo,
-		projectRepo: projectRepo,
-		taskRepo:    taskRepo,
-	}
+// NewAdminExportHandler serves synchronous exports; jobs may be nil, which
+// disables background exports.
+func NewAdminExportHandler(exports *services.ExportService, jobs *services.ExportJobs) *AdminExportHandler {
+	return &AdminExportHandler{exports: exports, jobs: jobs}
 }
 
+// ExportAll streams an export ZIP. Query parameters: format=csv|json|ndjson,
+// entities=users,projects,tasks,checklist_items, projectId, from and to.
 func (h *AdminExportHandler) ExportAll(c *gin.Context) {
-	ctx := c.Request.Context()
-
-	users, err := h.userRepo.List(ctx)
+	req, err := dto.ParseExportQuery(
+		c.Query("format"),
+		c.Query("entities"),
+		c.Query("projectId"),
+		c.Query("from"),
+		c.Query("to"),
+	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to export users"})
+		writeQueryError(c, err)
 		return
 	}
 
-	projects, err := h.projectRepo.List(ctx)
+	filename := "export-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// Once streaming has started the status code is sent; a failure leaves
+	// the archive unterminated, which clients detect as a corrupt ZIP.
+	if err := h.exports.WriteArchive(c.Request.Context(), c.Writer, req); err != nil {
+		// the 200 is already sent, so the access log would not flag this
+		slog.ErrorContext(c.Request.Context(), "export failed", "error", err)
+	}
+}
+
+// StartExport queues a background export and answers 202 with the job. An
+// empty body exports everything as CSV.
+func (h *AdminExportHandler) StartExport(c *gin.Context) {
+	if h.jobsDisabled(c) {
+		return
+	}
+
+	var body dto.ExportRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &body) {
+			return
+		}
+	}
+	req, err := body.ToService()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to export projects"})
+		writeQueryError(c, err)
 		return
 	}
 
-	tasks, err := h.taskRepo.ListAll(ctx)
+	job, err := h.jobs.Start(c.GetString("userId"), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to export tasks"})
+		writeServiceError(c, err)
 		return
 	}
 
-	filename := "export-" + time.Now().UTC().Format("20060102-150405") + ".zip"
-	c.Header("Content-Type", "application/zip")
-	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Location", "/api/admin/exports/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+type exportJobResponse struct {
+	services.ExportJob
+	DownloadURL       string     `json:"downloadUrl,omitempty"`
+	DownloadExpiresAt *time.Time `json:"downloadExpiresAt,omitempty"`
+}
 
-	zw := zip.NewWriter(c.Writer)
-	defer zw.Close()
+// ExportStatus reports a job; once done it carries a fresh signed link.
+func (h *AdminExportHandler) ExportStatus(c *gin.Context) {
+	if h.jobsDisabled(c) {
+		return
+	}
 
-	// meta.csv
-	{
-		f, _ := zw.Create("meta.csv")
-		w := csv.NewWriter(f)
-		_ = w.Write([]string{"exported_at_utc"})
-		_ = w.Write([]string{time.Now().UTC().Format(time.RFC3339)})
-		w.Flush()
+	job, ok := h.jobs.Get(c.Param("id"))
+	if !ok {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "export not found")
+		return
 	}
 
-	// users.csv
-	{
-		f, _ := zw.Create("users.csv")
-		w := csv.NewWriter(f)
-		_ = w.Write([]string{"id", "email", "role", "created_at"})
-		for _, u := range users {
-			_ = w.Write([]string{
-				u.ID,
-				u.Email,
-				u.Role,
-				u.CreatedAt.Format(time.RFC3339),
-			})
-		}
-		w.Flush()
+	resp := exportJobResponse{ExportJob: job}
+	if job.Status == services.ExportDone {
+		expires, signature := h.jobs.Link(job)
+		q := url.Values{}
+		q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+		q.Set("signature", signature)
+		resp.DownloadURL = "/api/admin/exports/" + job.ID + "/download?" + q.Encode()
+		resp.DownloadExpiresAt = &expires
 	}
 
-	// projects.csv
-	{
-		f, _ := zw.Create("projects.csv")
-		w := csv.NewWriter(f)
-		_ = w.Write([]string{"id", "name", "owner_id", "created_at"})
-		for _, p := range projects {
-			_ = w.Write([]string{
-				p.ID,
-				p.Name,
-				p.OwnerID,
-				p.CreatedAt.Format(time.RFC3339),
-			})
-		}
-		w.Flush()
+	c.JSON(http.StatusOK, resp)
+}
+
+// DownloadExport serves a finished export. It needs no Authorization
+// header: the signed, expiring link is the credential.
+func (h *AdminExportHandler) DownloadExport(c *gin.Context) {
+	if h.jobsDisabled(c) {
+		return
 	}
 
-	// tasks.csv
-	{
-		f, _ := zw.Create("tasks.csv")
-		w := csv.NewWriter(f)
-		_ = w.Write([]string{"id", "project_id", "title", "description", "status", "assignee_id", "created_at", "updated_at"})
-		for _, t := range tasks {
-			assignee := ""
-			if t.AssigneeID != nil {
-				assignee = t.AssigneeID.String()
-			}
-			_ = w.Write([]string{
-				t.ID.String(),
-				t.ProjectID.String(),
-				t.Title,
-				t.Description,
-				t.Status,
-				assignee,
-				t.CreatedAt.Format(time.RFC3339),
-				t.UpdatedAt.Format(time.RFC3339),
-			})
-		}
-		w.Flush()
+	f, job, err := h.jobs.Open(c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if errors.Is(err, services.ErrInvalidExportLink) {
+		RespondError(c, http.StatusForbidden, problem.CodeForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	defer f.Close()
+
+	filename := "export-" + job.CreatedAt.Format("20060102-150405") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	http.ServeContent(c.Writer, c.Request, filename, *job.FinishedAt, f)
+}
+
+func (h *AdminExportHandler) jobsDisabled(c *gin.Context) bool {
+	if h.jobs != nil {
+		return false
 	}
+	RespondError(c, http.StatusServiceUnavailable, problem.CodeUnavailable, "background exports are disabled")
+	return true
 }
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/services"
+)
+
+// maxImportSize caps the uploaded archive, which is held in memory because
+// zip needs random access.
+const maxImportSize = 64 << 20
+
+type AdminImportHandler struct {
+	service *services.ImportService
+}
+
+func NewAdminImportHandler(service *services.ImportService) *AdminImportHandler {
+	return &AdminImportHandler{service: service}
+}
+
+// ImportAll loads an archive produced by ExportAll. The archive comes as the
+// multipart field "file" or as the raw request body; ?mode=skip|upsert and
+// ?dryRun=true control how it is applied.
+func (h *AdminImportHandler) ImportAll(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	opts := services.ImportOptions{
+		Mode:   services.ImportMode(c.DefaultQuery("mode", string(services.ImportSkip))),
+		DryRun: dryRun,
+	}
+
+	data, err := readImportArchive(c)
+	if tooLarge(err) {
+		RespondError(c, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, "archive is larger than 64 MB")
+		return
+	}
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, err.Error())
+		return
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, "archive is not a valid zip file")
+		return
+	}
+
+	archive, err := services.ParseExportArchive(zr)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.Import(c.Request.Context(), archive, opts)
+	switch {
+	case errors.Is(err, services.ErrInvalidImport):
+		problem.Write(c, problem.New(http.StatusUnprocessableEntity, problem.CodeValidation, "archive contains invalid records").
+			With("report", report))
+	case errors.Is(err, services.ErrBadRequest):
+		respondInvalidField(c, "mode", "oneof", "must be skip or upsert")
+	case err != nil:
+		respondInternal(c, err)
+	default:
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func readImportArchive(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var src io.Reader = c.Request.Body
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	data, err := io.ReadAll(src)
+	if tooLarge(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("archive is unreadable")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("archive is empty")
+	}
+	return data, nil
+}
@@ -19,7 +19,7 @@ func NewAPIUserHandler(svc services.APIUserService) *APIUserHandler {
 func (h *APIUserHandler) ListMinimal(c *gin.Context) {
 	users, err := h.svc.ListMinimal(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal error"})
+		respondInternal(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, users)
@@ -8,17 +8,19 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/problem"
 	"task-management-platform/backend/internal/server/middleware"
 	"task-management-platform/backend/internal/services"
 	jwtutil "task-management-platform/backend/pkg/jwt"
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
+	authService      *services.AuthService
+	twoFactorService *services.TwoFactorService
 }
 
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *services.AuthService, twoFactorService *services.TwoFactorService) *AuthHandler {
+	return &AuthHandler{authService: authService, twoFactorService: twoFactorService}
 }
 
 type registerRequest struct {
@@ -35,10 +37,25 @@ type authResponse struct {
 	AccessToken string `json:"accessToken"`
 }
 
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+}
+
+type meResponse struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+	MFA    bool   `json:"mfa"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+	AccessToken   string   `json:"accessToken,omitempty"`
+}
+
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req registerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -47,17 +64,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user, err := h.authService.Register(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		if errors.Is(err, services.ErrEmailAlreadyExists) {
-			c.JSON(http.StatusConflict, gin.H{"message": "email already exists"})
-			return
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
 	token, err := jwtutil.GenerateToken(user.ID, user.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to generate token"})
+		respondInternal(c, err)
 		return
 	}
 
@@ -66,49 +79,186 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	req.Email = strings.TrimSpace(req.Email)
 	req.Password = strings.TrimSpace(req.Password)
 
-	user, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	result, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		if errors.Is(err, services.ErrInvalidCredentials) {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid credentials"})
+		var locked *services.LoginLockedError
+		if errors.As(err, &locked) {
+			writeServiceError(c, err)
 			return
 		}
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid credentials"})
+		// every other failure looks the same so logins reveal nothing
+		// about which accounts exist
+		RespondError(c, http.StatusUnauthorized, problem.CodeInvalidCredentials, "invalid credentials")
 		return
 	}
 
-	token, err := jwtutil.GenerateToken(user.ID, user.Role)
+	if result.ChallengeToken != "" {
+		c.JSON(http.StatusOK, twoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    result.ChallengeToken,
+		})
+		return
+	}
+
+	token, err := jwtutil.GenerateToken(result.User.ID, result.User.Role)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse{AccessToken: token})
+}
+
+// CompleteTwoFactorLogin is the second login step for accounts with
+// two-factor enabled.
+func (h *AuthHandler) CompleteTwoFactorLogin(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.authService.CompleteTwoFactorLogin(c.Request.Context(), req.ChallengeToken, req.Code, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to generate token"})
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			RespondError(c, http.StatusUnauthorized, problem.CodeUnauthorized, "login challenge expired, sign in again")
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			RespondError(c, http.StatusUnauthorized, problem.CodeInvalidCredentials, "invalid two-factor code")
+		default:
+			writeServiceError(c, err)
+		}
+		return
+	}
+
+	token, err := jwtutil.GenerateToken(user.ID, user.Role, jwtutil.WithMFA())
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, authResponse{AccessToken: token})
 }
 
+// Me describes the caller's session.
+func (h *AuthHandler) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, meResponse{
+		UserID: c.GetString(middleware.ContextUserIDKey),
+		Role:   c.GetString(middleware.ContextRoleKey),
+		MFA:    c.GetBool(middleware.ContextMFAKey),
+	})
+}
+
+// JWKS publishes the public keys access tokens can be verified with. Keys
+// change only with a restart, so caches may keep them briefly.
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwtutil.PublicKeys())
+}
+
+// BeginTwoFactorSetup returns a fresh secret and otpauth URI for the caller.
+func (h *AuthHandler) BeginTwoFactorSetup(c *gin.Context) {
+	userID := c.GetString(middleware.ContextUserIDKey)
+
+	setup, err := h.twoFactorService.BeginEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// ConfirmTwoFactorSetup enables two-factor and returns the recovery codes
+// together with an access token that already counts as a second-factor
+// session.
+func (h *AuthHandler) ConfirmTwoFactorSetup(c *gin.Context) {
+	userID := c.GetString(middleware.ContextUserIDKey)
+	role := c.GetString(middleware.ContextRoleKey)
+
+	var req dto.TwoFactorCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	codes, err := h.twoFactorService.ConfirmEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	token, err := jwtutil.GenerateToken(userID, role, jwtutil.WithMFA())
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes, AccessToken: token})
+}
+
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID := c.GetString(middleware.ContextUserIDKey)
+
+	var req dto.DisableTwoFactorRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, req.Password, req.Code); err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetString(middleware.ContextUserIDKey)
+
+	var req dto.TwoFactorCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func writeTwoFactorError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		// the caller is signed in; a wrong password is not a failed login
+		RespondError(c, http.StatusForbidden, problem.CodeForbidden, "password is incorrect")
+		return
+	}
+	writeUserError(c, err)
+}
+
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userIDAny, ok := c.Get(middleware.ContextUserIDKey)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+		RespondError(c, http.StatusUnauthorized, problem.CodeUnauthorized, "unauthorized")
 		return
 	}
 
 	userID, _ := userIDAny.(string)
 	if strings.TrimSpace(userID) == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+		RespondError(c, http.StatusUnauthorized, problem.CodeUnauthorized, "unauthorized")
 		return
 	}
 
 	var req dto.ChangePasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -117,17 +267,24 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	err := h.authService.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrInvalidCredentials):
-			c.JSON(http.StatusForbidden, gin.H{"message": "current password is incorrect"})
-			return
-		case errors.Is(err, services.ErrBadRequest):
-			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "internal error"})
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			RespondError(c, http.StatusForbidden, problem.CodeForbidden, "current password is incorrect")
 			return
 		}
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnlockAccount lets an admin clear the login lockout of a user.
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.authService.UnlockAccount(c.Request.Context(), id); err != nil {
+		writeUserError(c, err)
+		return
 	}
 
 	c.Status(http.StatusNoContent)
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/problem"
+)
+
+var useJSONFieldNames sync.Once
+
+// bindJSON decodes the request body into dst and runs its binding rules.
+// On failure it writes a problem naming the offending fields and returns
+// false.
+func bindJSON(c *gin.Context, dst any) bool {
+	useJSONFieldNames.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterTagNameFunc(jsonFieldName)
+		}
+	})
+
+	err := c.ShouldBindJSON(dst)
+	if err == nil {
+		return true
+	}
+
+	var invalid validator.ValidationErrors
+	var wrongType *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &invalid):
+		fields := make([]problem.FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			fields = append(fields, problem.FieldError{Field: fieldPath(fe), Code: fe.Tag(), Message: ruleMessage(fe)})
+		}
+		problem.Write(c, problem.New(http.StatusBadRequest, problem.CodeValidation, "some fields are invalid").WithErrors(fields...))
+	case errors.As(err, &wrongType):
+		problem.Write(c, problem.New(http.StatusBadRequest, problem.CodeValidation, "some fields are invalid").WithErrors(problem.FieldError{
+			Field:   wrongType.Field,
+			Code:    "type",
+			Message: "must be " + jsonKind(wrongType.Type),
+		}))
+	case errors.Is(err, io.EOF):
+		RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, "request body is empty")
+	default:
+		RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, "request body is not valid JSON")
+	}
+	return false
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// fieldPath drops the struct name from the validator's namespace, leaving
+// the JSON path of the field.
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "must be at least " + fe.Param() + " characters"
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must be at most " + fe.Param() + " characters"
+		}
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return "is invalid"
+	}
+}
+
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// respondInvalidField rejects a single parameter or body field that the
+// binding rules could not check, such as a malformed id.
+func respondInvalidField(c *gin.Context, field, code, message string) {
+	problem.Write(c, problem.New(http.StatusBadRequest, problem.CodeValidation, field+" "+message).
+		WithErrors(problem.FieldError{Field: field, Code: code, Message: message}))
+}
+
+// writeQueryError reports a query string the dto parsers rejected.
+func writeQueryError(c *gin.Context, err error) {
+	var bad *dto.InvalidParamError
+	if errors.As(err, &bad) {
+		respondInvalidField(c, bad.Param, "invalid", bad.Message)
+		return
+	}
+	RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, err.Error())
+}
+
+// tooLarge reports whether err comes from reading past an
+// http.MaxBytesReader limit.
+func tooLarge(err error) bool {
+	var mb *http.MaxBytesError
+	return errors.As(err, &mb)
+}
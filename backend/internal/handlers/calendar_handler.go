@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/services"
+)
+
+type CalendarHandler struct {
+	service *services.CalendarService
+}
+
+func NewCalendarHandler(service *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+type createCalendarFeedResponse struct {
+	// URL and WebcalURL embed the plaintext token and are only returned here.
+	URL       string               `json:"url"`
+	WebcalURL string               `json:"webcalUrl"`
+	Feed      *models.CalendarFeed `json:"feed"`
+}
+
+func (h *CalendarHandler) Create(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	var req dto.CreateCalendarFeedRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	plaintext, feed, err := h.service.Create(c.Request.Context(), actor, services.CreateCalendarFeedInput{
+		Name:       req.Name,
+		ProjectIDs: req.ProjectIDs,
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	path := "/api/calendar/" + plaintext + ".ics"
+	c.JSON(http.StatusCreated, createCalendarFeedResponse{
+		URL:       requestScheme(c) + "://" + c.Request.Host + path,
+		WebcalURL: "webcal://" + c.Request.Host + path,
+		Feed:      feed,
+	})
+}
+
+func (h *CalendarHandler) List(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	feeds, err := h.service.List(c.Request.Context(), actor)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+func (h *CalendarHandler) Revoke(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	if err := h.service.Revoke(c.Request.Context(), actor, c.Param("feedId")); err != nil {
+		writeCalendarFeedError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeCalendarFeedError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "calendar feed not found")
+		return
+	}
+	writeServiceError(c, err)
+}
+
+// Feed serves the calendar behind a secret URL. The token in the path is the
+// only credential, since calendar apps cannot send headers. ?type=todo
+// publishes VTODO entries instead of all-day events.
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "calendar feed not found")
+		return
+	}
+
+	component := services.CalendarEvents
+	switch c.Query("type") {
+	case "", string(services.CalendarEvents):
+	case string(services.CalendarTodos):
+		component = services.CalendarTodos
+	default:
+		respondInvalidField(c, "type", "oneof", "must be event or todo")
+		return
+	}
+
+	feed, tasks, err := h.service.Tasks(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			RespondError(c, http.StatusNotFound, problem.CodeNotFound, "calendar feed not found")
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteCalendarFeed(&buf, feed.Name, component, tasks, time.Now()); err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+func requestScheme(c *gin.Context) string {
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,7 @@
+package dto
+
+type CreateCalendarFeedRequest struct {
+	Name string `json:"name" binding:"max=100"`
+	// ProjectIDs adds all dated tasks of these projects to the feed.
+	ProjectIDs []string `json:"projectIds" binding:"max=20"`
+}
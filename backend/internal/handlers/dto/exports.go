@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"task-management-platform/backend/internal/services"
+)
+
+// ExportRequest is the body of POST /api/admin/exports and, through
+// ParseExportQuery, the query of GET /api/admin/export. Dates are RFC 3339
+// timestamps or plain YYYY-MM-DD days.
+type ExportRequest struct {
+	Format    string   `json:"format"`
+	Entities  []string `json:"entities"`
+	ProjectID string   `json:"projectId"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+}
+
+func ParseExportQuery(format, entities, projectID, from, to string) (services.ExportRequest, error) {
+	req := ExportRequest{Format: format, ProjectID: projectID, From: from, To: to}
+	if entities != "" {
+		req.Entities = strings.Split(entities, ",")
+	}
+	return req.ToService()
+}
+
+func (r ExportRequest) ToService() (services.ExportRequest, error) {
+	out := services.ExportRequest{Format: services.ExportFormat(strings.ToLower(r.Format))}
+
+	for _, e := range r.Entities {
+		if e = strings.TrimSpace(e); e != "" {
+			out.Entities = append(out.Entities, e)
+		}
+	}
+	if r.ProjectID != "" {
+		out.ProjectID = &r.ProjectID
+	}
+
+	var err error
+	if out.From, err = parseExportDate(r.From); err != nil {
+		return services.ExportRequest{}, err
+	}
+	if out.To, err = parseExportDate(r.To); err != nil {
+		return services.ExportRequest{}, err
+	}
+
+	if err := out.Normalize(); err != nil {
+		return services.ExportRequest{}, ErrInvalidQuery
+	}
+	return out, nil
+}
+
+func parseExportDate(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, ErrInvalidQuery
+	}
+	return &t, nil
+}
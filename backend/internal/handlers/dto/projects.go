@@ -7,3 +7,8 @@ type CreateProjectRequest struct {
 type UpdateProjectRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=120"`
 }
+
+type TransferProjectRequest struct {
+	// UserID is the user the project is offered to.
+	UserID string `json:"userId" binding:"required,uuid"`
+}
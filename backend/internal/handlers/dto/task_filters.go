@@ -11,6 +11,17 @@ import (
 
 var ErrInvalidQuery = errors.New("invalid query params")
 
+// InvalidParamError names the query parameter that could not be parsed. It
+// matches ErrInvalidQuery.
+type InvalidParamError struct {
+	Param   string
+	Message string
+}
+
+func (e *InvalidParamError) Error() string { return e.Param + " " + e.Message }
+
+func (e *InvalidParamError) Is(target error) bool { return target == ErrInvalidQuery }
+
 func ParseTaskFilters(
 	projectID uuid.UUID,
 	status string,
@@ -32,7 +43,7 @@ func ParseTaskFilters(
 	if assignee != "" {
 		id, err := uuid.Parse(assignee)
 		if err != nil {
-			return filters, ErrInvalidQuery
+			return filters, &InvalidParamError{Param: "assigneeId", Message: "must be a UUID"}
 		}
 		filters.AssigneeID = &id
 	}
@@ -40,7 +51,7 @@ func ParseTaskFilters(
 	if limitStr != "" {
 		limit, err := strconv.Atoi(limitStr)
 		if err != nil || limit <= 0 {
-			return filters, ErrInvalidQuery
+			return filters, &InvalidParamError{Param: "limit", Message: "must be a positive integer"}
 		}
 		if limit > 100 {
 			limit = 100
@@ -51,7 +62,7 @@ func ParseTaskFilters(
 	if offsetStr != "" {
 		offset, err := strconv.Atoi(offsetStr)
 		if err != nil || offset < 0 {
-			return filters, ErrInvalidQuery
+			return filters, &InvalidParamError{Param: "offset", Message: "must be a non-negative integer"}
 		}
 		filters.Offset = offset
 	}
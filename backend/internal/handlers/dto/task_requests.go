@@ -1,10 +1,21 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+
+	"task-management-platform/backend/internal/openapi"
+)
+
 type CreateTaskRequest struct {
 	Title       string  `json:"title" binding:"required"`
 	Description string  `json:"description"`
 	Status      string  `json:"status"`
 	AssigneeID  *string `json:"assigneeId"`
+	// DueDate is YYYY-MM-DD; omit or null for none.
+	DueDate *string `json:"dueDate"`
+	// ParentID makes the new task a subtask of another task in the project.
+	ParentID *string `json:"parentId"`
 }
 
 type UpdateTaskRequest struct {
@@ -12,4 +23,48 @@ type UpdateTaskRequest struct {
 	Description string  `json:"description"`
 	Status      string  `json:"status" binding:"required"`
 	AssigneeID  *string `json:"assigneeId"`
+	// DueDate is YYYY-MM-DD; null clears it and omitting it keeps it.
+	DueDate NullableDate `json:"dueDate"`
+}
+
+// NullableDate is a YYYY-MM-DD field that tells an omitted value (Set is
+// false) from an explicit null, so an update can keep or clear it.
+type NullableDate struct {
+	Set   bool
+	Value *string
+}
+
+func (d *NullableDate) UnmarshalJSON(data []byte) error {
+	d.Set = true
+	return json.Unmarshal(data, &d.Value)
+}
+
+func (NullableDate) OpenAPISchema() *openapi.Schema {
+	return &openapi.Schema{Type: []string{"string", "null"}}
+}
+
+type MoveTaskRequest struct {
+	// ParentID is the new parent task; null makes the task top-level.
+	ParentID *string `json:"parentId"`
+}
+
+type CreateChecklistItemRequest struct {
+	Title string `json:"title" binding:"required,max=200"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Done  bool   `json:"done"`
+}
+
+// ParseDueDate reads an optional YYYY-MM-DD day.
+func ParseDueDate(v *string) (*time.Time, error) {
+	if v == nil || *v == "" {
+		return nil, nil
+	}
+	d, err := time.Parse(time.DateOnly, *v)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
 }
@@ -9,6 +9,7 @@ type UserResponse struct {
 	ID        string    `json:"id"`
 	Email     string    `json:"email"`
 	Role      string    `json:"role"`
+	Kind      string    `json:"kind"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -17,6 +18,7 @@ func FromUser(u models.User) UserResponse {
 		ID:        u.ID,
 		Email:     u.Email,
 		Role:      u.Role,
+		Kind:      u.Kind,
 		CreatedAt: u.CreatedAt,
 	}
 }
@@ -1,10 +1,70 @@
 package handlers
 
-const (
-	CodeBadRequest   = "bad_request"
-	CodeUnauthorized = "unauthorized"
-	CodeForbidden    = "forbidden"
-	CodeNotFound     = "not_found"
-	CodeInternal     = "internal_error"
-	CodeBusinessRule = "business_rule_violation"
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/services"
 )
+
+// serviceErrors maps the sentinel errors of services and repositories to
+// responses. The first entry matching with errors.Is wins, and the
+// sentinel's own text becomes the detail, never a wrapped cause.
+var serviceErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{services.ErrBadRequest, http.StatusBadRequest, problem.CodeBadRequest},
+	{services.ErrEmailAndPasswordRequired, http.StatusBadRequest, problem.CodeBadRequest},
+	{services.ErrInvalidCredentials, http.StatusUnauthorized, problem.CodeInvalidCredentials},
+	{services.ErrInvalidToken, http.StatusUnauthorized, problem.CodeUnauthorized},
+	{services.ErrForbidden, http.StatusForbidden, problem.CodeForbidden},
+	{services.ErrCannotUpdateOwnRole, http.StatusForbidden, problem.CodeForbidden},
+	{services.ErrNotFound, http.StatusNotFound, problem.CodeNotFound},
+	{repository.ErrNotFound, http.StatusNotFound, problem.CodeNotFound},
+	{services.ErrEmailAlreadyExists, http.StatusConflict, problem.CodeConflict},
+	{services.ErrTwoFactorAlreadyEnabled, http.StatusConflict, problem.CodeConflict},
+	{services.ErrTwoFactorNotEnabled, http.StatusConflict, problem.CodeConflict},
+	{services.ErrProjectInTrash, http.StatusConflict, problem.CodeConflict},
+	{services.ErrParentTaskInTrash, http.StatusConflict, problem.CodeConflict},
+	{services.ErrInvalidTwoFactorCode, http.StatusUnprocessableEntity, problem.CodeValidation},
+	{services.ErrCannotDeleteOwnUser, http.StatusBadRequest, problem.CodeBusinessRule},
+	{services.ErrProjectArchived, http.StatusConflict, problem.CodeBusinessRule},
+	{services.ErrUserOwnsProjects, http.StatusConflict, problem.CodeBusinessRule},
+}
+
+// writeServiceError answers with the problem matching err. Validation errors
+// list their fields; anything unknown is a 500 whose cause is only logged.
+func writeServiceError(c *gin.Context, err error) {
+	var invalid *services.ValidationError
+	if errors.As(err, &invalid) {
+		fields := make([]problem.FieldError, 0, len(invalid.Fields))
+		for _, f := range invalid.Fields {
+			fields = append(fields, problem.FieldError{Field: f.Field, Code: f.Code, Message: f.Message})
+		}
+		problem.Write(c, problem.New(http.StatusBadRequest, problem.CodeValidation, "some fields are invalid").WithErrors(fields...))
+		return
+	}
+
+	var locked *services.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		RespondError(c, http.StatusTooManyRequests, problem.CodeLoginLocked, "too many failed login attempts, try again later")
+		return
+	}
+
+	for _, m := range serviceErrors {
+		if errors.Is(err, m.err) {
+			RespondError(c, m.status, m.code, m.err.Error())
+			return
+		}
+	}
+	respondInternal(c, err)
+}
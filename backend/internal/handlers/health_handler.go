@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"task-management-platform/backend/internal/migrate"
+)
+
+// readyTimeout bounds the database and Redis checks behind /readyz so a
+// hung connection fails the probe instead of stalling it.
+const readyTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db       func() *sql.DB
+	redis    func() *redis.Client
+	draining func() bool
+}
+
+// NewHealthHandler takes the database and Redis lazily because the probes
+// are registered before the connections are opened. A nil Redis client
+// means the instance runs without Redis and is not checked.
+func NewHealthHandler(db func() *sql.DB, redis func() *redis.Client, draining func() bool) *HealthHandler {
+	return &HealthHandler{db: db, redis: redis, draining: draining}
+}
+
+// Live reports that the process is up. It checks nothing else, so a
+// database outage does not get the pod restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	RespondOK(c, http.StatusOK, statusResponse{Status: "ok"})
+}
+
+type migrationState struct {
+	Version  uint `json:"version"`
+	Expected uint `json:"expected"`
+	Dirty    bool `json:"dirty"`
+}
+
+type readiness struct {
+	Status    string          `json:"status"`
+	Database  string          `json:"database"`
+	Redis     string          `json:"redis,omitempty"`
+	Migration *migrationState `json:"migration,omitempty"`
+}
+
+// Ready reports whether the instance should receive traffic: the database
+// and Redis answer, the schema is at the version this build expects, and
+// the server is not shutting down.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.draining() {
+		c.JSON(http.StatusServiceUnavailable, readiness{Status: "draining", Database: "unknown"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	redisState := ""
+	if client := h.redis(); client != nil {
+		redisState = "ok"
+		if err := client.Ping(ctx).Err(); err != nil {
+			_ = c.Error(err)
+			redisState = "unreachable"
+		}
+	}
+
+	db := h.db()
+	if err := db.PingContext(ctx); err != nil {
+		_ = c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, readiness{Status: "unavailable", Database: "unreachable", Redis: redisState})
+		return
+	}
+
+	st, err := migrate.Inspect(ctx, db)
+	if err != nil {
+		_ = c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, readiness{Status: "unavailable", Database: "ok", Redis: redisState})
+		return
+	}
+
+	resp := readiness{
+		Status:    "ok",
+		Database:  "ok",
+		Redis:     redisState,
+		Migration: &migrationState{Version: st.Current, Expected: st.Expected, Dirty: st.Dirty},
+	}
+	if st.Dirty || st.Current != st.Expected || redisState == "unreachable" {
+		resp.Status = "unavailable"
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	RespondOK(c, http.StatusOK, resp)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"task-management-platform/backend/internal/migrate"
+)
+
+// fakeDatabase answers the ping and the schema_migrations queries behind
+// /readyz, or refuses connections when down.
+type fakeDatabase struct {
+	down    bool
+	version int64
+}
+
+func (d *fakeDatabase) Connect(context.Context) (driver.Conn, error) {
+	if d.down {
+		return nil, errors.New("connection refused")
+	}
+	return &fakeConn{db: d}, nil
+}
+
+func (d *fakeDatabase) Driver() driver.Driver { return nil }
+
+type fakeConn struct {
+	db *fakeDatabase
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) Ping(context.Context) error {
+	if c.db.down {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "to_regclass"):
+		return &fakeRows{columns: []string{"to_regclass"}, values: [][]driver.Value{{"schema_migrations"}}}, nil
+	case strings.Contains(query, "FROM schema_migrations"):
+		return &fakeRows{columns: []string{"version", "dirty"}, values: [][]driver.Value{{c.db.version, false}}}, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	vs, err := migrate.Versions()
+	if err != nil || len(vs) == 0 {
+		t.Fatalf("Versions() = %v, %v", vs, err)
+	}
+	current := int64(vs[len(vs)-1])
+
+	cases := []struct {
+		name       string
+		dbDown     bool
+		redisDown  bool
+		noRedis    bool
+		draining   bool
+		wantStatus int
+		want       readiness
+	}{
+		{name: "all up", wantStatus: http.StatusOK, want: readiness{Status: "ok", Database: "ok", Redis: "ok"}},
+		{name: "without redis", noRedis: true, wantStatus: http.StatusOK, want: readiness{Status: "ok", Database: "ok"}},
+		{name: "database down", dbDown: true, wantStatus: http.StatusServiceUnavailable, want: readiness{Status: "unavailable", Database: "unreachable", Redis: "ok"}},
+		{name: "redis down", redisDown: true, wantStatus: http.StatusServiceUnavailable, want: readiness{Status: "unavailable", Database: "ok", Redis: "unreachable"}},
+		{name: "both down", dbDown: true, redisDown: true, wantStatus: http.StatusServiceUnavailable, want: readiness{Status: "unavailable", Database: "unreachable", Redis: "unreachable"}},
+		{name: "draining", draining: true, wantStatus: http.StatusServiceUnavailable, want: readiness{Status: "draining", Database: "unknown"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := sql.OpenDB(&fakeDatabase{down: tc.dbDown, version: current})
+			defer db.Close()
+
+			var client *redis.Client
+			if !tc.noRedis {
+				mr := miniredis.RunT(t)
+				client = redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+				defer client.Close()
+				if tc.redisDown {
+					mr.Close()
+				}
+			}
+
+			h := NewHealthHandler(
+				func() *sql.DB { return db },
+				func() *redis.Client { return client },
+				func() bool { return tc.draining },
+			)
+			r := gin.New()
+			r.GET("/readyz", h.Ready)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d. body=%s", w.Code, tc.wantStatus, w.Body.String())
+			}
+
+			var got readiness
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode body %q: %v", w.Body.String(), err)
+			}
+			got.Migration = nil
+			if got != tc.want {
+				t.Fatalf("body = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
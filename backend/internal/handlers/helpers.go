@@ -1,29 +1,15 @@
 package handlers
 
 import (
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"task-management-platform/backend/internal/models"
-	"task-management-platform/backend/internal/services"
+	"task-management-platform/backend/internal/server/middleware"
 )
 
-func writeServiceError(c *gin.Context, err error) {
-	switch err {
-	case services.ErrBadRequest:
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	case services.ErrForbidden:
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-	case services.ErrNotFound:
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
-	}
-}
-
 func mustGetActor(c *gin.Context) models.User {
 	userID := c.GetString("userId")
 	role := c.GetString("role")
@@ -42,6 +28,13 @@ func mustGetActor(c *gin.Context) models.User {
 	}
 }
 
+// projectAllowed reports whether the caller may touch projectID. Only
+// personal access tokens restricted to another project may not.
+func projectAllowed(c *gin.Context, projectID string) bool {
+	restricted, ok := middleware.TokenProject(c)
+	return !ok || restricted == projectID
+}
+
 func parseIntDefault(s string, def int) int {
 	if s == "" {
 		return def
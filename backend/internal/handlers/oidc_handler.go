@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/server/middleware"
+	"task-management-platform/backend/internal/services"
+	jwtutil "task-management-platform/backend/pkg/jwt"
+)
+
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/auth/oidc"
+)
+
+type oidcLinkTicketResponse struct {
+	// Ticket goes to GET /auth/oidc/link?ticket=… within two minutes.
+	Ticket string `json:"ticket"`
+}
+
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+	// postLoginURL is the frontend page that receives the access token in
+	// the URL fragment. When empty the callback answers with JSON instead.
+	postLoginURL string
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService, postLoginURL string) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, postLoginURL: postLoginURL}
+}
+
+// Login redirects the browser to the identity provider.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	login, err := h.oidcService.BeginLogin()
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	h.redirect(c, login)
+}
+
+// LinkTicket lets the signed-in caller link an identity to their account.
+// The browser then navigates to Link with the ticket.
+func (h *OIDCHandler) LinkTicket(c *gin.Context) {
+	ticket, err := h.oidcService.LinkTicket(c.GetString(middleware.ContextUserIDKey))
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	RespondOK(c, http.StatusOK, oidcLinkTicketResponse{Ticket: ticket})
+}
+
+// Link redirects the browser to the identity provider to link the identity
+// it signs in with to the account that requested the ticket.
+func (h *OIDCHandler) Link(c *gin.Context) {
+	login, err := h.oidcService.BeginLink(c.Query("ticket"))
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCState) {
+			h.fail(c, http.StatusBadRequest, problem.CodeBadRequest, "invalid or expired link request")
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+	h.redirect(c, login)
+}
+
+func (h *OIDCHandler) redirect(c *gin.Context, login *services.OIDCLogin) {
+	// Lax so the cookie survives the top-level redirect back from the IdP.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, login.Cookie, 600, oidcStateCookiePath, "", isHTTPS(c), true)
+
+	c.Redirect(http.StatusFound, login.URL)
+}
+
+// Callback finishes the flow and hands the frontend the same access token a
+// password login would.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	// the state is single use
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, "", isHTTPS(c), true)
+
+	if e := c.Query("error"); e != "" {
+		h.fail(c, http.StatusUnauthorized, problem.CodeUnauthorized, "sign-in was cancelled or denied: "+e)
+		return
+	}
+
+	result, err := h.oidcService.CompleteLogin(c.Request.Context(), cookie, c.Query("state"), c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCState):
+			h.fail(c, http.StatusBadRequest, problem.CodeBadRequest, err.Error())
+		case errors.Is(err, services.ErrForbidden):
+			h.fail(c, http.StatusForbidden, problem.CodeForbidden, "service accounts cannot sign in")
+		case errors.Is(err, services.ErrOIDCEmailNotVerified), errors.Is(err, services.ErrOIDCEmailMismatch):
+			h.fail(c, http.StatusForbidden, problem.CodeForbidden, err.Error())
+		case errors.Is(err, services.ErrOIDCAccountExists), errors.Is(err, services.ErrOIDCIdentityLinked):
+			h.fail(c, http.StatusConflict, problem.CodeConflict, err.Error())
+		case errors.Is(err, services.ErrOIDCExchange):
+			_ = c.Error(err)
+			h.fail(c, http.StatusUnauthorized, problem.CodeUnauthorized, services.ErrOIDCExchange.Error())
+		default:
+			_ = c.Error(err)
+			h.fail(c, http.StatusInternalServerError, problem.CodeInternal, "internal error")
+		}
+		return
+	}
+
+	if result.Linked {
+		if h.postLoginURL == "" {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.Redirect(http.StatusFound, h.postLoginURL+"#linked=1")
+		return
+	}
+
+	var opts []jwtutil.TokenOption
+	if result.MFA {
+		opts = append(opts, jwtutil.WithMFA())
+	}
+
+	token, err := jwtutil.GenerateToken(result.User.ID, result.User.Role, opts...)
+	if err != nil {
+		_ = c.Error(err)
+		h.fail(c, http.StatusInternalServerError, problem.CodeInternal, "failed to generate token")
+		return
+	}
+
+	if h.postLoginURL == "" {
+		c.JSON(http.StatusOK, authResponse{AccessToken: token})
+		return
+	}
+
+	// The fragment never reaches a server, so the token does not end up in
+	// proxy or access logs.
+	c.Redirect(http.StatusFound, h.postLoginURL+"#"+url.Values{"accessToken": {token}}.Encode())
+}
+
+func (h *OIDCHandler) fail(c *gin.Context, status int, code, message string) {
+	if h.postLoginURL == "" {
+		RespondError(c, status, code, message)
+		return
+	}
+	c.Redirect(http.StatusFound, h.postLoginURL+"#"+url.Values{"error": {message}}.Encode())
+}
+
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/openapi"
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/services"
+	jwtutil "task-management-platform/backend/pkg/jwt"
+)
+
+// APISpec describes every route of routes.Register and the health probes.
+// Schemas come from the request and response types the handlers use, so
+// renaming a JSON field changes the document with it; the routes tests fail
+// when a route is added without an entry here.
+func APISpec() *openapi.Document {
+	d := openapi.New(openapi.Info{
+		Title:   "Task Management API",
+		Version: "1.0.0",
+		Description: "Errors are `application/problem+json` documents with a stable `code`. " +
+			"Authenticated routes take a JWT or a personal access token as a bearer token.",
+	}, problem.Problem{})
+
+	describeHealth(d)
+	describeAuth(d)
+	describeUsers(d)
+	describeAccessTokens(d)
+	describeProjects(d)
+	describeTasks(d)
+	describeTrash(d)
+	describeTransfers(d)
+	describeAdmin(d)
+	describeCalendar(d)
+	return d
+}
+
+func describeHealth(d *openapi.Document) {
+	d.Op(http.MethodGet, "/livez", "live", "Liveness probe").Tag("health").
+		Returns(http.StatusOK, statusResponse{}, "The process is up")
+	d.Op(http.MethodGet, "/readyz", "ready", "Readiness probe").Tag("health").
+		Returns(http.StatusOK, readiness{}, "Ready for traffic").
+		Returns(http.StatusServiceUnavailable, readiness{}, "Draining, database unreachable or schema out of date")
+	d.Op(http.MethodGet, "/health", "health", "Liveness probe, kept for older deployments").Tag("health").
+		Returns(http.StatusOK, statusResponse{}, "The process is up")
+}
+
+func describeAuth(d *openapi.Document) {
+	d.Op(http.MethodPost, "/auth/register", "register", "Create an account").Tag("auth").
+		Body(registerRequest{}).
+		Returns(http.StatusCreated, authResponse{}, "Account created").
+		Fails(http.StatusConflict)
+	d.Op(http.MethodPost, "/auth/login", "login", "Sign in with email and password").Tag("auth").
+		Body(loginRequest{}).
+		ReturnsSchema(http.StatusOK, &openapi.Schema{AnyOf: []*openapi.Schema{
+			d.SchemaOf(authResponse{}),
+			d.SchemaOf(twoFactorChallengeResponse{}),
+		}}, "An access token, or a challenge when two-factor is enabled").
+		Fails(http.StatusUnauthorized)
+	d.Op(http.MethodPost, "/auth/login/2fa", "completeTwoFactorLogin", "Finish a login with a TOTP or recovery code").Tag("auth").
+		Body(dto.TwoFactorLoginRequest{}).
+		Returns(http.StatusOK, authResponse{}, "Signed in").
+		Fails(http.StatusUnauthorized)
+	d.Op(http.MethodGet, "/.well-known/jwks.json", "jwks", "Public keys that verify access tokens").Tag("auth").
+		Describe("Empty while tokens are signed with a shared HS256 secret.").
+		Returns(http.StatusOK, jwtutil.JWKSet{}, "A JSON Web Key Set")
+	d.Op(http.MethodGet, "/auth/me", "me", "Describe the current session").Tag("auth").Secured().
+		Returns(http.StatusOK, meResponse{}, "The session")
+	d.Op(http.MethodPost, "/auth/change-password", "changePassword", "Change the caller's password").Tag("auth").Secured().
+		Body(dto.ChangePasswordRequest{}).
+		Returns(http.StatusNoContent, nil, "Password changed")
+
+	d.Op(http.MethodPost, "/auth/2fa/setup", "beginTwoFactorSetup", "Start two-factor enrollment").Tag("two-factor").Secured().
+		Returns(http.StatusOK, services.TwoFactorSetup{}, "Secret and otpauth URI for an authenticator app").
+		Fails(http.StatusConflict)
+	d.Op(http.MethodPost, "/auth/2fa/confirm", "confirmTwoFactorSetup", "Enable two-factor with a first code").Tag("two-factor").Secured().
+		Body(dto.TwoFactorCodeRequest{}).
+		Returns(http.StatusOK, recoveryCodesResponse{}, "Recovery codes and a second-factor session").
+		Fails(http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Op(http.MethodPost, "/auth/2fa/disable", "disableTwoFactor", "Disable two-factor").Tag("two-factor").Secured().
+		Body(dto.DisableTwoFactorRequest{}).
+		Returns(http.StatusNoContent, nil, "Two-factor disabled").
+		Fails(http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Op(http.MethodPost, "/auth/2fa/recovery-codes", "regenerateRecoveryCodes", "Replace the recovery codes").Tag("two-factor").Secured().
+		Body(dto.TwoFactorCodeRequest{}).
+		Returns(http.StatusOK, recoveryCodesResponse{}, "The new recovery codes").
+		Fails(http.StatusConflict, http.StatusUnprocessableEntity)
+
+	d.Op(http.MethodGet, "/auth/admin/ping", "adminPing", "Check admin access").Tag("admin").Secured().
+		Returns(http.StatusOK, statusResponse{}, "The caller is an admin")
+	d.Op(http.MethodPost, "/auth/admin/users/:id/unlock", "unlockAccount", "Clear a user's login lockout").Tag("admin").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Unlocked").
+		Fails(http.StatusNotFound)
+
+	d.Op(http.MethodGet, "/auth/oidc/login", "oidcLogin", "Sign in through the identity provider").Tag("auth").
+		Redirects("To the identity provider")
+	d.Op(http.MethodPost, "/auth/oidc/link", "oidcLinkTicket", "Ask to link an identity provider account").Tag("auth").Secured().
+		Describe("Returns a short-lived ticket for GET /auth/oidc/link. The provider account's verified email must match the caller's.").
+		Returns(http.StatusOK, oidcLinkTicketResponse{}, "Link ticket")
+	d.Op(http.MethodGet, "/auth/oidc/link", "oidcLink", "Link an identity provider account").Tag("auth").
+		Query("ticket", openapi.String(), "From POST /auth/oidc/link").
+		Redirects("To the identity provider").
+		Fails(http.StatusBadRequest)
+	d.Op(http.MethodGet, "/auth/oidc/callback", "oidcCallback", "Return from the identity provider").Tag("auth").
+		Query("state", openapi.String(), "").
+		Query("code", openapi.String(), "").
+		Query("error", openapi.String(), "Set by the provider when sign-in failed").
+		Redirects("To the frontend, with the access token, linked=1 or the error in the URL fragment").
+		Returns(http.StatusOK, authResponse{}, "Signed in, when no post-login URL is configured").
+		Returns(http.StatusNoContent, nil, "Identity linked, when no post-login URL is configured").
+		Fails(http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict)
+}
+
+func describeUsers(d *openapi.Document) {
+	d.Op(http.MethodGet, "/users", "listUsers", "List users").Tag("users").Secured().
+		Returns(http.StatusOK, []dto.UserResponse{}, "All users")
+	d.Op(http.MethodGet, "/users/:id", "getUser", "Get a user").Tag("users").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, dto.UserResponse{}, "The user").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/users", "createUser", "Create a user").Tag("users").Secured().
+		Body(dto.CreateUserRequest{}).
+		Returns(http.StatusCreated, dto.UserResponse{}, "Created").
+		Fails(http.StatusConflict)
+	d.Op(http.MethodPost, "/users/service-accounts", "createServiceAccount", "Create a service account").Tag("users").Secured().
+		Body(dto.CreateServiceAccountRequest{}).
+		Returns(http.StatusCreated, dto.UserResponse{}, "Created").
+		Fails(http.StatusConflict)
+	d.Op(http.MethodPut, "/users/:id", "updateUser", "Change a user's email or role").Tag("users").Secured().
+		PathParam("id", openapi.UUID()).
+		Body(dto.UpdateUserRequest{}).
+		Returns(http.StatusOK, statusResponse{}, "Updated").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodDelete, "/users/:id", "deleteUser", "Delete a user").Tag("users").Secured().
+		Describe("A user who owns projects is only deleted when `transferProjectsTo` names the user who takes all of them over.").
+		PathParam("id", openapi.UUID()).
+		Query("transferProjectsTo", openapi.UUID(), "The new owner of the deleted user's projects").
+		Returns(http.StatusOK, statusResponse{}, "Deleted").
+		Fails(http.StatusNotFound, http.StatusConflict)
+}
+
+func describeAccessTokens(d *openapi.Document) {
+	d.Op(http.MethodGet, "/auth/tokens", "listAccessTokens", "List the caller's access tokens").Tag("access tokens").Secured().
+		Returns(http.StatusOK, []models.AccessToken{}, "The tokens, revoked ones included")
+	d.Op(http.MethodPost, "/auth/tokens", "createAccessToken", "Issue an access token").Tag("access tokens").Secured().
+		Body(dto.CreateAccessTokenRequest{}).
+		Returns(http.StatusCreated, createAccessTokenResponse{}, "The token; its secret is only shown here")
+	d.Op(http.MethodDelete, "/auth/tokens/:tokenId", "revokeAccessToken", "Revoke an access token").Tag("access tokens").Secured().
+		PathParam("tokenId", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Revoked").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodGet, "/users/:id/tokens", "listUserAccessTokens", "List a service account's access tokens").Tag("access tokens").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, []models.AccessToken{}, "The tokens").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/users/:id/tokens", "createUserAccessToken", "Issue an access token to a service account").Tag("access tokens").Secured().
+		PathParam("id", openapi.UUID()).
+		Body(dto.CreateAccessTokenRequest{}).
+		Returns(http.StatusCreated, createAccessTokenResponse{}, "The token; its secret is only shown here").
+		Fails(http.StatusNotFound)
+}
+
+func describeProjects(d *openapi.Document) {
+	d.Op(http.MethodPost, "/api/projects", "createProject", "Create a project").Tag("projects").Secured().
+		Body(dto.CreateProjectRequest{}).
+		Returns(http.StatusCreated, models.Project{}, "Created")
+	d.Op(http.MethodGet, "/api/projects", "listProjects", "List the projects the caller can see").Tag("projects").Secured().
+		Query("archived", openapi.Boolean(), "List archived projects instead of active ones").
+		Returns(http.StatusOK, []models.Project{}, "The projects")
+	d.Op(http.MethodGet, "/api/projects/:id", "getProject", "Get a project").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.Project{}, "The project").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPut, "/api/projects/:id", "renameProject", "Rename a project").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Body(dto.UpdateProjectRequest{}).
+		Returns(http.StatusNoContent, nil, "Renamed").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodDelete, "/api/projects/:id", "deleteProject", "Move a project and its tasks to the trash").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Deleted").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/api/projects/:id/archive", "archiveProject", "Archive a project, making it and its tasks read-only").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.Project{}, "The archived project").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/api/projects/:id/unarchive", "unarchiveProject", "Unarchive a project").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.Project{}, "The active project").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodGet, "/api/projects/:id/report.pdf", "projectReport", "Render the project's status report").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		ReturnsFile(http.StatusOK, "application/pdf", "The report").
+		Fails(http.StatusNotFound)
+}
+
+var taskStatus = openapi.Enum("todo", "in_progress", "done")
+
+func describeTasks(d *openapi.Document) {
+	d.Op(http.MethodPost, "/api/projects/:id/tasks", "createTask", "Create a task").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		Body(dto.CreateTaskRequest{}).
+		Returns(http.StatusCreated, models.Task{}, "Created").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodGet, "/api/projects/:id/tasks", "listTasks", "List a project's tasks").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		Query("status", taskStatus, "").
+		Query("assigneeId", openapi.UUID(), "").
+		Query("limit", openapi.Integer(1), "Page size, at most 100; defaults to 20").
+		Query("offset", openapi.Integer(0), "").
+		Returns(http.StatusOK, []models.Task{}, "The tasks").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/api/projects/:id/tasks/import", "importTasks", "Create tasks from a CSV or JSON file").Tag("tasks").Secured().
+		Describe("The file is the multipart field `file` or the raw body. One invalid row rejects the whole upload.").
+		PathParam("id", openapi.UUID()).
+		Query("format", openapi.Enum("csv", "json"), "Overrides the content type and file extension").
+		Query("dryRun", openapi.Boolean(), "Validate only").
+		Upload(openapi.ArrayOf(&openapi.Schema{Type: "object"}), openapi.MediaJSON, "text/csv", openapi.MediaMultipart).
+		Returns(http.StatusOK, services.TaskImportReport{}, "Dry run result").
+		Returns(http.StatusCreated, services.TaskImportReport{}, "Tasks created").
+		Fails(http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType).
+		FailsWith(http.StatusUnprocessableEntity, "report", services.TaskImportReport{}, "Some rows are invalid; nothing was created")
+	d.Op(http.MethodGet, "/api/tasks/:id", "getTask", "Get a task with its subtasks and checklist").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.TaskDetail{}, "The task").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPut, "/api/tasks/:id", "updateTask", "Replace a task's fields").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		Body(dto.UpdateTaskRequest{}).
+		Returns(http.StatusOK, models.Task{}, "The updated task").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodDelete, "/api/tasks/:id", "deleteTask", "Move a task to the trash").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Deleted").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodPut, "/api/tasks/:id/parent", "moveTask", "Make a task a subtask of another, or top-level").Tag("tasks").Secured().
+		Describe("The parent is a task of the same project. Tasks nest at most three levels deep and never under their own subtasks.").
+		PathParam("id", openapi.UUID()).
+		Body(dto.MoveTaskRequest{}).
+		Returns(http.StatusOK, models.Task{}, "The moved task").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodPost, "/api/tasks/:id/checklist", "addChecklistItem", "Add an item to a task's checklist").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		Body(dto.CreateChecklistItemRequest{}).
+		Returns(http.StatusCreated, models.ChecklistItem{}, "Created").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodPut, "/api/tasks/:id/checklist/:itemId", "updateChecklistItem", "Rename or tick a checklist item").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		PathParam("itemId", openapi.UUID()).
+		Body(dto.UpdateChecklistItemRequest{}).
+		Returns(http.StatusOK, models.ChecklistItem{}, "The updated item").
+		Fails(http.StatusNotFound, http.StatusConflict)
+	d.Op(http.MethodDelete, "/api/tasks/:id/checklist/:itemId", "deleteChecklistItem", "Remove a checklist item").Tag("tasks").Secured().
+		PathParam("id", openapi.UUID()).
+		PathParam("itemId", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Deleted").
+		Fails(http.StatusNotFound, http.StatusConflict)
+
+	d.Op(http.MethodGet, "/api/users", "listAssignableUsers", "List users tasks can be assigned to").Tag("tasks").Secured().
+		Returns(http.StatusOK, []models.MinimalUser{}, "The users")
+}
+
+func describeTrash(d *openapi.Document) {
+	d.Op(http.MethodGet, "/api/trash", "listTrash", "List the caller's deleted projects and tasks").Tag("trash").Secured().
+		Describe("Tasks deleted together with their project are not listed; restoring the project brings them back. Items are purged for good after the retention period.").
+		Returns(http.StatusOK, services.Trash{}, "The trash")
+	d.Op(http.MethodPost, "/api/projects/:id/restore", "restoreProject", "Restore a deleted project and the tasks deleted with it").Tag("trash").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.Project{}, "The restored project").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/api/tasks/:id/restore", "restoreTask", "Restore a deleted task").Tag("trash").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.Task{}, "The restored task").
+		Fails(http.StatusNotFound, http.StatusConflict)
+}
+
+func describeTransfers(d *openapi.Document) {
+	d.Op(http.MethodGet, "/api/project-transfers", "listProjectTransfers", "List pending transfers offered to the caller or of the caller's projects").Tag("projects").Secured().
+		Returns(http.StatusOK, []models.ProjectTransfer{}, "The transfers")
+	d.Op(http.MethodPost, "/api/projects/:id/transfer", "offerProject", "Offer a project to another user").Tag("projects").Secured().
+		Describe("The project changes hands once the user accepts. A new offer replaces a pending one.").
+		PathParam("id", openapi.UUID()).
+		Body(dto.TransferProjectRequest{}).
+		Returns(http.StatusCreated, models.ProjectTransfer{}, "Offered").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodPost, "/api/projects/:id/transfer/accept", "acceptProjectTransfer", "Accept a project offered to the caller").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, models.Project{}, "The project, now owned by the caller").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodDelete, "/api/projects/:id/transfer", "cancelProjectTransfer", "Withdraw or decline a pending transfer").Tag("projects").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Withdrawn").
+		Fails(http.StatusNotFound)
+}
+
+var exportDate = openapi.String().WithDescription("RFC 3339 timestamp or YYYY-MM-DD")
+
+func describeAdmin(d *openapi.Document) {
+	d.Op(http.MethodGet, "/api/admin/export", "exportAll", "Stream an export archive").Tag("admin").Secured().
+		Query("format", openapi.Enum("csv", "json", "ndjson"), "Defaults to csv").
+		Query("entities", openapi.String(), "Comma-separated subset of users,projects,tasks,checklist_items").
+		Query("projectId", openapi.UUID(), "").
+		Query("from", exportDate, "Earliest created_at; projects, parent tasks and users the rows refer to are included regardless, so the archive restores as-is").
+		Query("to", exportDate, "Exclusive upper bound on created_at").
+		ReturnsFile(http.StatusOK, "application/zip", "The archive")
+	d.Op(http.MethodPost, "/api/admin/exports", "startExport", "Start a background export").Tag("admin").Secured().
+		OptionalBody(dto.ExportRequest{}).
+		Returns(http.StatusAccepted, services.ExportJob{}, "The queued job").
+		Fails(http.StatusServiceUnavailable)
+	d.Op(http.MethodGet, "/api/admin/exports/:id", "exportStatus", "Get a background export").Tag("admin").Secured().
+		PathParam("id", openapi.UUID()).
+		Returns(http.StatusOK, exportJobResponse{}, "The job, with a signed download link once done").
+		Fails(http.StatusNotFound, http.StatusServiceUnavailable)
+	d.Op(http.MethodGet, "/api/admin/exports/:id/download", "downloadExport", "Download a finished export").Tag("admin").
+		Describe("Needs no Authorization header: the signed, expiring link is the credential.").
+		PathParam("id", openapi.UUID()).
+		Query("expires", openapi.String(), "").
+		Query("signature", openapi.String(), "").
+		ReturnsFile(http.StatusOK, "application/zip", "The archive").
+		Fails(http.StatusForbidden, http.StatusServiceUnavailable)
+	d.Op(http.MethodPost, "/api/admin/import", "importAll", "Load an export archive").Tag("admin").Secured().
+		Query("mode", openapi.Enum("skip", "upsert"), "What to do with rows whose id exists; defaults to skip").
+		Query("dryRun", openapi.Boolean(), "Report without writing").
+		Upload(nil, "application/zip", openapi.MediaMultipart).
+		Returns(http.StatusOK, services.ImportReport{}, "What was, or would be, written").
+		Fails(http.StatusRequestEntityTooLarge).
+		FailsWith(http.StatusUnprocessableEntity, "report", services.ImportReport{}, "The archive has problems; nothing was written")
+}
+
+func describeCalendar(d *openapi.Document) {
+	d.Op(http.MethodGet, "/api/calendar/feeds", "listCalendarFeeds", "List the caller's calendar feeds").Tag("calendar").Secured().
+		Returns(http.StatusOK, []models.CalendarFeed{}, "The feeds, revoked ones included")
+	d.Op(http.MethodPost, "/api/calendar/feeds", "createCalendarFeed", "Create a calendar feed").Tag("calendar").Secured().
+		Body(dto.CreateCalendarFeedRequest{}).
+		Returns(http.StatusCreated, createCalendarFeedResponse{}, "The feed; its URLs are only shown here")
+	d.Op(http.MethodDelete, "/api/calendar/feeds/:feedId", "revokeCalendarFeed", "Revoke a calendar feed").Tag("calendar").Secured().
+		PathParam("feedId", openapi.UUID()).
+		Returns(http.StatusNoContent, nil, "Revoked").
+		Fails(http.StatusNotFound)
+	d.Op(http.MethodGet, "/api/calendar/:token", "calendarFeed", "Read a calendar feed").Tag("calendar").
+		Describe("The secret path, ending in `.ics`, is the credential.").
+		Query("type", openapi.Enum("event", "todo"), "Publish all-day events (default) or VTODO entries").
+		ReturnsFile(http.StatusOK, "text/calendar", "The iCalendar document").
+		Fails(http.StatusNotFound)
+}
@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"slices"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
 	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/server/middleware"
 	"task-management-platform/backend/internal/services"
 )
 
@@ -27,21 +33,28 @@ func (h *ProjectHandler) Register(rg *gin.RouterGroup) {
 		projects.GET("/:id", h.getByID)
 		projects.PUT("/:id", h.update)
 		projects.DELETE("/:id", h.delete)
+		projects.POST("/:id/archive", h.archive)
+		projects.POST("/:id/unarchive", h.unarchive)
 	}
 }
 
 func (h *ProjectHandler) create(c *gin.Context) {
 	var req dto.CreateProjectRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	userID := c.GetString("userId")
 
+	// a token limited to one project cannot widen its own reach
+	if _, restricted := middleware.TokenProject(c); restricted {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+
 	p, err := h.service.Create(c.Request.Context(), userID, req.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -53,29 +66,45 @@ func (h *ProjectHandler) list(c *gin.Context) {
 	role := c.GetString("role")
 
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing user id"})
+		RespondError(c, http.StatusUnauthorized, problem.CodeUnauthorized, "missing user id")
 		return
 	}
 
-	projects, err := h.service.List(c.Request.Context(), userID, role)
+	archived, err := strconv.ParseBool(c.DefaultQuery("archived", "false"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInvalidField(c, "archived", "boolean", "must be true or false")
 		return
 	}
 
+	projects, err := h.service.List(c.Request.Context(), userID, role, archived)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	if restricted, ok := middleware.TokenProject(c); ok {
+		projects = slices.DeleteFunc(projects, func(p models.Project) bool {
+			return p.ID != restricted
+		})
+	}
+
 	c.JSON(http.StatusOK, projects)
 }
 
 func (h *ProjectHandler) getByID(c *gin.Context) {
 	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
 	userID := c.GetString("userId")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing user id"})
+		RespondError(c, http.StatusUnauthorized, problem.CodeUnauthorized, "missing user id")
 		return
 	}
 
 	if _, err := uuid.Parse(userID); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		RespondError(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid user id")
 		return
 	}
 
@@ -83,14 +112,7 @@ func (h *ProjectHandler) getByID(c *gin.Context) {
 
 	p, err := h.service.GetByID(c.Request.Context(), userID, role, id)
 	if err != nil {
-		switch err {
-		case services.ErrForbidden:
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
-		case repository.ErrNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		writeProjectError(c, err)
 		return
 	}
 
@@ -99,24 +121,20 @@ func (h *ProjectHandler) getByID(c *gin.Context) {
 
 func (h *ProjectHandler) update(c *gin.Context) {
 	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
 	userID := c.GetString("userId")
 	role := c.GetString("role")
 
 	var req dto.UpdateProjectRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	if err := h.service.UpdateName(c.Request.Context(), userID, role, id, req.Name); err != nil {
-		switch err {
-		case services.ErrForbidden:
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
-		case repository.ErrNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		writeProjectError(c, err)
 		return
 	}
 
@@ -125,20 +143,53 @@ func (h *ProjectHandler) update(c *gin.Context) {
 
 func (h *ProjectHandler) delete(c *gin.Context) {
 	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
 	userID := c.GetString("userId")
 	role := c.GetString("role")
 
 	if err := h.service.Delete(c.Request.Context(), userID, role, id); err != nil {
-		switch err {
-		case services.ErrForbidden:
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
-		case repository.ErrNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		writeProjectError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *ProjectHandler) archive(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+func (h *ProjectHandler) unarchive(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+func (h *ProjectHandler) setArchived(c *gin.Context, archived bool) {
+	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+	userID := c.GetString("userId")
+	role := c.GetString("role")
+
+	p, err := h.service.SetArchived(c.Request.Context(), userID, role, id, archived)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// writeProjectError names the missing resource instead of the generic "not
+// found".
+func writeProjectError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "project not found")
+		return
+	}
+	writeServiceError(c, err)
+}
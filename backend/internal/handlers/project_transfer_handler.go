@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers/dto"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/server/middleware"
+	"task-management-platform/backend/internal/services"
+)
+
+type ProjectTransferHandler struct {
+	service *services.ProjectTransferService
+}
+
+func NewProjectTransferHandler(service *services.ProjectTransferService) *ProjectTransferHandler {
+	return &ProjectTransferHandler{service: service}
+}
+
+func (h *ProjectTransferHandler) List(c *gin.Context) {
+	transfers, err := h.service.List(c.Request.Context(), mustGetActor(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	if restricted, ok := middleware.TokenProject(c); ok {
+		transfers = slices.DeleteFunc(transfers, func(t models.ProjectTransfer) bool {
+			return t.ProjectID != restricted
+		})
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *ProjectTransferHandler) Offer(c *gin.Context) {
+	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+
+	var req dto.TransferProjectRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	transfer, err := h.service.Offer(c.Request.Context(), mustGetActor(c), id, req.UserID)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *ProjectTransferHandler) Accept(c *gin.Context) {
+	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+
+	p, err := h.service.Accept(c.Request.Context(), mustGetActor(c), id)
+	if err != nil {
+		writeTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ProjectTransferHandler) Cancel(c *gin.Context) {
+	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+
+	if err := h.service.Cancel(c.Request.Context(), mustGetActor(c), id); err != nil {
+		writeTransferError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeTransferError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "no pending transfer for this project")
+		return
+	}
+	writeServiceError(c, err)
+}
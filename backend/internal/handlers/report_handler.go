@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/services"
+)
+
+type ReportHandler struct {
+	reports *services.ReportService
+}
+
+func NewReportHandler(reports *services.ReportService) *ReportHandler {
+	return &ReportHandler{reports: reports}
+}
+
+// ProjectPDF renders the project's status report. The PDF is built in memory
+// so a rendering failure can still be reported as JSON.
+func (h *ReportHandler) ProjectPDF(c *gin.Context) {
+	id := c.Param("id")
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+	userID := c.GetString("userId")
+	role := c.GetString("role")
+
+	report, err := h.reports.ProjectReport(c.Request.Context(), userID, role, id)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteProjectReportPDF(&buf, report); err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="project-%s-report.pdf"`, report.Project.ID))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -1,27 +1,31 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
 
-type ErrorResponse struct {
-	Code    string      `json:"code"`
-	Message string      `json:"message"`
-	Details interface{} `json:"details,omitempty"`
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
+)
+
+// statusResponse acknowledges a request that has nothing else to return.
+type statusResponse struct {
+	Status string `json:"status"`
 }
 
 func RespondOK(c *gin.Context, status int, payload interface{}) {
 	c.JSON(status, payload)
 }
 
-func RespondError(
-	c *gin.Context,
-	status int,
-	code string,
-	message string,
-	details interface{},
-) {
-	c.JSON(status, ErrorResponse{
-		Code:    code,
-		Message: message,
-		Details: details,
-	})
+// RespondError writes an application/problem+json error. code is one of the
+// problem.Code constants; detail is shown to the user.
+func RespondError(c *gin.Context, status int, code string, detail string) {
+	problem.Abort(c, status, code, detail)
+}
+
+// respondInternal logs err through the access log and answers with a
+// generic 500, so causes never reach the client.
+func respondInternal(c *gin.Context, err error) {
+	_ = c.Error(err)
+	problem.Abort(c, http.StatusInternalServerError, problem.CodeInternal, "internal error")
 }
@@ -8,6 +8,7 @@ import (
 
 	"task-management-platform/backend/internal/handlers/dto"
 	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/server/middleware"
 	"task-management-platform/backend/internal/services"
 )
 
@@ -24,13 +25,16 @@ func (h *TaskHandler) Create(c *gin.Context) {
 	projectIDStr := c.Param("id")
 	projectID, err := uuid.Parse(projectIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+	if !projectAllowed(c, projectID.String()) {
+		writeServiceError(c, services.ErrForbidden)
 		return
 	}
 
 	var req dto.CreateTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -38,18 +42,36 @@ func (h *TaskHandler) Create(c *gin.Context) {
 	if req.AssigneeID != nil {
 		parsed, err := uuid.Parse(*req.AssigneeID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee_id"})
+			respondInvalidField(c, "assigneeId", "uuid", "must be a UUID")
 			return
 		}
 		assigneeUUID = &parsed
 	}
 
+	dueDate, err := dto.ParseDueDate(req.DueDate)
+	if err != nil {
+		respondInvalidField(c, "dueDate", "date", "must be a date as YYYY-MM-DD")
+		return
+	}
+
+	var parentUUID *uuid.UUID
+	if req.ParentID != nil {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			respondInvalidField(c, "parentId", "uuid", "must be a UUID")
+			return
+		}
+		parentUUID = &parsed
+	}
+
 	task := &models.Task{
 		ProjectID:   projectID,
+		ParentID:    parentUUID,
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      req.Status,
 		AssigneeID:  assigneeUUID,
+		DueDate:     dueDate,
 	}
 
 	if err := h.tasks.Create(c.Request.Context(), actor, task); err != nil {
@@ -65,15 +87,19 @@ func (h *TaskHandler) GetByID(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
 		return
 	}
 
-	task, err := h.tasks.GetByID(c.Request.Context(), actor, id)
+	task, err := h.tasks.GetDetail(c.Request.Context(), actor, id)
 	if err != nil {
 		writeServiceError(c, err)
 		return
 	}
+	if !projectAllowed(c, task.ProjectID.String()) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
 
 	c.JSON(http.StatusOK, task)
 }
@@ -83,19 +109,23 @@ func (h *TaskHandler) ListByProject(c *gin.Context) {
 
 	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+	if !projectAllowed(c, projectID.String()) {
+		writeServiceError(c, services.ErrForbidden)
 		return
 	}
 
 	filters, err := dto.ParseTaskFilters(
 		projectID,
 		c.Query("status"),
-		c.Query("assignee_id"),
+		assigneeQuery(c),
 		c.Query("limit"),
 		c.Query("offset"),
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query params"})
+		writeQueryError(c, err)
 		return
 	}
 
@@ -113,13 +143,16 @@ func (h *TaskHandler) Update(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+
+	if !h.taskAllowed(c, actor, id) {
 		return
 	}
 
 	var req dto.UpdateTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -127,21 +160,28 @@ func (h *TaskHandler) Update(c *gin.Context) {
 	if req.AssigneeID != nil {
 		parsed, err := uuid.Parse(*req.AssigneeID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee_id"})
+			respondInvalidField(c, "assigneeId", "uuid", "must be a UUID")
 			return
 		}
 		assigneeUUID = &parsed
 	}
 
+	dueDate, err := dto.ParseDueDate(req.DueDate.Value)
+	if err != nil {
+		respondInvalidField(c, "dueDate", "date", "must be a date as YYYY-MM-DD")
+		return
+	}
+
 	task := &models.Task{
 		ID:          id,
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      req.Status,
 		AssigneeID:  assigneeUUID,
+		DueDate:     dueDate,
 	}
 
-	if err := h.tasks.Update(c.Request.Context(), actor, task); err != nil {
+	if err := h.tasks.Update(c.Request.Context(), actor, task, !req.DueDate.Set); err != nil {
 		writeServiceError(c, err)
 		return
 	}
@@ -154,7 +194,11 @@ func (h *TaskHandler) Delete(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+
+	if !h.taskAllowed(c, actor, id) {
 		return
 	}
 
@@ -165,3 +209,157 @@ func (h *TaskHandler) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *TaskHandler) Move(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+
+	if !h.taskAllowed(c, actor, id) {
+		return
+	}
+
+	var req dto.MoveTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	var parentUUID *uuid.UUID
+	if req.ParentID != nil {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			respondInvalidField(c, "parentId", "uuid", "must be a UUID")
+			return
+		}
+		parentUUID = &parsed
+	}
+
+	task, err := h.tasks.Move(c.Request.Context(), actor, id, parentUUID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+func (h *TaskHandler) AddChecklistItem(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+
+	if !h.taskAllowed(c, actor, id) {
+		return
+	}
+
+	var req dto.CreateChecklistItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := h.tasks.AddChecklistItem(c.Request.Context(), actor, id, req.Title)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *TaskHandler) UpdateChecklistItem(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	id, itemID, ok := parseChecklistItemPath(c)
+	if !ok {
+		return
+	}
+
+	if !h.taskAllowed(c, actor, id) {
+		return
+	}
+
+	var req dto.UpdateChecklistItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item := &models.ChecklistItem{ID: itemID, TaskID: id, Title: req.Title, Done: req.Done}
+	if err := h.tasks.UpdateChecklistItem(c.Request.Context(), actor, item); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	id, itemID, ok := parseChecklistItemPath(c)
+	if !ok {
+		return
+	}
+
+	if !h.taskAllowed(c, actor, id) {
+		return
+	}
+
+	if err := h.tasks.DeleteChecklistItem(c.Request.Context(), actor, id, itemID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseChecklistItemPath reads the task and item ids of a checklist item
+// route, writing the error response when one is not a UUID.
+func parseChecklistItemPath(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		respondInvalidField(c, "itemId", "uuid", "must be a UUID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, itemID, true
+}
+
+// taskAllowed checks a project-restricted access token against the task's
+// project before a write, writing the error response when it fails.
+func (h *TaskHandler) taskAllowed(c *gin.Context, actor models.User, id uuid.UUID) bool {
+	if _, restricted := middleware.TokenProject(c); !restricted {
+		return true
+	}
+
+	task, err := h.tasks.GetByID(c.Request.Context(), actor, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return false
+	}
+	if !projectAllowed(c, task.ProjectID.String()) {
+		writeServiceError(c, services.ErrForbidden)
+		return false
+	}
+	return true
+}
+
+// assigneeQuery reads the assigneeId filter. assignee_id is its original
+// spelling and still accepted.
+func assigneeQuery(c *gin.Context) string {
+	if v := c.Query("assigneeId"); v != "" {
+		return v
+	}
+	return c.Query("assignee_id")
+}
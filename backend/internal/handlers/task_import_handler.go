@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/services"
+)
+
+// maxTaskImportSize caps a task upload; a few thousand rows fit easily.
+const maxTaskImportSize = 8 << 20
+
+type TaskImportHandler struct {
+	service *services.TaskImportService
+}
+
+func NewTaskImportHandler(service *services.TaskImportService) *TaskImportHandler {
+	return &TaskImportHandler{service: service}
+}
+
+// Import creates tasks in a project from a CSV or JSON upload, sent as the
+// multipart field "file" or as the raw body. The format comes from ?format=,
+// then the content type, then the file extension. ?dryRun=true only
+// validates.
+func (h *TaskImportHandler) Import(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+	if !projectAllowed(c, projectID.String()) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTaskImportSize)
+
+	var (
+		src         io.Reader = c.Request.Body
+		contentType           = c.ContentType()
+		filename    string
+	)
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, "could not read file")
+			return
+		}
+		defer f.Close()
+		src = f
+		contentType, _, _ = mime.ParseMediaType(fh.Header.Get("Content-Type"))
+		filename = fh.Filename
+	}
+
+	format, ok := taskImportFormat(c.Query("format"), contentType, filename)
+	if !ok {
+		RespondError(c, http.StatusUnsupportedMediaType, problem.CodeUnsupportedMedia, "format must be csv or json")
+		return
+	}
+
+	rows, err := services.ParseTaskImport(format, src)
+	if tooLarge(err) {
+		RespondError(c, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, "upload is larger than 8 MB")
+		return
+	}
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, problem.CodeBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.Import(c.Request.Context(), actor, projectID, rows, dryRun)
+	switch {
+	case errors.Is(err, services.ErrInvalidTaskImport):
+		problem.Write(c, problem.New(http.StatusUnprocessableEntity, problem.CodeValidation, "some rows are invalid; nothing was imported").
+			With("report", report))
+	case err != nil:
+		writeServiceError(c, err)
+	case dryRun:
+		c.JSON(http.StatusOK, report)
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+func taskImportFormat(query, contentType, filename string) (services.TaskImportFormat, bool) {
+	switch strings.ToLower(query) {
+	case "csv":
+		return services.TaskImportCSV, true
+	case "json":
+		return services.TaskImportJSON, true
+	case "":
+	default:
+		return "", false
+	}
+
+	switch contentType {
+	case "text/csv", "application/csv":
+		return services.TaskImportCSV, true
+	case "application/json":
+		return services.TaskImportJSON, true
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return services.TaskImportCSV, true
+	case ".json":
+		return services.TaskImportJSON, true
+	}
+	return "", false
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/server/middleware"
+	"task-management-platform/backend/internal/services"
+)
+
+type TrashHandler struct {
+	service *services.TrashService
+}
+
+func NewTrashHandler(service *services.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+func (h *TrashHandler) List(c *gin.Context) {
+	trash, err := h.service.List(c.Request.Context(), mustGetActor(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	if restricted, ok := middleware.TokenProject(c); ok {
+		trash.Projects = slices.DeleteFunc(trash.Projects, func(p models.Project) bool {
+			return p.ID != restricted
+		})
+		trash.Tasks = slices.DeleteFunc(trash.Tasks, func(t models.Task) bool {
+			return t.ProjectID.String() != restricted
+		})
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+func (h *TrashHandler) RestoreProject(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+	if !projectAllowed(c, id) {
+		writeServiceError(c, services.ErrForbidden)
+		return
+	}
+
+	p, err := h.service.RestoreProject(c.Request.Context(), mustGetActor(c), id)
+	if err != nil {
+		writeTrashError(c, err, "project not in the trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *TrashHandler) RestoreTask(c *gin.Context) {
+	actor := mustGetActor(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidField(c, "id", "uuid", "must be a UUID")
+		return
+	}
+
+	// a project-restricted token is checked against the task's project
+	// before anything changes
+	if _, restricted := middleware.TokenProject(c); restricted {
+		task, err := h.service.GetTask(c.Request.Context(), actor, id)
+		if err != nil {
+			writeTrashError(c, err, "task not in the trash")
+			return
+		}
+		if !projectAllowed(c, task.ProjectID.String()) {
+			writeServiceError(c, services.ErrForbidden)
+			return
+		}
+	}
+
+	task, err := h.service.RestoreTask(c.Request.Context(), actor, id)
+	if err != nil {
+		writeTrashError(c, err, "task not in the trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// writeTrashError says what was not found: a live item is not in the trash.
+func writeTrashError(c *gin.Context, err error, notFound string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, notFound)
+		return
+	}
+	writeServiceError(c, err)
+}
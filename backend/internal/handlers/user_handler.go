@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"task-management-platform/backend/internal/handlers/dto"
 	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
 	"task-management-platform/backend/internal/repository"
 	"task-management-platform/backend/internal/services"
 )
@@ -25,8 +27,7 @@ func NewUserHandler(service *services.UserService) *UserHandler {
 func (h *UserHandler) List(c *gin.Context) {
 	users, err := h.service.List(c.Request.Context())
 	if err != nil {
-		RespondError(c, http.StatusInternalServerError, CodeInternal, "internal error", nil)
-
+		respondInternal(c, err)
 		return
 	}
 
@@ -43,11 +44,7 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 
 	user, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
-		if err == repository.ErrNotFound {
-			RespondError(c, http.StatusNotFound, CodeNotFound, "user not found", gin.H{"id": id})
-			return
-		}
-		RespondError(c, http.StatusInternalServerError, CodeInternal, "internal error", nil)
+		writeUserError(c, err)
 		return
 	}
 
@@ -55,32 +52,15 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 }
 
 func (h *UserHandler) Create(c *gin.Context) {
-	var req struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=4"`
-		Role     string `json:"role" binding:"required"`
-	}
+	var req dto.CreateUserRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		RespondError(
-			c,
-			http.StatusBadRequest,
-			CodeBadRequest,
-			"invalid payload",
-			gin.H{"error": err.Error()},
-		)
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		RespondError(
-			c,
-			http.StatusInternalServerError,
-			CodeInternal,
-			"password hashing failed",
-			nil,
-		)
+		respondInternal(c, err)
 		return
 	}
 
@@ -95,13 +75,24 @@ func (h *UserHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.service.Create(c.Request.Context(), user); err != nil {
-		RespondError(
-			c,
-			http.StatusInternalServerError,
-			CodeInternal,
-			"could not create user",
-			nil,
-		)
+		writeUserError(c, err)
+		return
+	}
+
+	RespondOK(c, http.StatusCreated, dto.FromUser(*user))
+}
+
+// CreateServiceAccount adds a non-human user that authenticates only with
+// personal access tokens.
+func (h *UserHandler) CreateServiceAccount(c *gin.Context) {
+	var req dto.CreateServiceAccountRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.CreateServiceAccount(c.Request.Context(), req.Email, req.Role)
+	if err != nil {
+		writeUserError(c, err)
 		return
 	}
 
@@ -111,13 +102,9 @@ func (h *UserHandler) Create(c *gin.Context) {
 func (h *UserHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	actorID := c.GetString("userId")
-	var req struct {
-		Email string `json:"email" binding:"required,email"`
-		Role  string `json:"role" binding:"required"`
-	}
+	var req dto.UpdateUserRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		RespondError(c, http.StatusBadRequest, CodeBadRequest, "invalid payload", gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -128,38 +115,34 @@ func (h *UserHandler) Update(c *gin.Context) {
 	}
 
 	if err := h.service.Update(c.Request.Context(), actorID, user); err != nil {
-		switch err {
-		case repository.ErrNotFound:
-			RespondError(c, http.StatusNotFound, CodeNotFound, "user not found", gin.H{"id": id})
-			return
-		case services.ErrCannotUpdateOwnRole:
-			RespondError(c, http.StatusForbidden, CodeForbidden, "cannot update own role", nil)
-			return
-		default:
-			RespondError(c, http.StatusInternalServerError, CodeInternal, "could not update user", nil)
-			return
-		}
+		writeUserError(c, err)
+		return
 	}
 
-	RespondOK(c, http.StatusOK, gin.H{"status": "updated"})
+	RespondOK(c, http.StatusOK, statusResponse{Status: "updated"})
 }
 
 func (h *UserHandler) Delete(c *gin.Context) {
 	targetID := c.Param("id")
 	actorID := c.GetString("userId")
 
-	if err := h.service.Delete(c.Request.Context(), actorID, targetID); err != nil {
-		if err == repository.ErrNotFound {
-			RespondError(c, http.StatusNotFound, CodeNotFound, "user not found", gin.H{"id": targetID})
-			return
-		}
-		if err.Error() == "cannot delete own user" {
-			RespondError(c, http.StatusBadRequest, CodeBusinessRule, err.Error(), gin.H{"id": targetID})
-			return
-		}
-		RespondError(c, http.StatusInternalServerError, CodeInternal, "could not delete user", nil)
+	// the projects of the deleted user go to this user
+	transferTo := c.Query("transferProjectsTo")
+
+	if err := h.service.Delete(c.Request.Context(), actorID, targetID, transferTo); err != nil {
+		writeUserError(c, err)
 		return
 	}
 
-	RespondOK(c, http.StatusOK, gin.H{"status": "deleted"})
+	RespondOK(c, http.StatusOK, statusResponse{Status: "deleted"})
+}
+
+// writeUserError names the missing resource instead of the generic "not
+// found".
+func writeUserError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		RespondError(c, http.StatusNotFound, problem.CodeNotFound, "user not found")
+		return
+	}
+	writeServiceError(c, err)
 }
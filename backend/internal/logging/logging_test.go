@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_AddsContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := With(context.Background(), "request_id", "abc")
+	ctx = With(ctx, slog.String("user_id", "u1"))
+	logger.InfoContext(ctx, "hello", "n", 1)
+	logger.DebugContext(ctx, "dropped")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["msg"] != "hello" || line["request_id"] != "abc" || line["user_id"] != "u1" || line["n"] != float64(1) {
+		t.Fatalf("unexpected record: %v", line)
+	}
+}
+
+func TestWith_DoesNotLeakIntoParent(t *testing.T) {
+	parent := With(context.Background(), "a", 1)
+	_ = With(parent, "b", 2)
+
+	if got := Attrs(parent); len(got) != 1 || got[0].Key != "a" {
+		t.Fatalf("expected parent attrs untouched, got %v", got)
+	}
+}
+
+func TestNew_RejectsUnknownFormat(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", slog.LevelInfo); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
+
+func TestNew_AddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.InfoContext(ctx, "hello")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["trace_id"] != traceID.String() || line["span_id"] != spanID.String() {
+		t.Fatalf("expected trace ids, got %v", line)
+	}
+}
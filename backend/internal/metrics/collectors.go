@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"task-management-platform/backend/internal/server/middleware"
+)
+
+// RateLimiter wraps rl so requests it rejects are counted under policy.
+// Limiter errors are not rejections: RateLimitBy fails open on them.
+func (m *Metrics) RateLimiter(policy string, rl middleware.RateLimiter) middleware.RateLimiter {
+	return &countingLimiter{next: rl, rejected: m.rateLimited.WithLabelValues(policy)}
+}
+
+type countingLimiter struct {
+	next     middleware.RateLimiter
+	rejected prometheus.Counter
+}
+
+func (l *countingLimiter) Allow(ctx context.Context, key string) (middleware.RateDecision, error) {
+	d, err := l.next.Allow(ctx, key)
+	if err == nil && !d.Allowed {
+		l.rejected.Inc()
+	}
+	return d, err
+}
+
+// TaskCounter is implemented by repository.StatsRepository.
+type TaskCounter interface {
+	TaskCountsByStatus(ctx context.Context) (map[string]int, error)
+}
+
+// taskStatuses are always exported, so a status without tasks reads 0
+// instead of disappearing.
+var taskStatuses = []string{"todo", "in_progress", "done"}
+
+// taskScrapeTimeout keeps a slow database from stalling the whole scrape.
+const taskScrapeTimeout = 5 * time.Second
+
+// taskCountTTL is how long one count serves scrapes, so frequent or
+// parallel scrapers do not each run a full table count.
+const taskCountTTL = 30 * time.Second
+
+type taskCollector struct {
+	counter TaskCounter
+	desc    *prometheus.Desc
+	now     func() time.Time
+
+	mu        sync.Mutex
+	counts    map[string]int
+	countedAt time.Time
+}
+
+func newTaskCollector(counter TaskCounter) *taskCollector {
+	return &taskCollector{
+		counter: counter,
+		now:     time.Now,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "tasks", "by_status"),
+			"Number of tasks in each status.",
+			[]string{"status"}, nil,
+		),
+	}
+}
+
+func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.taskCounts()
+	if err != nil {
+		slog.Warn("metrics: count tasks by status", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for _, status := range taskStatuses {
+		if _, ok := counts[status]; !ok {
+			counts[status] = 0
+		}
+	}
+	for status, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), status)
+	}
+}
+
+// taskCounts returns a copy of the last count while it is fresh and queries
+// the database otherwise. Failures are not cached.
+func (c *taskCollector) taskCounts() (map[string]int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts != nil && c.now().Sub(c.countedAt) < taskCountTTL {
+		return maps.Clone(c.counts), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), taskScrapeTimeout)
+	defer cancel()
+
+	counts, err := c.counter.TaskCountsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		counts = map[string]int{}
+	}
+	c.counts, c.countedAt = counts, c.now()
+	return maps.Clone(counts), nil
+}
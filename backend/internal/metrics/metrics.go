@@ -0,0 +1,130 @@
+// Package metrics exposes the API's Prometheus metrics: request latency per
+// route, rate-limit rejections, database pool usage, login outcomes and task
+// counts.
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"task-management-platform/backend/internal/problem"
+	"task-management-platform/backend/internal/services"
+)
+
+const namespace = "taskmgr"
+
+// unmatchedRoute labels requests that matched no route, so probing random
+// paths cannot grow the number of series.
+const unmatchedRoute = "unmatched"
+
+// Metrics owns a registry of its own rather than the global default one, so
+// every series it serves is one the API defines.
+type Metrics struct {
+	registry    *prometheus.Registry
+	requests    *prometheus.HistogramVec
+	rateLimited *prometheus.CounterVec
+	logins      *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected by a rate limit policy.",
+		}, []string{"policy"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "logins_total",
+			Help:      "Login attempts by result and, for failures, reason.",
+		}, []string{"result", "reason"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.rateLimited,
+		m.logins,
+	)
+	return m
+}
+
+// Middleware times every request. Register it before the recovery
+// middleware and the rate limiter so panics and rejected requests are
+// measured too.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.requests.
+			WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveLogin implements services.LoginObserver.
+func (m *Metrics) ObserveLogin(outcome string) {
+	if outcome == services.LoginSucceeded {
+		m.logins.WithLabelValues("success", "").Inc()
+		return
+	}
+	m.logins.WithLabelValues("failure", outcome).Inc()
+}
+
+// WatchDB exports the connection pool statistics of db: open, in-use and
+// idle connections and how often callers had to wait for one.
+func (m *Metrics) WatchDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// WatchTasks exports the number of tasks per status. Counts are cached for
+// taskCountTTL between scrapes.
+func (m *Metrics) WatchTasks(counter TaskCounter) {
+	m.registry.MustRegister(newTaskCollector(counter))
+}
+
+// Handler serves the metrics in the Prometheus text format. Scrapers must
+// send token as a bearer token; an empty token rejects every request.
+func (m *Metrics) Handler(token string) gin.HandlerFunc {
+	h := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		// a failing collector drops its own series, not the whole scrape
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+	return func(c *gin.Context) {
+		if token == "" || !validBearer(c.GetHeader("Authorization"), token) {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid token")
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+func validBearer(header, token string) bool {
+	got, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
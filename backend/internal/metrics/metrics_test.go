@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"task-management-platform/backend/internal/server/middleware"
+	"task-management-platform/backend/internal/services"
+)
+
+type fakeTaskCounter struct {
+	counts map[string]int
+	err    error
+	calls  *int
+}
+
+func (f fakeTaskCounter) TaskCountsByStatus(context.Context) (map[string]int, error) {
+	if f.calls != nil {
+		*f.calls++
+	}
+	return f.counts, f.err
+}
+
+func scrape(t *testing.T, r *gin.Engine, token string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/projects/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/projects/a", "/projects/b", "/nope/1", "/nope/2"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if n := testutil.CollectAndCount(m.requests); n != 2 {
+		t.Fatalf("expected 2 series, got %d", n)
+	}
+	r.GET("/metrics", m.Handler("s3cret"))
+	_, body := scrape(t, r, "s3cret")
+	for _, want := range []string{
+		`taskmgr_http_request_duration_seconds_count{method="GET",route="/projects/:id",status="200"} 2`,
+		`taskmgr_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in scrape", want)
+		}
+	}
+}
+
+func TestHandler_RequiresToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	r := gin.New()
+	r.GET("/metrics", m.Handler("s3cret"))
+
+	if code, _ := scrape(t, r, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", code)
+	}
+	if code, _ := scrape(t, r, "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with a wrong token, got %d", code)
+	}
+	code, body := scrape(t, r, "s3cret")
+	if code != http.StatusOK || !strings.Contains(body, "go_goroutines") {
+		t.Fatalf("expected metrics with the token, got %d", code)
+	}
+}
+
+func TestHandler_EmptyTokenRejectsEverything(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	r := gin.New()
+	r.GET("/metrics", m.Handler(""))
+
+	if code, _ := scrape(t, r, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a configured token, got %d", code)
+	}
+}
+
+func TestRateLimiter_CountsRejections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	r := gin.New()
+	r.Use(middleware.RateLimitBy(m.RateLimiter("auth", middleware.NewRateLimiter(1, time.Minute)), middleware.KeyByIP))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 0; i < 3; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	if got := testutil.ToFloat64(m.rateLimited.WithLabelValues("auth")); got != 2 {
+		t.Fatalf("expected 2 rejections, got %v", got)
+	}
+}
+
+func TestObserveLogin(t *testing.T) {
+	m := New()
+	m.ObserveLogin(services.LoginSucceeded)
+	m.ObserveLogin(services.LoginInvalidCredentials)
+	m.ObserveLogin(services.LoginInvalidCredentials)
+
+	if got := testutil.ToFloat64(m.logins.WithLabelValues("success", "")); got != 1 {
+		t.Fatalf("expected 1 success, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.logins.WithLabelValues("failure", services.LoginInvalidCredentials)); got != 2 {
+		t.Fatalf("expected 2 failures, got %v", got)
+	}
+}
+
+func TestWatchTasks_FillsMissingStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	m.WatchTasks(fakeTaskCounter{counts: map[string]int{"todo": 3}})
+
+	r := gin.New()
+	r.GET("/metrics", m.Handler("s3cret"))
+
+	_, body := scrape(t, r, "s3cret")
+	for _, want := range []string{
+		`taskmgr_tasks_by_status{status="todo"} 3`,
+		`taskmgr_tasks_by_status{status="done"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in scrape", want)
+		}
+	}
+}
+
+func TestWatchTasks_ErrorKeepsOtherMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	m.WatchTasks(fakeTaskCounter{err: errors.New("db down")})
+
+	r := gin.New()
+	r.GET("/metrics", m.Handler("s3cret"))
+
+	code, body := scrape(t, r, "s3cret")
+	if code != http.StatusOK || !strings.Contains(body, "go_goroutines") {
+		t.Fatalf("expected a partial scrape, got %d", code)
+	}
+	if strings.Contains(body, "taskmgr_tasks_by_status") {
+		t.Fatalf("expected task counts to be left out")
+	}
+}
+
+func TestWatchTasks_CachesCounts(t *testing.T) {
+	calls := 0
+	c := newTaskCollector(fakeTaskCounter{counts: map[string]int{"todo": 1}, calls: &calls})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if n := testutil.CollectAndCount(c); n != len(taskStatuses) {
+			t.Fatalf("expected %d series, got %d", len(taskStatuses), n)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 query within the TTL, got %d", calls)
+	}
+
+	now = now.Add(taskCountTTL)
+	testutil.CollectAndCount(c)
+	if calls != 2 {
+		t.Fatalf("expected a new query after the TTL, got %d", calls)
+	}
+}
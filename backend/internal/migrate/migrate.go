@@ -0,0 +1,237 @@
+// Package migrate applies the embedded schema migrations and checks that a
+// database is at the version the code expects.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"task-management-platform/backend/migrations"
+)
+
+// ErrSchemaMismatch is returned by Check when the database is not at the
+// latest embedded version.
+var ErrSchemaMismatch = errors.New("database schema version mismatch")
+
+// Status describes a database against the embedded migrations. Current is 0
+// when no migration has been applied.
+type Status struct {
+	Current  uint
+	Dirty    bool
+	Expected uint
+	Pending  []uint
+}
+
+// Versions lists the embedded migration versions in ascending order.
+func Versions() ([]uint, error) {
+	return versions(migrations.FS)
+}
+
+func versions(fsys fs.FS) ([]uint, error) {
+	names, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]uint, 0, len(names))
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %q: missing version prefix", name)
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %q: %w", name, err)
+		}
+		out = append(out, uint(v))
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// Expected is the schema version this build requires: the newest embedded
+// migration.
+func Expected() (uint, error) {
+	vs, err := Versions()
+	if err != nil {
+		return 0, err
+	}
+	if len(vs) == 0 {
+		return 0, nil
+	}
+	return vs[len(vs)-1], nil
+}
+
+// Inspect reads the version golang-migrate recorded in schema_migrations. It
+// only reads, so it is safe to call on the server's connection pool.
+func Inspect(ctx context.Context, db *sql.DB) (Status, error) {
+	vs, err := Versions()
+	if err != nil {
+		return Status{}, err
+	}
+
+	var st Status
+	if len(vs) > 0 {
+		st.Expected = vs[len(vs)-1]
+	}
+
+	var table sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations')::text`).Scan(&table); err != nil {
+		return Status{}, err
+	}
+	if table.Valid {
+		var version int64
+		err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &st.Dirty)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return Status{}, err
+		}
+		if version > 0 {
+			st.Current = uint(version)
+		}
+	}
+
+	for _, v := range vs {
+		if v > st.Current {
+			st.Pending = append(st.Pending, v)
+		}
+	}
+	return st, nil
+}
+
+// Check fails unless the database is clean and at Expected.
+func Check(ctx context.Context, db *sql.DB) error {
+	st, err := Inspect(ctx, db)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if st.Dirty {
+		return fmt.Errorf("%w: version %d is dirty after a failed migration; repair it and run `migrate force %d`",
+			ErrSchemaMismatch, st.Current, st.Current)
+	}
+	if st.Current != st.Expected {
+		return fmt.Errorf("%w: database is at version %d, code expects %d; run `migrate up`",
+			ErrSchemaMismatch, st.Current, st.Expected)
+	}
+	return nil
+}
+
+// Run executes a migrate subcommand: up, down [N], status or force VERSION.
+// The migrator closes db when it is done.
+func Run(ctx context.Context, db *sql.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [N]|status|force VERSION")
+	}
+
+	if args[0] == "status" {
+		st, err := Inspect(ctx, db)
+		if err != nil {
+			return err
+		}
+		printStatus(out, st)
+		return nil
+	}
+
+	m, err := newMigrator(db, out)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		err = m.Steps(-steps)
+	case "force":
+		if len(args) < 2 {
+			return errors.New("usage: migrate force VERSION")
+		}
+		var version int
+		if version, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		err = m.Force(version)
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Fprintln(out, "no change")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Fprintln(out, "schema version: none")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "schema version: %d (dirty: %t)\n", version, dirty)
+	return nil
+}
+
+func newMigrator(db *sql.DB, out io.Writer) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = logger{out}
+	return m, nil
+}
+
+func printStatus(out io.Writer, st Status) {
+	fmt.Fprintf(out, "current:  %d\n", st.Current)
+	fmt.Fprintf(out, "expected: %d\n", st.Expected)
+	fmt.Fprintf(out, "dirty:    %t\n", st.Dirty)
+	if len(st.Pending) == 0 {
+		fmt.Fprintln(out, "pending:  none")
+		return
+	}
+	pending := make([]string, len(st.Pending))
+	for i, v := range st.Pending {
+		pending[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	fmt.Fprintf(out, "pending:  %s\n", strings.Join(pending, ", "))
+}
+
+// logger reports each applied migration.
+type logger struct {
+	out io.Writer
+}
+
+func (l logger) Printf(format string, v ...any) {
+	fmt.Fprintf(l.out, format, v...)
+}
+
+func (l logger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,68 @@
+package migrate
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"task-management-platform/backend/migrations"
+)
+
+func TestVersions_SortsAndRejectsBadNames(t *testing.T) {
+	got, err := versions(fstest.MapFS{
+		"000010_later.up.sql":    {},
+		"000002_second.up.sql":   {},
+		"000002_second.down.sql": {},
+		"README.md":              {},
+	})
+	if err != nil {
+		t.Fatalf("versions() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != 2 || got[1] != 10 {
+		t.Fatalf("versions() = %v, want [2 10]", got)
+	}
+
+	if _, err := versions(fstest.MapFS{"init.up.sql": {}}); err == nil {
+		t.Fatalf("versions() accepted a file without a version prefix")
+	}
+}
+
+func TestEmbeddedMigrations_AreWellFormed(t *testing.T) {
+	files, err := fs.Glob(migrations.FS, "*")
+	if err != nil {
+		t.Fatalf("Glob error = %v", err)
+	}
+
+	ups := map[string]bool{}
+	downs := map[string]bool{}
+	for _, name := range files {
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			ups[strings.TrimSuffix(name, ".up.sql")] = true
+		case strings.HasSuffix(name, ".down.sql"):
+			downs[strings.TrimSuffix(name, ".down.sql")] = true
+		default:
+			t.Fatalf("unexpected file %q in migrations", name)
+		}
+	}
+
+	for base := range ups {
+		if !downs[base] {
+			t.Fatalf("migration %s has no down file", base)
+		}
+	}
+	for base := range downs {
+		if !ups[base] {
+			t.Fatalf("migration %s has no up file", base)
+		}
+	}
+
+	expected, err := Expected()
+	if err != nil {
+		t.Fatalf("Expected() error = %v", err)
+	}
+	if expected == 0 {
+		t.Fatalf("Expected() = 0, want the newest migration version")
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AccessTokenPrefix starts every personal access token, which lets
+// AuthRequired tell them apart from JWTs and secret scanners find leaked ones.
+const AccessTokenPrefix = "tmp_"
+
+// Scopes a personal access token can carry. A write scope implies the
+// matching read scope.
+const (
+	ScopeTasksRead     = "tasks:read"
+	ScopeTasksWrite    = "tasks:write"
+	ScopeProjectsRead  = "projects:read"
+	ScopeProjectsWrite = "projects:write"
+	ScopeUsersRead     = "users:read"
+	// ScopeAdmin reaches admin-only routes when the owner is an admin.
+	ScopeAdmin = "admin"
+)
+
+var AccessTokenScopes = []string{
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+	ScopeUsersRead,
+	ScopeAdmin,
+}
+
+type AccessToken struct {
+	ID     string `db:"id" json:"id"`
+	UserID string `db:"user_id" json:"userId"`
+	Name   string `db:"name" json:"name"`
+	// Prefix is the start of the token, kept so users can tell tokens apart.
+	Prefix    string         `db:"prefix" json:"prefix"`
+	TokenHash string         `db:"token_hash" json:"-"`
+	Scopes    pq.StringArray `db:"scopes" json:"scopes"`
+	// ProjectID restricts the token to one project's tasks when set.
+	ProjectID  *string    `db:"project_id" json:"projectId,omitempty"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expiresAt"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// ScopesAllow reports whether scopes grant want.
+func ScopesAllow(scopes []string, want string) bool {
+	if slices.Contains(scopes, want) {
+		return true
+	}
+	if resource, ok := strings.CutSuffix(want, ":read"); ok {
+		return slices.Contains(scopes, resource+":write")
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CalendarFeedPrefix starts every calendar feed token so it is never
+// mistaken for an access token.
+const CalendarFeedPrefix = "cal_"
+
+// CalendarFeed is a secret URL publishing a user's tasks as iCalendar. The
+// token only grants read access to that one feed.
+type CalendarFeed struct {
+	ID        string `db:"id" json:"id"`
+	UserID    string `db:"user_id" json:"userId"`
+	Name      string `db:"name" json:"name"`
+	Prefix    string `db:"prefix" json:"prefix"`
+	TokenHash string `db:"token_hash" json:"-"`
+	// ProjectIDs adds every dated task of these projects to the feed, on top
+	// of the tasks assigned to the user.
+	ProjectIDs pq.StringArray `db:"project_ids" json:"projectIds"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"lastUsedAt"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revokedAt"`
+	CreatedAt  time.Time      `db:"created_at" json:"createdAt"`
+}
+
+// CalendarTask is a task with the project name calendar entries show.
+type CalendarTask struct {
+	Task
+	ProjectName string `db:"project_name"`
+}
@@ -7,4 +7,8 @@ type Project struct {
 	Name      string    `db:"name" json:"name"`
 	OwnerID   string    `db:"owner_id" json:"ownerId"`
 	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	// ArchivedAt is set while the project is archived, and read-only.
+	ArchivedAt *time.Time `db:"archived_at" json:"archivedAt,omitempty"`
+	// DeletedAt is set while the project is in the trash.
+	DeletedAt *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
 }
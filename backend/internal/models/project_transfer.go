@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ProjectTransfer is an offer to hand a project to another user. It takes
+// effect when that user accepts it; a project has at most one pending.
+type ProjectTransfer struct {
+	ProjectID   string    `db:"project_id" json:"projectId"`
+	ProjectName string    `db:"project_name" json:"projectName"`
+	FromUserID  string    `db:"from_user_id" json:"fromUserId"`
+	ToUserID    string    `db:"to_user_id" json:"toUserId"`
+	RequestedBy *string   `db:"requested_by" json:"requestedBy"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+}
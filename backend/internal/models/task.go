@@ -13,6 +13,40 @@ type Task struct {
 	Description string     `json:"description" db:"description"`
 	Status      string     `json:"status" db:"status"`
 	AssigneeID  *uuid.UUID `json:"assigneeId" db:"assignee_id"`
-	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+	// ParentID is set on subtasks; the parent is in the same project.
+	ParentID *uuid.UUID `json:"parentId" db:"parent_id"`
+	// DueDate is a calendar day; only its date part is meaningful.
+	DueDate   *time.Time `json:"dueDate" db:"due_date"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+// TaskDetail is a task with its direct subtasks and checklist, as shown on
+// its own page.
+type TaskDetail struct {
+	Task
+	Subtasks          []Task          `json:"subtasks"`
+	SubtaskProgress   Progress        `json:"subtaskProgress"`
+	Checklist         []ChecklistItem `json:"checklist"`
+	ChecklistProgress Progress        `json:"checklistProgress"`
+}
+
+// Progress counts how many of Total items are done.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ChecklistItem is a step inside a task that can be ticked off without being
+// a task of its own.
+type ChecklistItem struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	TaskID    uuid.UUID `json:"taskId" db:"task_id"`
+	Title     string    `json:"title" db:"title"`
+	Done      bool      `json:"done" db:"done"`
+	Position  int       `json:"position" db:"position"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -2,10 +2,24 @@ package models
 
 import "time"
 
+// User kinds. Service accounts belong to automation: they cannot log in with
+// a password and only authenticate with personal access tokens.
+const (
+	UserKindHuman   = "human"
+	UserKindService = "service"
+)
+
 type User struct {
 	ID           string    `db:"id" json:"id"`
 	Email        string    `db:"email" json:"email"`
 	PasswordHash string    `db:"password_hash" json:"passwordHash"`
 	Role         string    `db:"role" json:"role"`
+	Kind         string    `db:"kind" json:"kind"`
 	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+
+	// TOTPSecret is set once enrollment starts; TOTPEnabled only after the
+	// user confirmed a code generated from it.
+	TOTPSecret   *string `db:"totp_secret" json:"-"`
+	TOTPEnabled  bool    `db:"totp_enabled" json:"totpEnabled"`
+	TOTPLastStep int64   `db:"totp_last_step" json:"-"`
 }
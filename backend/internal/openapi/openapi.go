@@ -0,0 +1,368 @@
+// Package openapi builds the OpenAPI 3.1 description of the API from the Go
+// types handlers read and write, serves it, and validates traffic against
+// it.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files/v2"
+
+	"task-management-platform/backend/internal/problem"
+)
+
+// Version is the OpenAPI version documents are written in.
+const Version = "3.1.0"
+
+// Media types used by the builder.
+const (
+	MediaJSON      = "application/json"
+	MediaProblem   = "application/problem+json"
+	MediaMultipart = "multipart/form-data"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	schemas *registry
+	// ops indexes operations by method and gin route, as in "GET /tasks/:id".
+	ops map[string]*Operation
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+
+	doc    *Document
+	method string
+	path   string
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// BearerAuth is the security scheme of authenticated operations.
+const BearerAuth = "bearerAuth"
+
+// New starts an empty document. problemType is the Go type of error bodies;
+// every operation documents its failures with it.
+func New(info Info, problemType any) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				BearerAuth: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "A JWT from /auth/login or a personal access token.",
+				},
+			},
+		},
+		ops: map[string]*Operation{},
+	}
+	d.schemas = newRegistry(d.Components.Schemas)
+	d.schemas.problem = d.schemas.of(reflect.TypeOf(problemType), false)
+	return d
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Op adds the operation serving method on the gin route path, with its path
+// parameters declared as strings. Every operation may fail with 429 and 500.
+func (d *Document) Op(method, path, id, summary string) *Operation {
+	o := &Operation{
+		OperationID: id,
+		Summary:     summary,
+		Responses:   map[string]*Response{},
+		doc:         d,
+		method:      method,
+		path:        path,
+	}
+	for _, m := range ginParam.FindAllStringSubmatch(path, -1) {
+		o.Parameters = append(o.Parameters, &Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	o.Fails(http.StatusTooManyRequests, http.StatusInternalServerError)
+
+	key := ginParam.ReplaceAllString(path, "{$1}")
+	item, ok := d.Paths[key]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[key] = item
+	}
+	(*item)[strings.ToLower(method)] = o
+	d.ops[method+" "+path] = o
+	return o
+}
+
+// Operation returns the operation registered for method and gin route path.
+func (d *Document) Operation(method, path string) (*Operation, bool) {
+	o, ok := d.ops[method+" "+path]
+	return o, ok
+}
+
+// Routes lists the operations as "METHOD /gin/:route", sorted.
+func (d *Document) Routes() []string {
+	out := make([]string, 0, len(d.ops))
+	for k := range d.ops {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Tag groups the operation in the docs UI.
+func (o *Operation) Tag(tags ...string) *Operation {
+	o.Tags = append(o.Tags, tags...)
+	return o
+}
+
+// Describe sets the long description, which may use Markdown.
+func (o *Operation) Describe(text string) *Operation {
+	o.Description = text
+	return o
+}
+
+// Secured marks the operation as needing a bearer token, which also means
+// it may answer 401 and 403.
+func (o *Operation) Secured() *Operation {
+	o.Security = []map[string][]string{{BearerAuth: {}}}
+	return o.Fails(http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// PathParam narrows a path parameter, e.g. to a UUID; values outside it
+// fail with 400.
+func (o *Operation) PathParam(name string, schema *Schema) *Operation {
+	for _, p := range o.Parameters {
+		if p.In == "path" && p.Name == name {
+			p.Schema = schema
+			return o.Fails(http.StatusBadRequest)
+		}
+	}
+	panic("openapi: " + o.path + " has no path parameter " + name)
+}
+
+// Query documents an optional query parameter; values outside schema fail
+// with 400.
+func (o *Operation) Query(name string, schema *Schema, description string) *Operation {
+	o.Parameters = append(o.Parameters, &Parameter{Name: name, In: "query", Description: description, Schema: schema})
+	return o.Fails(http.StatusBadRequest)
+}
+
+// Body documents a required JSON body of v's type, with the constraints of
+// its binding tags. A 400 failure is implied.
+func (o *Operation) Body(v any) *Operation {
+	o.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{MediaJSON: {Schema: o.doc.schemas.of(reflect.TypeOf(v), true)}},
+	}
+	return o.Fails(http.StatusBadRequest)
+}
+
+// OptionalBody is Body for operations that also accept an empty body.
+func (o *Operation) OptionalBody(v any) *Operation {
+	o.Body(v)
+	o.RequestBody.Required = false
+	return o
+}
+
+// Upload documents a body in any of the given media types. JSON types are
+// described by schema; multipart bodies carry the file in the field "file".
+func (o *Operation) Upload(schema *Schema, mediaTypes ...string) *Operation {
+	content := map[string]*MediaType{}
+	for _, mt := range mediaTypes {
+		switch mt {
+		case MediaJSON:
+			content[mt] = &MediaType{Schema: schema}
+		case MediaMultipart:
+			content[mt] = &MediaType{Schema: &Schema{
+				Type:       "object",
+				Required:   []string{"file"},
+				Properties: map[string]*Schema{"file": Binary()},
+			}}
+		default:
+			content[mt] = &MediaType{Schema: Binary()}
+		}
+	}
+	o.RequestBody = &RequestBody{Required: true, Content: content}
+	return o.Fails(http.StatusBadRequest)
+}
+
+// Returns documents a JSON response of v's type; a nil v documents an empty
+// response.
+func (o *Operation) Returns(status int, v any, description string) *Operation {
+	r := &Response{Description: description}
+	if v != nil {
+		r.Content = map[string]*MediaType{MediaJSON: {Schema: o.doc.schemas.of(reflect.TypeOf(v), false)}}
+	}
+	o.Responses[strconv.Itoa(status)] = r
+	return o
+}
+
+// ReturnsSchema documents a JSON response described by hand, for payloads
+// without a Go type of their own.
+func (o *Operation) ReturnsSchema(status int, schema *Schema, description string) *Operation {
+	o.Responses[strconv.Itoa(status)] = &Response{
+		Description: description,
+		Content:     map[string]*MediaType{MediaJSON: {Schema: schema}},
+	}
+	return o
+}
+
+// ReturnsFile documents a binary response of the given media type.
+func (o *Operation) ReturnsFile(status int, mediaType, description string) *Operation {
+	o.Responses[strconv.Itoa(status)] = &Response{
+		Description: description,
+		Content:     map[string]*MediaType{mediaType: {Schema: Binary()}},
+	}
+	return o
+}
+
+// Redirects documents a 302 response.
+func (o *Operation) Redirects(description string) *Operation {
+	o.Responses[strconv.Itoa(http.StatusFound)] = &Response{Description: description}
+	return o
+}
+
+// Fails documents problem responses for the given statuses.
+func (o *Operation) Fails(statuses ...int) *Operation {
+	for _, status := range statuses {
+		o.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*MediaType{MediaProblem: {Schema: o.doc.schemas.problem}},
+		}
+	}
+	return o
+}
+
+// FailsWith documents a problem response that carries v in the extension
+// member, such as the report explaining why an upload was rejected.
+func (o *Operation) FailsWith(status int, member string, v any, description string) *Operation {
+	schema := &Schema{AllOf: []*Schema{
+		o.doc.schemas.problem,
+		{
+			Type:       "object",
+			Required:   []string{member},
+			Properties: map[string]*Schema{member: o.doc.schemas.of(reflect.TypeOf(v), false)},
+		},
+	}}
+	o.Responses[strconv.Itoa(status)] = &Response{
+		Description: description,
+		Content:     map[string]*MediaType{MediaProblem: {Schema: schema}},
+	}
+	return o
+}
+
+// Handler serves the document as JSON.
+func (d *Document) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+// UI serves an HTML page rendering the document at specURL with Swagger UI.
+// The page loads its scripts and styles from assetsURL, where UIAssets is
+// mounted, so the docs work offline and nothing is pulled from a CDN.
+func UI(title, specURL, assetsURL string) gin.HandlerFunc {
+	page := strings.NewReplacer(
+		"{{title}}", title,
+		"{{spec}}", strconv.Quote(specURL),
+		"{{assets}}", strings.TrimSuffix(assetsURL, "/"),
+	).Replace(uiPage)
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	}
+}
+
+// uiAssets are the Swagger UI files the docs page needs.
+var uiAssets = map[string]bool{"/swagger-ui.css": true, "/swagger-ui-bundle.js": true}
+
+// UIAssets serves the bundled Swagger UI files. Mount it on a route whose
+// last segment is the wildcard *file.
+func UIAssets() gin.HandlerFunc {
+	files := http.FS(swaggerfiles.FS)
+	return func(c *gin.Context) {
+		file := c.Param("file")
+		if !uiAssets[file] {
+			problem.Abort(c, http.StatusNotFound, problem.CodeNotFound, "route not found")
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.FileFromFS(file, files)
+	}
+}
+
+const uiPage = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{title}}</title>
+<link rel="stylesheet" href="{{assets}}/swagger-ui.css">
+</head>
+<body>
+<div id="ui"></div>
+<script src="{{assets}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({ url: {{spec}}, dom_id: "#ui", persistAuthorization: true });
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
+)
+
+type widgetRequest struct {
+	Name  string   `json:"name" binding:"required,max=20"`
+	Email string   `json:"email" binding:"omitempty,email"`
+	Kind  string   `json:"kind" binding:"required,oneof=small large"`
+	Size  int      `json:"size" binding:"min=1"`
+	Tags  []string `json:"tags"`
+}
+
+type widget struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	DueDate   *time.Time `json:"dueDate"`
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func newTestDocument() *Document {
+	d := New(Info{Title: "test", Version: "1"}, problem.Problem{})
+	d.Op(http.MethodPost, "/widgets/:id", "saveWidget", "Save a widget").
+		PathParam("id", UUID()).
+		Query("limit", Integer(1), "").
+		Body(widgetRequest{}).
+		Returns(http.StatusOK, widget{}, "Saved")
+	return d
+}
+
+func TestSchemaOf_ResponseRequiresFieldsThatAreAlwaysWritten(t *testing.T) {
+	d := newTestDocument()
+
+	s := d.Components.Schemas["widget"]
+	if s == nil {
+		t.Fatalf("widget is not a component: %v", d.Components.Schemas)
+	}
+	if want := []string{"id", "name", "dueDate", "createdAt"}; !reflect.DeepEqual(s.Required, want) {
+		t.Fatalf("required = %v, want %v", s.Required, want)
+	}
+	if got := s.Properties["dueDate"].Type; !reflect.DeepEqual(got, []string{"string", "null"}) {
+		t.Fatalf("dueDate type = %v, want nullable string", got)
+	}
+	if got := s.Properties["createdAt"].Format; got != "date-time" {
+		t.Fatalf("createdAt format = %q, want date-time", got)
+	}
+}
+
+func TestSchemaOf_RequestTakesConstraintsFromBindingTags(t *testing.T) {
+	d := newTestDocument()
+
+	s := d.Components.Schemas["widgetRequest"]
+	if s == nil {
+		t.Fatalf("widgetRequest is not a component: %v", d.Components.Schemas)
+	}
+	if want := []string{"name", "kind"}; !reflect.DeepEqual(s.Required, want) {
+		t.Fatalf("required = %v, want %v", s.Required, want)
+	}
+	if got := s.Properties["name"]; *got.MinLength != 1 || *got.MaxLength != 20 {
+		t.Fatalf("name length = %d..%d, want 1..20", *got.MinLength, *got.MaxLength)
+	}
+	if got := s.Properties["email"].Format; got != "email" {
+		t.Fatalf("email format = %q, want email", got)
+	}
+	if got := s.Properties["kind"].Enum; !reflect.DeepEqual(got, []any{"small", "large"}) {
+		t.Fatalf("kind enum = %v", got)
+	}
+	if got := s.Properties["size"].Minimum; got == nil || *got != 1 {
+		t.Fatalf("size minimum = %v, want 1", got)
+	}
+}
+
+func TestOp_DocumentsImpliedFailures(t *testing.T) {
+	d := newTestDocument()
+
+	op, ok := d.Operation(http.MethodPost, "/widgets/:id")
+	if !ok {
+		t.Fatalf("operation not found; routes = %v", d.Routes())
+	}
+	for _, status := range []string{"400", "429", "500"} {
+		if op.Responses[status] == nil {
+			t.Fatalf("%s is not documented: %v", status, op.Responses)
+		}
+	}
+	if _, ok := d.Paths["/widgets/{id}"]; !ok {
+		t.Fatalf("paths = %v, want /widgets/{id}", d.Paths)
+	}
+}
+
+func serveValidated(t *testing.T, opts Options, handler gin.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	validate, err := newTestDocument().Validator(opts)
+	if err != nil {
+		t.Fatalf("Validator error = %v", err)
+	}
+	r := gin.New()
+	r.Use(validate)
+	r.POST("/widgets/:id", handler)
+	r.GET("/other", handler)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func ok(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"id": "w1", "name": "w", "dueDate": nil, "createdAt": "2024-01-02T03:04:05Z"})
+}
+
+const validID = "/widgets/0b6f0f6e-3c86-4a52-8f5e-27d2a3e0a0a1"
+
+func TestValidator_RejectsInvalidBodyWithFieldErrors(t *testing.T) {
+	w := serveValidated(t, Options{}, ok, http.MethodPost, validID, `{"name":"","kind":"huge","tags":[1]}`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body=%s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Code   string               `json:"code"`
+		Errors []problem.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != problem.CodeValidation {
+		t.Fatalf("code = %q, want %q", body.Code, problem.CodeValidation)
+	}
+
+	got := map[string]string{}
+	for _, fe := range body.Errors {
+		got[fe.Field] = fe.Code
+	}
+	want := map[string]string{"name": "min", "kind": "oneof", "tags[0]": "type"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("errors = %v, want %v", got, want)
+	}
+}
+
+func TestValidator_RejectsInvalidParameters(t *testing.T) {
+	w := serveValidated(t, Options{}, ok, http.MethodPost, "/widgets/nope?limit=0", `{"name":"a","kind":"small"}`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400. body=%s", w.Code, w.Body.String())
+	}
+	for _, want := range []string{`"field":"id","code":"uuid"`, `"field":"limit","code":"min"`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("body = %s, want it to contain %s", w.Body.String(), want)
+		}
+	}
+}
+
+func TestValidator_PassesValidAndUndocumentedRequests(t *testing.T) {
+	w := serveValidated(t, Options{}, ok, http.MethodPost, validID+"?limit=5", `{"name":"a","kind":"small","size":3}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200. body=%s", w.Code, w.Body.String())
+	}
+
+	w = serveValidated(t, Options{}, ok, http.MethodGet, "/other", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("undocumented route status = %d, want 200. body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestValidator_ReportsResponsesThatDoNotMatch(t *testing.T) {
+	var reported []error
+	opts := Options{Responses: true, OnResponseError: func(_ *gin.Context, err error) { reported = append(reported, err) }}
+
+	serveValidated(t, opts, ok, http.MethodPost, validID, `{"name":"a","kind":"small"}`)
+	if len(reported) != 0 {
+		t.Fatalf("valid response reported: %v", reported)
+	}
+
+	serveValidated(t, opts, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": "w1", "name": "w", "created_at": "2024-01-02T03:04:05Z"})
+	}, http.MethodPost, validID, `{"name":"a","kind":"small"}`)
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "createdAt is required") {
+		t.Fatalf("reported = %v, want the missing createdAt", reported)
+	}
+
+	reported = nil
+	serveValidated(t, opts, func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	}, http.MethodPost, validID, `{"name":"a","kind":"small"}`)
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "status 202 is not documented") {
+		t.Fatalf("reported = %v, want the undocumented status", reported)
+	}
+}
+
+func TestUI_ServesSwaggerUIFromAssets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/docs", UI("test", "/openapi.json", "/docs/assets/"))
+	r.GET("/docs/assets/*file", UIAssets())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	page := w.Body.String()
+	if !strings.Contains(page, `src="/docs/assets/swagger-ui-bundle.js"`) || strings.Contains(page, "https://") {
+		t.Fatalf("page does not load Swagger UI from the assets route:\n%s", page)
+	}
+
+	for path, want := range map[string]int{
+		"/docs/assets/swagger-ui-bundle.js": http.StatusOK,
+		"/docs/assets/swagger-ui.css":       http.StatusOK,
+		"/docs/assets/index.html":           http.StatusNotFound,
+		"/docs/assets/../openapi.go":        http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Fatalf("%s: status = %d, want %d", path, w.Code, want)
+		}
+		if want == http.StatusOK && w.Body.Len() == 0 {
+			t.Fatalf("%s: empty body", path)
+		}
+	}
+}
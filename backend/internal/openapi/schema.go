@@ -0,0 +1,338 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Schema is the JSON Schema 2020-12 subset the document uses.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+func String() *Schema { return &Schema{Type: "string"} }
+
+func UUID() *Schema { return &Schema{Type: "string", Format: "uuid"} }
+
+func Boolean() *Schema { return &Schema{Type: "boolean"} }
+
+func Binary() *Schema { return &Schema{Type: "string", Format: "binary"} }
+
+// Integer is an integer of at least min.
+func Integer(min int) *Schema {
+	m := float64(min)
+	return &Schema{Type: "integer", Minimum: &m}
+}
+
+func Enum(values ...string) *Schema {
+	s := &Schema{Type: "string"}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+func ArrayOf(items *Schema) *Schema { return &Schema{Type: "array", Items: items} }
+
+// WithDescription sets the description and returns s.
+func (s *Schema) WithDescription(text string) *Schema {
+	s.Description = text
+	return s
+}
+
+// SchemaOf returns the schema of v's type as a response, registering named
+// structs as components.
+func (d *Document) SchemaOf(v any) *Schema {
+	return d.schemas.of(reflect.TypeOf(v), false)
+}
+
+// Describer is implemented by types whose JSON form their Go type does not
+// show, e.g. a struct that unmarshals from a scalar.
+type Describer interface {
+	OpenAPISchema() *Schema
+}
+
+var (
+	describerType     = reflect.TypeOf((*Describer)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// registry derives schemas from Go types the way encoding/json marshals them.
+// Named structs become components referenced with $ref. Request types
+// (input) take "required" and constraints from their binding tags; response
+// types require every field that is not omitempty.
+type registry struct {
+	schemas map[string]*Schema
+	names   map[regKey]string
+	problem *Schema
+}
+
+type regKey struct {
+	t     reflect.Type
+	input bool
+}
+
+func newRegistry(schemas map[string]*Schema) *registry {
+	return &registry{schemas: schemas, names: map[regKey]string{}}
+}
+
+func (r *registry) of(t reflect.Type, input bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return UUID()
+	case rawMessageType:
+		return &Schema{}
+	}
+	if reflect.PointerTo(t).Implements(describerType) {
+		return reflect.New(t).Interface().(Describer).OpenAPISchema()
+	}
+	if t.Kind() != reflect.Struct && reflect.PointerTo(t).Implements(textMarshalerType) {
+		return String()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return String()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return ArrayOf(r.nullable(t.Elem(), input))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.nullable(t.Elem(), input)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t, input)
+		}
+		return r.component(t, input)
+	default:
+		// interfaces hold anything
+		return &Schema{}
+	}
+}
+
+// nullable is of for values encoding/json may write as null: pointers,
+// slices and maps.
+func (r *registry) nullable(t reflect.Type, input bool) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+	default:
+		return r.of(t, input)
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return r.of(t, input)
+	}
+
+	s := r.of(t, input)
+	switch typ := s.Type.(type) {
+	case string:
+		s.Type = []string{typ, "null"}
+		return s
+	case nil:
+		if s.Ref == "" {
+			// the empty schema already allows null
+			return s
+		}
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
+
+func (r *registry) component(t reflect.Type, input bool) *Schema {
+	key := regKey{t, input}
+	if name, ok := r.names[key]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	name := t.Name()
+	if r.taken(name) {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = upperFirst(pkg) + name
+	}
+	if r.taken(name) && input {
+		name += "Input"
+	}
+	r.names[key] = name
+	// reserve the name before descending, for recursive types
+	r.schemas[name] = &Schema{}
+	*r.schemas[name] = *r.object(t, input)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (r *registry) taken(name string) bool {
+	_, ok := r.schemas[name]
+	return ok
+}
+
+func (r *registry) object(t reflect.Type, input bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(s, t, input)
+	return s
+}
+
+func (r *registry) addFields(s *Schema, t reflect.Type, input bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// encoding/json promotes the fields of embedded structs
+			r.addFields(s, ft, input)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+		fs := r.nullable(f.Type, input)
+		if !input && omitempty {
+			// omitted rather than null
+			fs = r.of(f.Type, input)
+		}
+		rules := f.Tag.Get("binding")
+		applyBinding(fs, f.Type, rules)
+
+		s.Properties[name] = fs
+		if input && hasRule(rules, "required") || !input && !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBinding turns the validator rules gin enforces on a field into schema
+// constraints. Rules without an equivalent are left to the handler.
+func applyBinding(s *Schema, t reflect.Type, rules string) {
+	if rules == "" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			// later rules apply to the elements
+			return
+		case "required":
+			// the validator rejects empty strings as missing
+			if t.Kind() == reflect.String && s.MinLength == nil {
+				s.MinLength = intPtr(1)
+			}
+		case "email":
+			s.Format = "email"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "gte":
+			setBound(s, t, param, true)
+		case "max", "lte":
+			setBound(s, t, param, false)
+		}
+	}
+}
+
+func setBound(s *Schema, t reflect.Type, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			s.MinLength = intPtr(int(n))
+		} else {
+			s.MaxLength = intPtr(int(n))
+		}
+	case reflect.Map:
+		// no use in request types yet
+	case reflect.Slice, reflect.Array:
+		if lower {
+			s.MinItems = intPtr(int(n))
+		} else {
+			s.MaxItems = intPtr(int(n))
+		}
+	default:
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,391 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+
+	"task-management-platform/backend/internal/problem"
+)
+
+// Options configures Validator.
+type Options struct {
+	// Responses also checks what handlers write against the documented
+	// responses. Every JSON response is buffered, so this is meant for
+	// tests; OnResponseError receives each mismatch.
+	Responses       bool
+	OnResponseError func(c *gin.Context, err error)
+}
+
+type compiledOp struct {
+	params []compiledParam
+	body   *jsonschema.Schema
+	// bodyRequired rejects empty bodies; jsonOnly validates bodies whatever
+	// their content type, as gin binds them as JSON regardless.
+	bodyRequired bool
+	jsonOnly     bool
+	// responses maps status and media type, as in "200 application/json".
+	responses map[string]*jsonschema.Schema
+	declared  map[string]bool
+}
+
+type compiledParam struct {
+	name     string
+	in       string
+	required bool
+	typ      string
+	schema   *jsonschema.Schema
+}
+
+// Validator returns middleware checking requests against the operation
+// documented for their route. Invalid requests get a 400 problem listing
+// the rejected fields before any handler runs. Routes the document does
+// not describe pass unchecked.
+func (d *Document) Validator(opts Options) (gin.HandlerFunc, error) {
+	if opts.Responses && opts.OnResponseError == nil {
+		return nil, errors.New("openapi: Responses needs OnResponseError")
+	}
+
+	ops, err := d.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		op, ok := ops[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+		if !op.checkRequest(c) {
+			return
+		}
+		if !opts.Responses {
+			c.Next()
+			return
+		}
+
+		rec := &recorder{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+		if err := op.checkResponse(rec); err != nil {
+			opts.OnResponseError(c, fmt.Errorf("%s %s: %w", c.Request.Method, c.FullPath(), err))
+		}
+	}, nil
+}
+
+const resourceURL = "openapi.json"
+
+func (d *Document) compile() (map[string]*compiledOp, error) {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+	if err := c.AddResource(resourceURL, doc); err != nil {
+		return nil, err
+	}
+	schemaAt := func(ptr ...string) (*jsonschema.Schema, error) {
+		for i, p := range ptr {
+			ptr[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(p)
+		}
+		return c.Compile(resourceURL + "#/" + strings.Join(ptr, "/"))
+	}
+
+	out := make(map[string]*compiledOp, len(d.ops))
+	for key, o := range d.ops {
+		path := ginParam.ReplaceAllString(o.path, "{$1}")
+		method := strings.ToLower(o.method)
+		op := &compiledOp{responses: map[string]*jsonschema.Schema{}, declared: map[string]bool{}}
+
+		for i, p := range o.Parameters {
+			sch, err := schemaAt("paths", path, method, "parameters", strconv.Itoa(i), "schema")
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s parameter %s: %w", key, p.Name, err)
+			}
+			typ, _ := p.Schema.Type.(string)
+			op.params = append(op.params, compiledParam{name: p.Name, in: p.In, required: p.Required, typ: typ, schema: sch})
+		}
+
+		if rb := o.RequestBody; rb != nil {
+			if _, ok := rb.Content[MediaJSON]; ok {
+				op.body, err = schemaAt("paths", path, method, "requestBody", "content", MediaJSON, "schema")
+				if err != nil {
+					return nil, fmt.Errorf("openapi: %s body: %w", key, err)
+				}
+				op.bodyRequired = rb.Required
+				op.jsonOnly = len(rb.Content) == 1
+			}
+		}
+
+		for status, r := range o.Responses {
+			op.declared[status] = true
+			for mt := range r.Content {
+				if mt != MediaJSON && mt != MediaProblem {
+					continue
+				}
+				sch, err := schemaAt("paths", path, method, "responses", status, "content", mt, "schema")
+				if err != nil {
+					return nil, fmt.Errorf("openapi: %s response %s: %w", key, status, err)
+				}
+				op.responses[status+" "+mt] = sch
+			}
+		}
+		out[key] = op
+	}
+	return out, nil
+}
+
+func (op *compiledOp) checkRequest(c *gin.Context) bool {
+	var fields []problem.FieldError
+	for _, p := range op.params {
+		var raw string
+		var ok bool
+		if p.in == "path" {
+			raw, ok = c.Params.Get(p.name)
+		} else {
+			raw, ok = c.GetQuery(p.name)
+		}
+		if !ok {
+			if p.required {
+				fields = append(fields, problem.FieldError{Field: p.name, Code: "required", Message: "is required"})
+			}
+			continue
+		}
+
+		v, fe := paramValue(p, raw)
+		if fe != nil {
+			fields = append(fields, *fe)
+			continue
+		}
+		fields = append(fields, fieldErrors(p.name, p.schema.Validate(v))...)
+	}
+	if len(fields) > 0 {
+		writeInvalid(c, fields)
+		return false
+	}
+
+	if op.body == nil || (!op.jsonOnly && c.ContentType() != MediaJSON) {
+		return true
+	}
+	raw, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		problem.Abort(c, http.StatusBadRequest, problem.CodeBadRequest, "request body could not be read")
+		return false
+	}
+	// handlers bind the body again
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if op.bodyRequired {
+			problem.Abort(c, http.StatusBadRequest, problem.CodeBadRequest, "request body is empty")
+			return false
+		}
+		return true
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		problem.Abort(c, http.StatusBadRequest, problem.CodeBadRequest, "request body is not valid JSON")
+		return false
+	}
+	if fields := fieldErrors("", op.body.Validate(v)); len(fields) > 0 {
+		writeInvalid(c, fields)
+		return false
+	}
+	return true
+}
+
+func writeInvalid(c *gin.Context, fields []problem.FieldError) {
+	problem.Write(c, problem.New(http.StatusBadRequest, problem.CodeValidation, "some fields are invalid").WithErrors(fields...))
+}
+
+// paramValue converts a path or query string to the JSON type its schema
+// expects.
+func paramValue(p compiledParam, raw string) (any, *problem.FieldError) {
+	switch p.typ {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, &problem.FieldError{Field: p.name, Code: "type", Message: "must be an integer"}
+		}
+		return json.Number(raw), nil
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, &problem.FieldError{Field: p.name, Code: "type", Message: "must be a number"}
+		}
+		return json.Number(raw), nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, &problem.FieldError{Field: p.name, Code: "type", Message: "must be true or false"}
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// fieldErrors flattens a schema validation error into one entry per failed
+// rule, with codes and messages in the style of the handlers' own binding
+// errors.
+func fieldErrors(prefix string, err error) []problem.FieldError {
+	if err == nil {
+		return nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []problem.FieldError{{Field: prefix, Code: "invalid", Message: err.Error()}}
+	}
+
+	var out []problem.FieldError
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			out = append(out, leafErrors(prefix, e)...)
+			return
+		}
+		for _, cause := range e.Causes {
+			// a nullable value that is present fails its "null" branch too
+			if _, anyOf := e.ErrorKind.(*kind.AnyOf); anyOf && wantsNull(cause) {
+				continue
+			}
+			walk(cause)
+		}
+	}
+	walk(ve)
+	return out
+}
+
+func wantsNull(e *jsonschema.ValidationError) bool {
+	t, ok := e.ErrorKind.(*kind.Type)
+	return ok && len(t.Want) == 1 && t.Want[0] == "null"
+}
+
+func leafErrors(prefix string, e *jsonschema.ValidationError) []problem.FieldError {
+	field := fieldPath(prefix, e.InstanceLocation)
+
+	switch k := e.ErrorKind.(type) {
+	case *kind.Required:
+		out := make([]problem.FieldError, 0, len(k.Missing))
+		for _, m := range k.Missing {
+			out = append(out, problem.FieldError{Field: fieldPath(field, []string{m}), Code: "required", Message: "is required"})
+		}
+		return out
+	case *kind.Type:
+		return []problem.FieldError{{Field: field, Code: "type", Message: "must be " + strings.Join(k.Want, " or ")}}
+	case *kind.Enum:
+		want := make([]string, 0, len(k.Want))
+		for _, w := range k.Want {
+			want = append(want, fmt.Sprint(w))
+		}
+		return []problem.FieldError{{Field: field, Code: "oneof", Message: "must be one of " + strings.Join(want, ", ")}}
+	case *kind.Format:
+		return []problem.FieldError{{Field: field, Code: k.Want, Message: formatMessage(k.Want)}}
+	case *kind.MinLength:
+		return []problem.FieldError{{Field: field, Code: "min", Message: fmt.Sprintf("must be at least %d characters", k.Want)}}
+	case *kind.MaxLength:
+		return []problem.FieldError{{Field: field, Code: "max", Message: fmt.Sprintf("must be at most %d characters", k.Want)}}
+	case *kind.MinItems:
+		return []problem.FieldError{{Field: field, Code: "min", Message: fmt.Sprintf("must have at least %d items", k.Want)}}
+	case *kind.MaxItems:
+		return []problem.FieldError{{Field: field, Code: "max", Message: fmt.Sprintf("must have at most %d items", k.Want)}}
+	case *kind.Minimum:
+		return []problem.FieldError{{Field: field, Code: "min", Message: "must be at least " + k.Want.RatString()}}
+	case *kind.Maximum:
+		return []problem.FieldError{{Field: field, Code: "max", Message: "must be at most " + k.Want.RatString()}}
+	}
+	path := e.ErrorKind.KeywordPath()
+	code := "invalid"
+	if len(path) > 0 {
+		code = path[len(path)-1]
+	}
+	return []problem.FieldError{{Field: field, Code: code, Message: "is invalid"}}
+}
+
+func formatMessage(format string) string {
+	switch format {
+	case "email":
+		return "must be a valid email address"
+	case "uuid":
+		return "must be a UUID"
+	case "date-time":
+		return "must be an RFC 3339 timestamp"
+	}
+	return "must be a valid " + format
+}
+
+// fieldPath joins JSON locations the way binding errors name fields:
+// "rows[2].title".
+func fieldPath(prefix string, loc []string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for _, seg := range loc {
+		if _, err := strconv.Atoi(seg); err == nil {
+			b.WriteString("[" + seg + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(seg)
+	}
+	return b.String()
+}
+
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (op *compiledOp) checkResponse(w *recorder) error {
+	status := strconv.Itoa(w.Status())
+	if !op.declared[status] {
+		return fmt.Errorf("status %s is not documented", status)
+	}
+	mt, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if mt != MediaJSON && mt != MediaProblem {
+		return nil
+	}
+
+	sch, ok := op.responses[status+" "+mt]
+	if !ok {
+		return fmt.Errorf("%s %s response is not documented", status, mt)
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(w.body.Bytes()))
+	if err != nil {
+		return fmt.Errorf("%s response is not valid JSON: %w", status, err)
+	}
+	if fields := fieldErrors("", sch.Validate(v)); len(fields) > 0 {
+		msgs := make([]string, 0, len(fields))
+		for _, f := range fields {
+			msgs = append(msgs, strings.TrimPrefix(f.Field+" "+f.Message, " "))
+		}
+		return fmt.Errorf("%s response does not match the schema: %s", status, strings.Join(msgs, "; "))
+	}
+	return nil
+}
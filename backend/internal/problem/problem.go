@@ -0,0 +1,140 @@
+// Package problem writes API errors as RFC 9457 (formerly RFC 7807)
+// problem details. Every error response has the same envelope and a stable
+// machine-readable code, so clients can branch on the code and show the
+// detail without knowing which endpoint failed.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of every error response.
+const ContentType = "application/problem+json"
+
+// TypePrefix is joined with the code to form the problem type URI, a
+// reference relative to the API's base URL.
+const TypePrefix = "/problems/"
+
+// Stable error codes. Clients may rely on them; add new ones rather than
+// changing the meaning of existing ones.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidation           = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeForbidden            = "forbidden"
+	CodeSecondFactorRequired = "second_factor_required"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeBusinessRule         = "business_rule_violation"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMedia     = "unsupported_media_type"
+	CodeRateLimited          = "rate_limited"
+	CodeLoginLocked          = "login_locked"
+	CodeInternal             = "internal_error"
+	CodeUnavailable          = "service_unavailable"
+)
+
+var titles = map[string]string{
+	CodeBadRequest:           "Bad request",
+	CodeValidation:           "Validation failed",
+	CodeUnauthorized:         "Unauthorized",
+	CodeInvalidCredentials:   "Invalid credentials",
+	CodeForbidden:            "Forbidden",
+	CodeSecondFactorRequired: "Second factor required",
+	CodeNotFound:             "Not found",
+	CodeConflict:             "Conflict",
+	CodeBusinessRule:         "Business rule violation",
+	CodePayloadTooLarge:      "Payload too large",
+	CodeUnsupportedMedia:     "Unsupported media type",
+	CodeRateLimited:          "Too many requests",
+	CodeLoginLocked:          "Login locked",
+	CodeInternal:             "Internal error",
+	CodeUnavailable:          "Service unavailable",
+}
+
+// FieldError explains why one input field was rejected. Field is the JSON
+// name, dotted for nested values; Code is the rule that failed, e.g.
+// "required", "email" or "invalid".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem is the error envelope. Type, Title and Status follow the RFC; Code,
+// RequestID and Errors are extension members.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Extensions are further members, e.g. the report of a rejected
+	// import. Keys must not repeat the members above.
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON flattens Extensions into the object, as the RFC places
+// extension members next to the standard ones.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	b, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+	ext, err := json.Marshal(p.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(b[:len(b)-1], ','), ext[1:]...), nil
+}
+
+// New builds a problem; detail is a human-readable explanation specific to
+// this occurrence.
+func New(status int, code, detail string) *Problem {
+	title, ok := titles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+	return &Problem{
+		Type:   TypePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithErrors attaches field-level details.
+func (p *Problem) WithErrors(errs ...FieldError) *Problem {
+	p.Errors = append(p.Errors, errs...)
+	return p
+}
+
+// With sets an extension member.
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]any{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// Write sends p and aborts the handler chain. The request id set by the
+// RequestID middleware is echoed so a report can be matched to the logs.
+func Write(c *gin.Context, p *Problem) {
+	p.RequestID = c.Writer.Header().Get("X-Request-ID")
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Abort writes a problem without field details.
+func Abort(c *gin.Context, status int, code, detail string) {
+	Write(c, New(status, code, detail))
+}
@@ -0,0 +1,93 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serve(h gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		c.Header("X-Request-ID", "req-1")
+		c.Next()
+	}, h)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestAbort_WritesEnvelope(t *testing.T) {
+	w := serve(func(c *gin.Context) {
+		Abort(c, http.StatusNotFound, CodeNotFound, "task not found")
+	})
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("Content-Type = %q, want %q", ct, ContentType)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal error = %v. body=%s", err, w.Body.String())
+	}
+	want := map[string]any{
+		"type":      "/problems/not_found",
+		"title":     "Not found",
+		"status":    float64(http.StatusNotFound),
+		"detail":    "task not found",
+		"code":      CodeNotFound,
+		"requestId": "req-1",
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Fatalf("%s = %v, want %v. body=%s", k, body[k], v, w.Body.String())
+		}
+	}
+	if _, ok := body["errors"]; ok {
+		t.Fatalf("expected no errors member, got %s", w.Body.String())
+	}
+}
+
+func TestWrite_FieldErrorsAndExtensions(t *testing.T) {
+	w := serve(func(c *gin.Context) {
+		Write(c, New(http.StatusUnprocessableEntity, CodeValidation, "2 rows are invalid").
+			WithErrors(FieldError{Field: "title", Code: "required", Message: "title is required"}).
+			With("report", map[string]int{"invalid": 2}))
+	})
+
+	var body struct {
+		Code   string       `json:"code"`
+		Errors []FieldError `json:"errors"`
+		Report struct {
+			Invalid int `json:"invalid"`
+		} `json:"report"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal error = %v. body=%s", err, w.Body.String())
+	}
+	if body.Code != CodeValidation {
+		t.Fatalf("code = %q, want %q", body.Code, CodeValidation)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Field != "title" || body.Errors[0].Code != "required" {
+		t.Fatalf("errors = %+v", body.Errors)
+	}
+	if body.Report.Invalid != 2 {
+		t.Fatalf("expected the report extension at the top level, got %s", w.Body.String())
+	}
+}
+
+func TestNew_UnknownCodeFallsBackToStatusText(t *testing.T) {
+	p := New(http.StatusTeapot, "teapot", "")
+	if p.Title != http.StatusText(http.StatusTeapot) {
+		t.Fatalf("Title = %q, want %q", p.Title, http.StatusText(http.StatusTeapot))
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"task-management-platform/backend/internal/models"
+)
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *models.AccessToken) error
+	GetByID(ctx context.Context, id string) (*models.AccessToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*models.AccessToken, error)
+	ListByUser(ctx context.Context, userID string) ([]models.AccessToken, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeAllForUser(ctx context.Context, userID string) (int64, error)
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+type accessTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccessTokenRepository(db *sqlx.DB) AccessTokenRepository {
+	return &accessTokenRepository{db: db}
+}
+
+const accessTokenColumns = `
+	id, user_id, name, prefix, token_hash, scopes, project_id,
+	expires_at, last_used_at, revoked_at, created_at
+`
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *models.AccessToken) error {
+	query := `
+		INSERT INTO access_tokens (id, user_id, name, prefix, token_hash, scopes, project_id, expires_at, created_at)
+		VALUES (:id, :user_id, :name, :prefix, :token_hash, :scopes, :project_id, :expires_at, :created_at)
+	`
+	_, err := r.db.NamedExecContext(ctx, query, token)
+	return err
+}
+
+func (r *accessTokenRepository) GetByID(ctx context.Context, id string) (*models.AccessToken, error) {
+	var t models.AccessToken
+
+	query := `SELECT ` + accessTokenColumns + ` FROM access_tokens WHERE id = $1`
+	if err := r.db.GetContext(ctx, &t, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *accessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.AccessToken, error) {
+	var t models.AccessToken
+
+	query := `SELECT ` + accessTokenColumns + ` FROM access_tokens WHERE token_hash = $1`
+	if err := r.db.GetContext(ctx, &t, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *accessTokenRepository) ListByUser(ctx context.Context, userID string) ([]models.AccessToken, error) {
+	tokens := make([]models.AccessToken, 0)
+
+	query := `
+		SELECT ` + accessTokenColumns + `
+		FROM access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &tokens, query, userID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Revoke is idempotent: revoking an already revoked token keeps the original
+// revocation time.
+func (r *accessTokenRepository) Revoke(ctx context.Context, id string) error {
+	query := `
+		UPDATE access_tokens
+		SET revoked_at = COALESCE(revoked_at, now())
+		WHERE id = $1
+	`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeAllForUser revokes every active token of a user and reports how many
+// it revoked.
+func (r *accessTokenRepository) RevokeAllForUser(ctx context.Context, userID string) (int64, error) {
+	query := `
+		UPDATE access_tokens
+		SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *accessTokenRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE access_tokens SET last_used_at = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, at)
+	return err
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/cache"
+	"task-management-platform/backend/internal/models"
+)
+
+// Cache key layout shared by the cached repositories and the services that
+// invalidate them.
+const (
+	CachePrefixProjects  = "projects:"
+	CachePrefixTasks     = "tasks:"
+	CachePrefixUsers     = "users:"
+	CacheKeyMinimalUsers = CachePrefixUsers + "minimal"
+)
+
+// cachedProjectRepository serves project reads from the cache. Deleting a
+// project also drops cached tasks because they go to the trash with it.
+type cachedProjectRepository struct {
+	inner  ProjectRepository
+	loader *cache.Loader
+}
+
+func NewCachedProjectRepository(inner ProjectRepository, loader *cache.Loader) ProjectRepository {
+	return &cachedProjectRepository{inner: inner, loader: loader}
+}
+
+func (r *cachedProjectRepository) Create(ctx context.Context, project *models.Project) error {
+	if err := r.inner.Create(ctx, project); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixProjects)
+	return nil
+}
+
+func (r *cachedProjectRepository) GetByID(ctx context.Context, id string) (*models.Project, error) {
+	return cache.Fetch(ctx, r.loader, CachePrefixProjects+"id:"+id, func(ctx context.Context) (*models.Project, error) {
+		return r.inner.GetByID(ctx, id)
+	})
+}
+
+func (r *cachedProjectRepository) ListByOwner(ctx context.Context, ownerID string) ([]models.Project, error) {
+	return cache.Fetch(ctx, r.loader, CachePrefixProjects+"owner:"+ownerID, func(ctx context.Context) ([]models.Project, error) {
+		return r.inner.ListByOwner(ctx, ownerID)
+	})
+}
+
+func (r *cachedProjectRepository) UpdateName(ctx context.Context, id string, name string) error {
+	if err := r.inner.UpdateName(ctx, id, name); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixProjects)
+	return nil
+}
+
+func (r *cachedProjectRepository) Delete(ctx context.Context, id string) error {
+	if err := r.inner.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixProjects, CachePrefixTasks)
+	return nil
+}
+
+func (r *cachedProjectRepository) List(ctx context.Context, archived bool) ([]models.Project, error) {
+	key := CachePrefixProjects + "all"
+	if archived {
+		key = CachePrefixProjects + "archived"
+	}
+	return cache.Fetch(ctx, r.loader, key, func(ctx context.Context) ([]models.Project, error) {
+		return r.inner.List(ctx, archived)
+	})
+}
+
+func (r *cachedProjectRepository) SetArchived(ctx context.Context, id string, archived bool) error {
+	if err := r.inner.SetArchived(ctx, id, archived); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixProjects)
+	return nil
+}
+
+type cachedAPIUserRepository struct {
+	inner  APIUserRepository
+	loader *cache.Loader
+}
+
+// NewCachedAPIUserRepository caches the minimal user list. The users table is
+// written through UserService and AuthService, which invalidate
+// CacheKeyMinimalUsers themselves.
+func NewCachedAPIUserRepository(inner APIUserRepository, loader *cache.Loader) APIUserRepository {
+	return &cachedAPIUserRepository{inner: inner, loader: loader}
+}
+
+func (r *cachedAPIUserRepository) ListMinimal(ctx context.Context) ([]models.MinimalUser, error) {
+	return cache.Fetch(ctx, r.loader, CacheKeyMinimalUsers, r.inner.ListMinimal)
+}
+
+type cachedTaskRepository struct {
+	inner  TaskRepository
+	loader *cache.Loader
+}
+
+func NewCachedTaskRepository(inner TaskRepository, loader *cache.Loader) TaskRepository {
+	return &cachedTaskRepository{inner: inner, loader: loader}
+}
+
+func (r *cachedTaskRepository) Create(ctx context.Context, task *models.Task) error {
+	if err := r.inner.Create(ctx, task); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixTasks)
+	return nil
+}
+
+func (r *cachedTaskRepository) CreateMany(ctx context.Context, tasks []models.Task) error {
+	if err := r.inner.CreateMany(ctx, tasks); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixTasks)
+	return nil
+}
+
+func (r *cachedTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	return cache.Fetch(ctx, r.loader, CachePrefixTasks+"id:"+id.String(), func(ctx context.Context) (*models.Task, error) {
+		return r.inner.GetByID(ctx, id)
+	})
+}
+
+func (r *cachedTaskRepository) List(ctx context.Context, filters TaskFilters) ([]models.Task, error) {
+	return cache.Fetch(ctx, r.loader, taskListCacheKey(filters), func(ctx context.Context) ([]models.Task, error) {
+		return r.inner.List(ctx, filters)
+	})
+}
+
+func (r *cachedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.inner.Update(ctx, task); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixTasks)
+	return nil
+}
+
+func (r *cachedTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.inner.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixTasks)
+	return nil
+}
+
+func (r *cachedTaskRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.Task, error) {
+	return cache.Fetch(ctx, r.loader, CachePrefixTasks+"project:"+projectID.String(), func(ctx context.Context) ([]models.Task, error) {
+		return r.inner.ListByProject(ctx, projectID)
+	})
+}
+
+func (r *cachedTaskRepository) ListSubtasks(ctx context.Context, parentID uuid.UUID) ([]models.Task, error) {
+	return cache.Fetch(ctx, r.loader, CachePrefixTasks+"subtasks:"+parentID.String(), func(ctx context.Context) ([]models.Task, error) {
+		return r.inner.ListSubtasks(ctx, parentID)
+	})
+}
+
+// Ancestors and SubtreeHeight guard moves, so they always read the database.
+func (r *cachedTaskRepository) Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	return r.inner.Ancestors(ctx, id)
+}
+
+func (r *cachedTaskRepository) SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error) {
+	return r.inner.SubtreeHeight(ctx, id)
+}
+
+func (r *cachedTaskRepository) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	if err := r.inner.SetParent(ctx, id, parentID); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixTasks)
+	return nil
+}
+
+// WithTree reads inside the transaction, uncached, and invalidates once it
+// commits.
+func (r *cachedTaskRepository) WithTree(ctx context.Context, projectID uuid.UUID, fn func(tree TaskTree) error) error {
+	if err := r.inner.WithTree(ctx, projectID, fn); err != nil {
+		return err
+	}
+	r.loader.InvalidatePrefix(ctx, CachePrefixTasks)
+	return nil
+}
+
+func taskListCacheKey(f TaskFilters) string {
+	project, assignee, status := "*", "*", "*"
+	if f.ProjectID != nil {
+		project = f.ProjectID.String()
+	}
+	if f.AssigneeID != nil {
+		assignee = f.AssigneeID.String()
+	}
+	if f.Status != nil {
+		status = *f.Status
+	}
+	return fmt.Sprintf("%slist:%s:%s:%s:%d:%d", CachePrefixTasks, project, assignee, status, f.Limit, f.Offset)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"task-management-platform/backend/internal/models"
+)
+
+type CalendarFeedRepository interface {
+	Create(ctx context.Context, feed *models.CalendarFeed) error
+	GetByID(ctx context.Context, id string) (*models.CalendarFeed, error)
+	GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	ListByUser(ctx context.Context, userID string) ([]models.CalendarFeed, error)
+	Revoke(ctx context.Context, id string) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+	// ListTasks returns the dated tasks assigned to userID or belonging to
+	// one of projectIDs.
+	ListTasks(ctx context.Context, userID string, projectIDs []string) ([]models.CalendarTask, error)
+}
+
+type calendarFeedRepository struct {
+	db *sqlx.DB
+}
+
+func NewCalendarFeedRepository(db *sqlx.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+const calendarFeedColumns = `
+	id, user_id, name, prefix, token_hash, project_ids,
+	last_used_at, revoked_at, created_at
+`
+
+func (r *calendarFeedRepository) Create(ctx context.Context, feed *models.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (id, user_id, name, prefix, token_hash, project_ids, created_at)
+		VALUES (:id, :user_id, :name, :prefix, :token_hash, :project_ids, :created_at)
+	`
+	_, err := r.db.NamedExecContext(ctx, query, feed)
+	return err
+}
+
+func (r *calendarFeedRepository) GetByID(ctx context.Context, id string) (*models.CalendarFeed, error) {
+	var f models.CalendarFeed
+
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feeds WHERE id = $1`
+	if err := r.db.GetContext(ctx, &f, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *calendarFeedRepository) GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var f models.CalendarFeed
+
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feeds WHERE token_hash = $1`
+	if err := r.db.GetContext(ctx, &f, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *calendarFeedRepository) ListByUser(ctx context.Context, userID string) ([]models.CalendarFeed, error) {
+	feeds := make([]models.CalendarFeed, 0)
+
+	query := `
+		SELECT ` + calendarFeedColumns + `
+		FROM calendar_feeds
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &feeds, query, userID); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// Revoke is idempotent like accessTokenRepository.Revoke.
+func (r *calendarFeedRepository) Revoke(ctx context.Context, id string) error {
+	query := `
+		UPDATE calendar_feeds
+		SET revoked_at = COALESCE(revoked_at, now())
+		WHERE id = $1
+	`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *calendarFeedRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE calendar_feeds SET last_used_at = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, at)
+	return err
+}
+
+func (r *calendarFeedRepository) ListTasks(ctx context.Context, userID string, projectIDs []string) ([]models.CalendarTask, error) {
+	tasks := make([]models.CalendarTask, 0)
+
+	query := `
+		SELECT t.id, t.project_id, t.title, COALESCE(t.description, '') AS description,
+		       t.status, t.assignee_id, t.due_date, t.created_at, t.updated_at,
+		       p.name AS project_name
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.due_date IS NOT NULL
+		  AND t.deleted_at IS NULL
+		  AND (t.assignee_id = $1 OR t.project_id = ANY($2::uuid[]))
+		ORDER BY t.due_date, t.created_at
+	`
+	if err := r.db.SelectContext(ctx, &tasks, query, userID, pq.StringArray(projectIDs)); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"task-management-platform/backend/internal/models"
+)
+
+// ChecklistRepository stores the checklist items of tasks. Every call is
+// scoped to one task, so an item id from another task is not found.
+type ChecklistRepository interface {
+	// List returns the task's items in order.
+	List(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error)
+	// Create appends the item to the end of its task's checklist.
+	Create(ctx context.Context, item *models.ChecklistItem) error
+	Update(ctx context.Context, item *models.ChecklistItem) error
+	Delete(ctx context.Context, taskID, id uuid.UUID) error
+}
+
+type checklistRepository struct {
+	db *sqlx.DB
+}
+
+func NewChecklistRepository(db *sqlx.DB) ChecklistRepository {
+	return &checklistRepository{db: db}
+}
+
+func (r *checklistRepository) List(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error) {
+	items := make([]models.ChecklistItem, 0)
+
+	query := `
+		SELECT id, task_id, title, done, position, created_at, updated_at
+		FROM task_checklist_items
+		WHERE task_id = $1
+		ORDER BY position, created_at
+	`
+	if err := r.db.SelectContext(ctx, &items, query, taskID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *checklistRepository) Create(ctx context.Context, item *models.ChecklistItem) error {
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO task_checklist_items (id, task_id, title, done, position)
+		SELECT $1, $2, $3, $4, COALESCE(MAX(position), 0) + 1
+		FROM task_checklist_items
+		WHERE task_id = $2
+		RETURNING position, created_at, updated_at
+	`
+	return r.db.GetContext(ctx, item, query, item.ID, item.TaskID, item.Title, item.Done)
+}
+
+func (r *checklistRepository) Update(ctx context.Context, item *models.ChecklistItem) error {
+	query := `
+		UPDATE task_checklist_items
+		SET title = $3, done = $4, updated_at = NOW()
+		WHERE id = $1 AND task_id = $2
+		RETURNING position, created_at, updated_at
+	`
+	if err := r.db.GetContext(ctx, item, query, item.ID, item.TaskID, item.Title, item.Done); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, taskID, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM task_checklist_items WHERE id = $1 AND task_id = $2`, id, taskID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
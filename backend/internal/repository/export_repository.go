@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"task-management-platform/backend/internal/models"
+)
+
+// ExportFilter narrows an export. Nil fields do not filter; the date range
+// applies to created_at and is half-open, [From, To). Rows the selected ones
+// refer to are exported whatever their created_at, so an archive always
+// imports as-is.
+type ExportFilter struct {
+	ProjectID *string
+	From      *time.Time
+	To        *time.Time
+}
+
+// ExportRepository streams rows from database cursors so exports never hold
+// a whole table in memory. Iteration stops at the first error fn returns.
+// Projects and tasks in the trash are left out.
+type ExportRepository struct {
+	db *sqlx.DB
+}
+
+func NewExportRepository(db *sqlx.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// exportScope selects the ids every stream shares: the tasks matching the
+// filter plus their ancestors, so each parent_id resolves, and the projects
+// matching it plus those of the selected tasks. The depth bound stops the
+// walk should the data ever hold a cycle.
+const exportScope = `
+	WITH RECURSIVE matched AS (
+		SELECT t.id, t.parent_id, 0 AS depth
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND ($1::uuid IS NULL OR t.project_id = $1)
+		  AND ($2::timestamptz IS NULL OR t.created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR t.created_at < $3)
+		UNION
+		SELECT t.id, t.parent_id, m.depth + 1
+		FROM tasks t
+		JOIN matched m ON t.id = m.parent_id
+		WHERE t.deleted_at IS NULL AND m.depth < 100
+	),
+	export_tasks AS (
+		SELECT DISTINCT id FROM matched
+	),
+	export_projects AS (
+		SELECT id
+		FROM projects
+		WHERE deleted_at IS NULL
+		  AND ($1::uuid IS NULL OR id = $1)
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR created_at < $3)
+		UNION
+		SELECT t.project_id FROM tasks t JOIN export_tasks e ON e.id = t.id
+	)
+`
+
+// StreamUsers yields the owners and assignees of the exported projects and
+// tasks and, without a project filter, every user created in the range.
+func (r *ExportRepository) StreamUsers(ctx context.Context, f ExportFilter, fn func(models.User) error) error {
+	query := exportScope + `
+		SELECT id, email, role, kind, created_at
+		FROM users
+		WHERE ($1::uuid IS NULL
+		        AND ($2::timestamptz IS NULL OR created_at >= $2)
+		        AND ($3::timestamptz IS NULL OR created_at < $3))
+		   OR id IN (SELECT p.owner_id FROM projects p JOIN export_projects e ON e.id = p.id)
+		   OR id IN (SELECT t.assignee_id FROM tasks t JOIN export_tasks e ON e.id = t.id)
+		ORDER BY created_at, id
+	`
+	return stream(ctx, r.db, query, []any{f.ProjectID, f.From, f.To}, fn)
+}
+
+func (r *ExportRepository) StreamProjects(ctx context.Context, f ExportFilter, fn func(models.Project) error) error {
+	query := exportScope + `
+		SELECT id, name, owner_id, created_at, archived_at
+		FROM projects
+		WHERE id IN (SELECT id FROM export_projects)
+		ORDER BY created_at, id
+	`
+	return stream(ctx, r.db, query, []any{f.ProjectID, f.From, f.To}, fn)
+}
+
+func (r *ExportRepository) StreamTasks(ctx context.Context, f ExportFilter, fn func(models.Task) error) error {
+	query := exportScope + `
+		SELECT id, project_id, parent_id, title, COALESCE(description, '') AS description,
+		       status, assignee_id, due_date, created_at, updated_at
+		FROM tasks
+		WHERE id IN (SELECT id FROM export_tasks)
+		ORDER BY created_at, id
+	`
+	return stream(ctx, r.db, query, []any{f.ProjectID, f.From, f.To}, fn)
+}
+
+// StreamChecklistItems yields the checklist items of the tasks StreamTasks
+// yields for the same filter.
+func (r *ExportRepository) StreamChecklistItems(ctx context.Context, f ExportFilter, fn func(models.ChecklistItem) error) error {
+	query := exportScope + `
+		SELECT c.id, c.task_id, c.title, c.done, c.position, c.created_at, c.updated_at
+		FROM task_checklist_items c
+		JOIN tasks t ON t.id = c.task_id
+		WHERE c.task_id IN (SELECT id FROM export_tasks)
+		ORDER BY t.created_at, t.id, c.position, c.id
+	`
+	return stream(ctx, r.db, query, []any{f.ProjectID, f.From, f.To}, fn)
+}
+
+func stream[T any](ctx context.Context, db *sqlx.DB, query string, args []any, fn func(T) error) error {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"task-management-platform/backend/internal/models"
+)
+
+// RowState is what an import finds under an id. Trashed rows keep their id,
+// so they can neither be inserted again nor silently updated.
+type RowState int
+
+const (
+	RowMissing RowState = iota
+	RowLive
+	RowTrashed
+)
+
+// ImportTx is the set of writes a data import needs, all bound to one
+// transaction. Updates leave trashed rows alone. The embedded TaskTree lets
+// the import check subtask parents by the same rules as a move.
+type ImportTx interface {
+	TaskTree
+
+	UserByID(ctx context.Context, id string) (*models.User, error)
+	UserByEmail(ctx context.Context, email string) (*models.User, error)
+	InsertUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
+
+	ProjectState(ctx context.Context, id string) (RowState, error)
+	InsertProject(ctx context.Context, project *models.Project) error
+	UpdateProject(ctx context.Context, project *models.Project) error
+
+	TaskState(ctx context.Context, id uuid.UUID) (RowState, error)
+	InsertTask(ctx context.Context, task *models.Task) error
+	UpdateTask(ctx context.Context, task *models.Task) error
+	// LockTree takes the project's tree lock, as TaskRepository.WithTree
+	// does, until the import ends.
+	LockTree(ctx context.Context, projectID uuid.UUID) error
+
+	// ChecklistItemState never reports RowTrashed; items are deleted for
+	// good.
+	ChecklistItemState(ctx context.Context, id uuid.UUID) (RowState, error)
+	InsertChecklistItem(ctx context.Context, item *models.ChecklistItem) error
+	UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) error
+}
+
+type ImportRepository struct {
+	db *sqlx.DB
+}
+
+func NewImportRepository(db *sqlx.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// WithTx runs fn in a transaction, committing only when fn returns nil.
+func (r *ImportRepository) WithTx(ctx context.Context, fn func(tx ImportTx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&importTx{tx: tx, taskTree: taskTree{q: tx}}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type importTx struct {
+	tx *sqlx.Tx
+	taskTree
+}
+
+func (t *importTx) UserByID(ctx context.Context, id string) (*models.User, error) {
+	return t.getUser(ctx, `SELECT id, email, role, kind, created_at FROM users WHERE id = $1`, id)
+}
+
+func (t *importTx) UserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return t.getUser(ctx, `SELECT id, email, role, kind, created_at FROM users WHERE email = $1`, email)
+}
+
+func (t *importTx) getUser(ctx context.Context, query string, arg any) (*models.User, error) {
+	var u models.User
+	if err := t.tx.GetContext(ctx, &u, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (t *importTx) InsertUser(ctx context.Context, user *models.User) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		INSERT INTO users (id, email, password_hash, role, kind, created_at)
+		VALUES (:id, :email, :password_hash, :role, :kind, :created_at)
+	`, user)
+	return err
+}
+
+func (t *importTx) UpdateUser(ctx context.Context, user *models.User) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		UPDATE users
+		SET email = :email, role = :role, kind = :kind
+		WHERE id = :id
+	`, user)
+	return err
+}
+
+func (t *importTx) ProjectState(ctx context.Context, id string) (RowState, error) {
+	return t.rowState(ctx, `SELECT deleted_at IS NOT NULL FROM projects WHERE id = $1`, id)
+}
+
+func (t *importTx) rowState(ctx context.Context, query string, id any) (RowState, error) {
+	var trashed bool
+	err := t.tx.GetContext(ctx, &trashed, query, id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return RowMissing, nil
+	case err != nil:
+		return RowMissing, err
+	case trashed:
+		return RowTrashed, nil
+	}
+	return RowLive, nil
+}
+
+func (t *importTx) InsertProject(ctx context.Context, project *models.Project) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		INSERT INTO projects (id, name, owner_id, created_at, archived_at)
+		VALUES (:id, :name, :owner_id, :created_at, :archived_at)
+	`, project)
+	return err
+}
+
+func (t *importTx) UpdateProject(ctx context.Context, project *models.Project) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		UPDATE projects
+		SET name = :name, owner_id = :owner_id, archived_at = :archived_at
+		WHERE id = :id AND deleted_at IS NULL
+	`, project)
+	return err
+}
+
+func (t *importTx) TaskState(ctx context.Context, id uuid.UUID) (RowState, error) {
+	return t.rowState(ctx, `SELECT deleted_at IS NOT NULL FROM tasks WHERE id = $1`, id)
+}
+
+func (t *importTx) InsertTask(ctx context.Context, task *models.Task) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		INSERT INTO tasks (id, project_id, parent_id, title, description, status, assignee_id, due_date, created_at, updated_at)
+		VALUES (:id, :project_id, :parent_id, :title, :description, :status, :assignee_id, :due_date, :created_at, :updated_at)
+	`, task)
+	return err
+}
+
+func (t *importTx) UpdateTask(ctx context.Context, task *models.Task) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		UPDATE tasks
+		SET parent_id = :parent_id,
+		    title = :title,
+		    description = :description,
+		    status = :status,
+		    assignee_id = :assignee_id,
+		    due_date = :due_date,
+		    updated_at = :updated_at
+		WHERE id = :id AND deleted_at IS NULL
+	`, task)
+	return err
+}
+
+func (t *importTx) LockTree(ctx context.Context, projectID uuid.UUID) error {
+	return lockTree(ctx, t.tx, projectID)
+}
+
+func (t *importTx) ChecklistItemState(ctx context.Context, id uuid.UUID) (RowState, error) {
+	return t.rowState(ctx, `SELECT false FROM task_checklist_items WHERE id = $1`, id)
+}
+
+func (t *importTx) InsertChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		INSERT INTO task_checklist_items (id, task_id, title, done, position, created_at, updated_at)
+		VALUES (:id, :task_id, :title, :done, :position, :created_at, :updated_at)
+	`, item)
+	return err
+}
+
+func (t *importTx) UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	_, err := t.tx.NamedExecContext(ctx, `
+		UPDATE task_checklist_items
+		SET task_id = :task_id,
+		    title = :title,
+		    done = :done,
+		    position = :position,
+		    updated_at = :updated_at
+		WHERE id = :id
+	`, item)
+	return err
+}
@@ -4,19 +4,27 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
 	"task-management-platform/backend/internal/models"
 )
 
+// ProjectRepository only sees live projects; deleted ones are reached
+// through TrashRepository.
 type ProjectRepository interface {
 	Create(ctx context.Context, project *models.Project) error
 	GetByID(ctx context.Context, id string) (*models.Project, error)
 	ListByOwner(ctx context.Context, ownerID string) ([]models.Project, error)
 	UpdateName(ctx context.Context, id string, name string) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context) ([]models.Project, error)
+	// List returns the archived projects, or with archived false the
+	// others.
+	List(ctx context.Context, archived bool) ([]models.Project, error)
+	// SetArchived archives or unarchives a project. Archiving an archived
+	// project keeps its original archived_at.
+	SetArchived(ctx context.Context, id string, archived bool) error
 }
 
 type projectRepository struct {
@@ -40,9 +48,9 @@ func (r *projectRepository) GetByID(ctx context.Context, id string) (*models.Pro
 	var p models.Project
 
 	query := `
-		SELECT id, name, owner_id, created_at
+		SELECT id, name, owner_id, created_at, archived_at
 		FROM projects
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	if err := r.db.GetContext(ctx, &p, query, id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -57,9 +65,9 @@ func (r *projectRepository) ListByOwner(ctx context.Context, ownerID string) ([]
 	projects := make([]models.Project, 0)
 
 	query := `
-		SELECT id, name, owner_id, created_at
+		SELECT id, name, owner_id, created_at, archived_at
 		FROM projects
-		WHERE owner_id = $1
+		WHERE owner_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 	if err := r.db.SelectContext(ctx, &projects, query, ownerID); err != nil {
@@ -72,7 +80,7 @@ func (r *projectRepository) UpdateName(ctx context.Context, id string, name stri
 	query := `
 		UPDATE projects
 		SET name = $2
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	res, err := r.db.ExecContext(ctx, query, id, name)
 	if err != nil {
@@ -88,32 +96,69 @@ func (r *projectRepository) UpdateName(ctx context.Context, id string, name stri
 	return nil
 }
 
+// Delete moves the project to the trash along with its live tasks. The tasks
+// share the project's deleted_at, which is how a restore tells them apart
+// from tasks that were deleted on their own.
 func (r *projectRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM projects WHERE id = $1`
-	res, err := r.db.ExecContext(ctx, query, id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	aff, err := res.RowsAffected()
-	if err != nil {
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	query := `
+		UPDATE projects
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING deleted_at
+	`
+	if err := tx.GetContext(ctx, &deletedAt, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
 		return err
 	}
-	if aff == 0 {
-		return ErrNotFound
+
+	query = `UPDATE tasks SET deleted_at = $2 WHERE project_id = $1 AND deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, id, deletedAt); err != nil {
+		return err
 	}
-	return nil
+	return tx.Commit()
 }
 
-func (r *projectRepository) List(ctx context.Context) ([]models.Project, error) {
+func (r *projectRepository) List(ctx context.Context, archived bool) ([]models.Project, error) {
 	projects := make([]models.Project, 0)
 
 	query := `
-		SELECT id, name, owner_id, created_at
+		SELECT id, name, owner_id, created_at, archived_at
 		FROM projects
+		WHERE deleted_at IS NULL
+		  AND (archived_at IS NOT NULL) = $1::boolean
 		ORDER BY created_at DESC
 	`
-	if err := r.db.SelectContext(ctx, &projects, query); err != nil {
+	if err := r.db.SelectContext(ctx, &projects, query, archived); err != nil {
 		return nil, err
 	}
 	return projects, nil
 }
+
+func (r *projectRepository) SetArchived(ctx context.Context, id string, archived bool) error {
+	query := `
+		UPDATE projects
+		SET archived_at = CASE WHEN $2::boolean THEN COALESCE(archived_at, NOW()) END
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, id, archived)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"task-management-platform/backend/internal/models"
+)
+
+type ProjectTransferRepository interface {
+	// Create offers the project to transfer.ToUserID, replacing any pending
+	// offer for the project.
+	Create(ctx context.Context, transfer *models.ProjectTransfer) error
+	GetByProject(ctx context.Context, projectID string) (*models.ProjectTransfer, error)
+	// ListForUser returns the pending transfers offered to userID or of
+	// projects it owns, newest first.
+	ListForUser(ctx context.Context, userID string) ([]models.ProjectTransfer, error)
+	Delete(ctx context.Context, projectID string) error
+	// Accept makes the recipient the owner and removes the offer. It fails
+	// with ErrNotFound when the offer is gone or the project changed owner
+	// since it was made.
+	Accept(ctx context.Context, projectID string, userID string) error
+}
+
+type projectTransferRepository struct {
+	db *sqlx.DB
+}
+
+func NewProjectTransferRepository(db *sqlx.DB) ProjectTransferRepository {
+	return &projectTransferRepository{db: db}
+}
+
+func (r *projectTransferRepository) Create(ctx context.Context, transfer *models.ProjectTransfer) error {
+	query := `
+		INSERT INTO project_transfers (project_id, from_user_id, to_user_id, requested_by, created_at)
+		VALUES (:project_id, :from_user_id, :to_user_id, :requested_by, :created_at)
+		ON CONFLICT (project_id) DO UPDATE
+		SET from_user_id = EXCLUDED.from_user_id,
+		    to_user_id = EXCLUDED.to_user_id,
+		    requested_by = EXCLUDED.requested_by,
+		    created_at = EXCLUDED.created_at
+	`
+	_, err := r.db.NamedExecContext(ctx, query, transfer)
+	return err
+}
+
+func (r *projectTransferRepository) GetByProject(ctx context.Context, projectID string) (*models.ProjectTransfer, error) {
+	var t models.ProjectTransfer
+
+	query := `
+		SELECT t.project_id, p.name AS project_name, t.from_user_id, t.to_user_id,
+		       t.requested_by, t.created_at
+		FROM project_transfers t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.project_id = $1 AND p.deleted_at IS NULL
+	`
+	if err := r.db.GetContext(ctx, &t, query, projectID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *projectTransferRepository) ListForUser(ctx context.Context, userID string) ([]models.ProjectTransfer, error) {
+	transfers := make([]models.ProjectTransfer, 0)
+
+	query := `
+		SELECT t.project_id, p.name AS project_name, t.from_user_id, t.to_user_id,
+		       t.requested_by, t.created_at
+		FROM project_transfers t
+		JOIN projects p ON p.id = t.project_id
+		WHERE p.deleted_at IS NULL
+		  AND (t.to_user_id = $1 OR p.owner_id = $1)
+		ORDER BY t.created_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &transfers, query, userID); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (r *projectTransferRepository) Delete(ctx context.Context, projectID string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM project_transfers WHERE project_id = $1`, projectID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *projectTransferRepository) Accept(ctx context.Context, projectID string, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var fromUserID string
+	query := `
+		DELETE FROM project_transfers
+		WHERE project_id = $1 AND to_user_id = $2
+		RETURNING from_user_id
+	`
+	if err := tx.GetContext(ctx, &fromUserID, query, projectID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	query = `
+		UPDATE projects
+		SET owner_id = $2
+		WHERE id = $1 AND owner_id = $3 AND deleted_at IS NULL
+	`
+	res, err := tx.ExecContext(ctx, query, projectID, userID, fromUserID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StatsRepository runs the aggregate queries behind the business metrics.
+type StatsRepository struct {
+	db *sqlx.DB
+}
+
+func NewStatsRepository(db *sqlx.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// TaskCountsByStatus returns how many live tasks exist in each status.
+// Statuses without tasks are absent from the map.
+func (r *StatsRepository) TaskCountsByStatus(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	query := `SELECT status, COUNT(*) AS count FROM tasks WHERE deleted_at IS NULL GROUP BY status`
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"strings"
 
 	"github.com/google/uuid"
@@ -18,13 +20,45 @@ type TaskFilters struct {
 	Offset     int
 }
 
+// TaskRepository only sees live tasks; deleted ones are reached through
+// TrashRepository.
 type TaskRepository interface {
 	Create(ctx context.Context, task *models.Task) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error)
 	List(ctx context.Context, filters TaskFilters) ([]models.Task, error)
+	// Update changes a task's fields; SetParent moves it.
 	Update(ctx context.Context, task *models.Task) error
+	// Delete moves the task to the trash together with its subtasks.
 	Delete(ctx context.Context, id uuid.UUID) error
-	ListAll(ctx context.Context) ([]models.Task, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.Task, error)
+	// ListSubtasks returns the direct subtasks of a task, oldest first.
+	ListSubtasks(ctx context.Context, parentID uuid.UUID) ([]models.Task, error)
+	// Ancestors returns the ids of a task's parent, grandparent and so on,
+	// nearest first.
+	Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	// SubtreeHeight counts the levels of subtasks below a task: 0 without
+	// subtasks, 1 when they have none of their own, and so on.
+	SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error)
+	// SetParent makes the task a subtask of parentID, or a top-level task
+	// when parentID is nil.
+	SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error
+	// CreateMany inserts tasks in one transaction, so either all of them are
+	// stored or none.
+	CreateMany(ctx context.Context, tasks []models.Task) error
+	// WithTree runs fn in a transaction holding the project's tree lock, so
+	// no other move, delete or subtask insert in the project lands between
+	// fn's checks and its write.
+	WithTree(ctx context.Context, projectID uuid.UUID, fn func(tree TaskTree) error) error
+}
+
+// TaskTree is the part of TaskRepository that checks and changes where a
+// task sits in the hierarchy.
+type TaskTree interface {
+	Create(ctx context.Context, task *models.Task) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error)
+	SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error
 }
 
 type taskRepository struct {
@@ -36,21 +70,51 @@ func NewTaskRepository(db *sqlx.DB) TaskRepository {
 }
 
 func (r *taskRepository) Create(ctx context.Context, task *models.Task) error {
+	return taskTree{q: r.db}.Create(ctx, task)
+}
+
+func (t taskTree) Create(ctx context.Context, task *models.Task) error {
 	if task.ID == uuid.Nil {
 		task.ID = uuid.New()
 	}
 	query := `
-		INSERT INTO tasks (id, project_id, title, description, status, assignee_id)
-		VALUES (:id, :project_id, :title, :description, :status, :assignee_id)
+		INSERT INTO tasks (id, project_id, parent_id, title, description, status, assignee_id, due_date)
+		VALUES (:id, :project_id, :parent_id, :title, :description, :status, :assignee_id, :due_date)
 	`
-	_, err := r.db.NamedExecContext(ctx, query, task)
+	_, err := sqlx.NamedExecContext(ctx, t.q, query, task)
 	return err
 }
 
+func (r *taskRepository) CreateMany(ctx context.Context, tasks []models.Task) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO tasks (id, project_id, parent_id, title, description, status, assignee_id, due_date)
+		VALUES (:id, :project_id, :parent_id, :title, :description, :status, :assignee_id, :due_date)
+	`
+	for i := range tasks {
+		if tasks[i].ID == uuid.Nil {
+			tasks[i].ID = uuid.New()
+		}
+		if _, err := tx.NamedExecContext(ctx, query, tasks[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	return taskTree{q: r.db}.GetByID(ctx, id)
+}
+
+func (t taskTree) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
 	var task models.Task
-	query := `SELECT * FROM tasks WHERE id = $1`
-	if err := r.db.GetContext(ctx, &task, query, id); err != nil {
+	query := `SELECT * FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+	if err := sqlx.GetContext(ctx, t.q, &task, query, id); err != nil {
 		return nil, err
 	}
 	return &task, nil
@@ -59,7 +123,7 @@ func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tas
 func (r *taskRepository) List(ctx context.Context, filters TaskFilters) ([]models.Task, error) {
 	var tasks []models.Task
 
-	conditions := []string{"1=1"}
+	conditions := []string{"deleted_at IS NULL"}
 	args := map[string]interface{}{}
 
 	if filters.ProjectID != nil {
@@ -110,28 +174,172 @@ func (r *taskRepository) Update(ctx context.Context, task *models.Task) error {
 		    description = :description,
 		    status = :status,
 		    assignee_id = :assignee_id,
+		    due_date = :due_date,
 		    updated_at = NOW()
-		WHERE id = :id
+		WHERE id = :id AND deleted_at IS NULL
 	`
 	_, err := r.db.NamedExecContext(ctx, query, task)
 	return err
 }
 
+// Delete stamps the task and its live subtasks with the same deleted_at, so
+// restoring the task brings back exactly those.
 func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = $1`, id)
-	return err
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID uuid.UUID
+	query := `SELECT project_id FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+	if err := tx.GetContext(ctx, &projectID, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	// a subtask moved or created concurrently would otherwise stay live under a
+	// deleted parent
+	if err := lockTree(ctx, tx, projectID); err != nil {
+		return err
+	}
+
+	query = `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT t.id
+			FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL
+		)
+		UPDATE tasks SET deleted_at = NOW()
+		WHERE id IN (SELECT id FROM subtree)
+	`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *taskRepository) ListAll(ctx context.Context) ([]models.Task, error) {
+// ListByProject returns every task of a project, unpaginated, for reports.
+func (r *taskRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.Task, error) {
 	tasks := make([]models.Task, 0)
 
 	query := `
-		SELECT id, project_id, title, description, status, assignee_id, created_at
+		SELECT id, project_id, parent_id, title, COALESCE(description, '') AS description,
+		       status, assignee_id, due_date, created_at, updated_at
 		FROM tasks
-		ORDER BY created_at DESC
+		WHERE project_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+	if err := r.db.SelectContext(ctx, &tasks, query, projectID); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepository) ListSubtasks(ctx context.Context, parentID uuid.UUID) ([]models.Task, error) {
+	tasks := make([]models.Task, 0)
+
+	query := `
+		SELECT id, project_id, parent_id, title, COALESCE(description, '') AS description,
+		       status, assignee_id, due_date, created_at, updated_at
+		FROM tasks
+		WHERE parent_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
 	`
-	if err := r.db.SelectContext(ctx, &tasks, query); err != nil {
+	if err := r.db.SelectContext(ctx, &tasks, query, parentID); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
+
+func (r *taskRepository) Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	return taskTree{q: r.db}.Ancestors(ctx, id)
+}
+
+func (t taskTree) Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+
+	// the depth bound stops the walk should the data ever hold a cycle
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id AS id, 1 AS depth FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT t.parent_id, a.depth + 1
+			FROM tasks t
+			JOIN ancestors a ON t.id = a.id
+			WHERE a.depth < 100
+		)
+		SELECT id FROM ancestors WHERE id IS NOT NULL ORDER BY depth
+	`
+	if err := sqlx.SelectContext(ctx, t.q, &ids, query, id); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *taskRepository) SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error) {
+	return taskTree{q: r.db}.SubtreeHeight(ctx, id)
+}
+
+func (t taskTree) SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error) {
+	var height int
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, 0 AS depth FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT t.id, s.depth + 1
+			FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL AND s.depth < 100
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM subtree
+	`
+	if err := sqlx.GetContext(ctx, t.q, &height, query, id); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func (r *taskRepository) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	return taskTree{q: r.db}.SetParent(ctx, id, parentID)
+}
+
+func (t taskTree) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	query := `UPDATE tasks SET parent_id = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := t.q.ExecContext(ctx, query, id, parentID)
+	return err
+}
+
+func (r *taskRepository) WithTree(ctx context.Context, projectID uuid.UUID, fn func(tree TaskTree) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockTree(ctx, tx, projectID); err != nil {
+		return err
+	}
+	if err := fn(taskTree{q: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// taskTree runs the hierarchy queries on the pool or inside a transaction.
+type taskTree struct {
+	q sqlx.ExtContext
+}
+
+// lockTree serialises changes to one project's task hierarchy until tx ends.
+// Row locks alone would not do: two moves in opposite directions touch
+// different rows and could each pass the cycle check.
+func lockTree(ctx context.Context, tx *sqlx.Tx, projectID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::text, 0))`, projectID)
+	return err
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"task-management-platform/backend/internal/models"
+)
+
+// TrashRepository reads and restores deleted projects and tasks, and purges
+// them for good once they have been in the trash long enough.
+type TrashRepository interface {
+	// ListProjects returns deleted projects, newest first, of one owner or,
+	// with an empty ownerID, of everyone.
+	ListProjects(ctx context.Context, ownerID string) ([]models.Project, error)
+	// ListTasks returns tasks deleted on their own, newest first, that are
+	// assigned to userID or belong to one of its projects; an empty userID
+	// lists everyone's. Tasks that went to the trash with their project or
+	// parent task are left out: they come back with it.
+	ListTasks(ctx context.Context, userID string) ([]models.Task, error)
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	GetTask(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	// RestoreProject brings the project back with the tasks that were
+	// deleted along with it.
+	RestoreProject(ctx context.Context, id string) error
+	// RestoreTask brings the task back with the subtasks that were deleted
+	// along with it.
+	RestoreTask(ctx context.Context, id uuid.UUID) error
+	// Purge permanently deletes projects and tasks deleted before cutoff.
+	Purge(ctx context.Context, cutoff time.Time) (TrashPurge, error)
+}
+
+// TrashPurge counts the rows one purge removed.
+type TrashPurge struct {
+	Projects int64 `json:"projects"`
+	Tasks    int64 `json:"tasks"`
+}
+
+type trashRepository struct {
+	db *sqlx.DB
+}
+
+func NewTrashRepository(db *sqlx.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) ListProjects(ctx context.Context, ownerID string) ([]models.Project, error) {
+	projects := make([]models.Project, 0)
+
+	query := `
+		SELECT id, name, owner_id, created_at, archived_at, deleted_at
+		FROM projects
+		WHERE deleted_at IS NOT NULL
+		  AND ($1 = '' OR owner_id::text = $1)
+		ORDER BY deleted_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &projects, query, ownerID); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *trashRepository) ListTasks(ctx context.Context, userID string) ([]models.Task, error) {
+	tasks := make([]models.Task, 0)
+
+	query := `
+		SELECT t.id, t.project_id, t.parent_id, t.title, COALESCE(t.description, '') AS description,
+		       t.status, t.assignee_id, t.due_date, t.created_at, t.updated_at, t.deleted_at
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.deleted_at IS NOT NULL
+		  AND p.deleted_at IS NULL
+		  AND NOT EXISTS (
+		        SELECT 1 FROM tasks pt
+		        WHERE pt.id = t.parent_id AND pt.deleted_at = t.deleted_at
+		      )
+		  AND ($1 = '' OR t.assignee_id::text = $1 OR p.owner_id::text = $1)
+		ORDER BY t.deleted_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &tasks, query, userID); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *trashRepository) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	var p models.Project
+
+	query := `
+		SELECT id, name, owner_id, created_at, archived_at, deleted_at
+		FROM projects
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	if err := r.db.GetContext(ctx, &p, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *trashRepository) GetTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	var t models.Task
+
+	query := `
+		SELECT id, project_id, parent_id, title, COALESCE(description, '') AS description,
+		       status, assignee_id, due_date, created_at, updated_at, deleted_at
+		FROM tasks
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	if err := r.db.GetContext(ctx, &t, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *trashRepository) RestoreProject(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	query := `SELECT deleted_at FROM projects WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`
+	if err := tx.GetContext(ctx, &deletedAt, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE projects SET deleted_at = NULL WHERE id = $1`, id); err != nil {
+		return err
+	}
+	query = `UPDATE tasks SET deleted_at = NULL WHERE project_id = $1 AND deleted_at = $2`
+	if _, err := tx.ExecContext(ctx, query, id, deletedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *trashRepository) RestoreTask(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	query := `SELECT deleted_at FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`
+	if err := tx.GetContext(ctx, &deletedAt, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	query = `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1
+			UNION
+			SELECT t.id
+			FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at = $2
+		)
+		UPDATE tasks SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM subtree)
+	`
+	if _, err := tx.ExecContext(ctx, query, id, deletedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *trashRepository) Purge(ctx context.Context, cutoff time.Time) (TrashPurge, error) {
+	var purged TrashPurge
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return purged, err
+	}
+	defer tx.Rollback()
+
+	// tasks first so the count includes those of purged projects, which the
+	// foreign key would otherwise remove silently
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM tasks t
+		USING projects p
+		WHERE p.id = t.project_id
+		  AND (t.deleted_at < $1 OR p.deleted_at < $1)
+	`, cutoff)
+	if err != nil {
+		return purged, err
+	}
+	if purged.Tasks, err = res.RowsAffected(); err != nil {
+		return purged, err
+	}
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM projects WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		return purged, err
+	}
+	if purged.Projects, err = res.RowsAffected(); err != nil {
+		return purged, err
+	}
+	return purged, tx.Commit()
+}
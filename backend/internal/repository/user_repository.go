@@ -6,12 +6,20 @@ import (
 	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"task-management-platform/backend/internal/models"
 )
 
 var ErrNotFound = errors.New("resource not found")
 
+// ErrEmailTaken reports an insert that hit the unique constraint on
+// users.email, which a check-then-insert cannot rule out under concurrency.
+var ErrEmailTaken = errors.New("email already in use")
+
+// ErrOwnsProjects stops deleting a user whose projects were not reassigned.
+var ErrOwnsProjects = errors.New("user owns projects")
+
 type UserRepository struct {
 	db *sqlx.DB
 }
@@ -21,11 +29,22 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	if user.Kind == "" {
+		user.Kind = models.UserKindHuman
+	}
 	query := `
-		INSERT INTO users (id, email, password_hash, role, created_at)
-		VALUES (:id, :email, :password_hash, :role, :created_at)
+		INSERT INTO users (id, email, password_hash, role, kind, created_at)
+		VALUES (:id, :email, :password_hash, :role, :kind, :created_at)
 	`
 	_, err := r.db.NamedExecContext(ctx, query, user)
+	return emailTaken(err)
+}
+
+func emailTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_email_key" {
+		return ErrEmailTaken
+	}
 	return err
 }
 
@@ -33,7 +52,8 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	var user models.User
 
 	query := `
-		SELECT id, email, password_hash, role, created_at
+		SELECT id, email, password_hash, role, kind, created_at,
+		       totp_secret, totp_enabled, totp_last_step
 		FROM users
 		WHERE email = $1
 	`
@@ -52,7 +72,8 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User,
 	var user models.User
 
 	query := `
-		SELECT id, email, password_hash, role, created_at
+		SELECT id, email, password_hash, role, kind, created_at,
+		       totp_secret, totp_enabled, totp_last_step
 		FROM users
 		WHERE id = $1
 	`
@@ -71,7 +92,8 @@ func (r *UserRepository) List(ctx context.Context) ([]models.User, error) {
 	users := make([]models.User, 0)
 
 	query := `
-		SELECT id, email, password_hash, role, created_at
+		SELECT id, email, password_hash, role, kind, created_at,
+		       totp_secret, totp_enabled, totp_last_step
 		FROM users
 		ORDER BY created_at DESC
 	`
@@ -90,7 +112,7 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	`
 	res, err := r.db.NamedExecContext(ctx, query, user)
 	if err != nil {
-		return err
+		return emailTaken(err)
 	}
 	aff, err := res.RowsAffected()
 	if err != nil {
@@ -102,9 +124,33 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	res, err := r.db.ExecContext(ctx, query, id)
+// Delete removes a user. Their projects, including those in the trash, go
+// to newOwnerID first; with an empty newOwnerID a user who owns projects is
+// not deleted and ErrOwnsProjects is returned.
+func (r *UserRepository) Delete(ctx context.Context, id string, newOwnerID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if newOwnerID != "" {
+		query := `UPDATE projects SET owner_id = $2 WHERE owner_id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, newOwnerID); err != nil {
+			return err
+		}
+	} else {
+		var owns bool
+		query := `SELECT EXISTS (SELECT 1 FROM projects WHERE owner_id = $1)`
+		if err := tx.GetContext(ctx, &owns, query, id); err != nil {
+			return err
+		}
+		if owns {
+			return ErrOwnsProjects
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
@@ -115,7 +161,7 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	if aff == 0 {
 		return ErrNotFound
 	}
-	return nil
+	return tx.Commit()
 }
 
 func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id string, passwordHash string) error {
@@ -137,3 +183,248 @@ func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id string, pass
 	}
 	return nil
 }
+
+// SetTOTPSecret stores a pending secret. Two-factor stays disabled until
+// EnableTOTP is called after the user proves they can generate codes.
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, id string, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $2,
+		    totp_enabled = false,
+		    totp_last_step = 0
+		WHERE id = $1
+	`
+	res, err := r.db.ExecContext(ctx, query, id, secret)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListPlainTOTPSecrets returns the secrets that have not been sealed yet,
+// keyed by user id.
+func (r *UserRepository) ListPlainTOTPSecrets(ctx context.Context) (map[string]string, error) {
+	var rows []struct {
+		ID     string `db:"id"`
+		Secret string `db:"totp_secret"`
+	}
+	query := `
+		SELECT id, totp_secret
+		FROM users
+		WHERE totp_secret IS NOT NULL AND totp_secret NOT LIKE 'v1:%'
+	`
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]string, len(rows))
+	for _, row := range rows {
+		secrets[row.ID] = row.Secret
+	}
+	return secrets, nil
+}
+
+// ReplaceTOTPSecret rewrites the secret only while it still equals old, so a
+// concurrent enrollment or another instance's rewrite is left alone.
+func (r *UserRepository) ReplaceTOTPSecret(ctx context.Context, id, old, new string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET totp_secret = $3 WHERE id = $1 AND totp_secret = $2`, id, old, new)
+	return err
+}
+
+// EnableTOTP turns two-factor on and replaces the recovery codes in one
+// transaction, so an enabled account always has a fresh set.
+func (r *UserRepository) EnableTOTP(ctx context.Context, id string, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET totp_enabled = true,
+		    totp_last_step = $2
+		WHERE id = $1 AND totp_secret IS NOT NULL
+	`, id, step)
+	if err != nil {
+		return err
+	}
+	if aff, err := res.RowsAffected(); err != nil {
+		return err
+	} else if aff == 0 {
+		return ErrNotFound
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, id, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableTOTP clears the secret and every recovery code.
+func (r *UserRepository) DisableTOTP(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET totp_secret = NULL,
+		    totp_enabled = false,
+		    totp_last_step = 0
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+	if aff, err := res.RowsAffected(); err != nil {
+		return err
+	} else if aff == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AdvanceTOTPStep records step as the last accepted one. It returns false when
+// step is not newer than the stored one, meaning the code was replayed.
+func (r *UserRepository) AdvanceTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND totp_last_step < $2
+	`
+	res, err := r.db.ExecContext(ctx, query, id, step)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff == 1, nil
+}
+
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks the matching unused code as used. It returns false
+// when no unused code matches.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff > 0, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`,
+			userID, h,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByIdentity returns the user linked to an external identity provider
+// account.
+func (r *UserRepository) GetByIdentity(ctx context.Context, issuer, subject string) (*models.User, error) {
+	var user models.User
+
+	query := `
+		SELECT u.id, u.email, u.password_hash, u.role, u.kind, u.created_at,
+		       u.totp_secret, u.totp_enabled, u.totp_last_step
+		FROM users u
+		JOIN user_identities i ON i.user_id = u.id
+		WHERE i.issuer = $1 AND i.subject = $2
+	`
+	err := r.db.GetContext(ctx, &user, query, issuer, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// LinkIdentity attaches an external identity to an existing user.
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID, issuer, subject string) error {
+	query := `
+		INSERT INTO user_identities (issuer, subject, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (issuer, subject) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, issuer, subject, userID)
+	return err
+}
+
+// CreateWithIdentity provisions a user and links the external identity in one
+// transaction.
+func (r *UserRepository) CreateWithIdentity(ctx context.Context, user *models.User, issuer, subject string) error {
+	if user.Kind == "" {
+		user.Kind = models.UserKindHuman
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExecContext(ctx, `
+		INSERT INTO users (id, email, password_hash, role, kind, created_at)
+		VALUES (:id, :email, :password_hash, :role, :kind, :created_at)
+	`, user); err != nil {
+		return emailTaken(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO user_identities (issuer, subject, user_id)
+		VALUES ($1, $2, $3)
+	`, issuer, subject, user.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
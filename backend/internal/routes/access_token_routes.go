@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/server/middleware"
+)
+
+// RegisterAccessTokenRoutes exposes token management to interactive sessions
+// only, so a leaked token cannot mint more tokens.
+func RegisterAccessTokenRoutes(r *gin.Engine, h *handlers.AccessTokenHandler, protected, admin gin.HandlersChain) {
+	own := r.Group("/auth/tokens", protected...)
+	own.Use(middleware.SessionOnly())
+	{
+		own.GET("", h.List)
+		own.POST("", h.Create)
+		own.DELETE("/:tokenId", h.Revoke)
+	}
+
+	// admins manage the tokens of service accounts
+	users := r.Group("/users/:id/tokens", admin...)
+	users.Use(middleware.SessionOnly())
+	{
+		users.GET("", h.List)
+		users.POST("", h.Create)
+	}
+}
@@ -4,13 +4,21 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"task-management-platform/backend/internal/handlers"
-	"task-management-platform/backend/internal/server/middleware"
 )
 
-func RegisterAdminRoutes(r *gin.Engine, exportHandler *handlers.AdminExportHandler) {
+func RegisterAdminRoutes(r *gin.Engine, exportHandler *handlers.AdminExportHandler, importHandler *handlers.AdminImportHandler, adminOnly gin.HandlersChain) {
 	admin := r.Group("/api/admin")
-	admin.Use(middleware.AuthRequired(), middleware.RequireRole("admin"))
+	admin.Use(adminOnly...)
 	{
 		admin.GET("/export", exportHandler.ExportAll)
+		admin.POST("/exports", exportHandler.StartExport)
+		admin.GET("/exports/:id", exportHandler.ExportStatus)
+		if importHandler != nil {
+			admin.POST("/import", importHandler.ImportAll)
+		}
 	}
+
+	// the signed link is the credential, so downloads work from a plain
+	// browser navigation without an Authorization header
+	r.GET("/api/admin/exports/:id/download", exportHandler.DownloadExport)
 }
@@ -2,14 +2,15 @@ package routes
 
 import (
 	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/models"
 	"task-management-platform/backend/internal/server/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterAPIUserRoutes(r *gin.Engine, h *handlers.APIUserHandler) {
+func RegisterAPIUserRoutes(r *gin.Engine, h *handlers.APIUserHandler, protected gin.HandlersChain) {
 	api := r.Group("/api")
-	api.Use(middleware.AuthRequired())
+	api.Use(protected...)
 
-	api.GET("/users", h.ListMinimal)
+	api.GET("/users", middleware.RequireScope(models.ScopeUsersRead), h.ListMinimal)
 }
@@ -9,31 +9,42 @@ import (
 	"task-management-platform/backend/internal/server/middleware"
 )
 
-func RegisterAuthRoutes(r *gin.Engine, authHandler *handlers.AuthHandler) {
+func RegisterAuthRoutes(r *gin.Engine, authHandler *handlers.AuthHandler, credentials, protected, admin gin.HandlersChain) {
+	// resource servers fetch the token verification keys from here
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	auth := r.Group("/auth")
+
+	// credential endpoints get the stricter per-IP auth policy
+	public := auth.Group("", credentials...)
+	{
+		public.POST("/register", authHandler.Register)
+		public.POST("/login", authHandler.Login)
+		public.POST("/login/2fa", authHandler.CompleteTwoFactorLogin)
+	}
+
+	authed := auth.Group("", protected...)
 	{
-		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
-		auth.POST("/change-password", middleware.AuthRequired(), authHandler.ChangePassword)
-		auth.GET("/me", middleware.AuthRequired(), func(c *gin.Context) {
-			userID, _ := c.Get(middleware.ContextUserIDKey)
-			role, _ := c.Get(middleware.ContextRoleKey)
+		authed.GET("/me", authHandler.Me)
+	}
 
+	// account management stays out of reach of personal access tokens
+	session := authed.Group("", middleware.SessionOnly())
+	{
+		session.POST("/change-password", authHandler.ChangePassword)
+		session.POST("/2fa/setup", authHandler.BeginTwoFactorSetup)
+		session.POST("/2fa/confirm", authHandler.ConfirmTwoFactorSetup)
+		session.POST("/2fa/disable", authHandler.DisableTwoFactor)
+		session.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	}
+
+	adminOnly := auth.Group("/admin", admin...)
+	{
+		adminOnly.GET("/ping", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
-				"userId": userID,
-				"role":   role,
+				"status": "admin ok",
 			})
 		})
-		auth.GET(
-			"/admin/ping",
-			middleware.AuthRequired(),
-			middleware.RequireRole("admin"),
-			func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"status": "admin ok",
-				})
-			},
-		)
-
+		adminOnly.POST("/users/:id/unlock", authHandler.UnlockAccount)
 	}
 }
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/server/middleware"
+)
+
+// CalendarFeedPath is the public feed route. Its path holds the feed secret,
+// so it is kept out of access logs and traces.
+const CalendarFeedPath = "/api/calendar/:token"
+
+// RegisterCalendarRoutes exposes feed management to interactive sessions and
+// the feeds themselves publicly: the secret in the URL is the credential.
+func RegisterCalendarRoutes(r *gin.Engine, h *handlers.CalendarHandler, protected gin.HandlersChain) {
+	feeds := r.Group("/api/calendar/feeds", protected...)
+	feeds.Use(middleware.SessionOnly())
+	{
+		feeds.GET("", h.List)
+		feeds.POST("", h.Create)
+		feeds.DELETE("/:feedId", h.Revoke)
+	}
+
+	// gin params cannot carry a suffix, so the handler strips ".ics"
+	r.GET(CalendarFeedPath, h.Feed)
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/openapi"
+	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
+)
+
+// newTestRouter registers the routes of deps the way the server does, with
+// requests and responses checked against the OpenAPI document. A response
+// that does not match fails the test.
+func newTestRouter(t *testing.T, deps Dependencies) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	jwttest.Configure()
+
+	validate, err := handlers.APISpec().Validator(openapi.Options{
+		Responses: true,
+		OnResponseError: func(c *gin.Context, err error) {
+			t.Errorf("response does not match the OpenAPI document: %v", err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Validator error = %v", err)
+	}
+	deps.Validate = validate
+
+	r := gin.New()
+	Register(r, deps)
+	return r
+}
+
+// sessionToken signs a session token for userID.
+func sessionToken(t *testing.T, userID, role string) string {
+	t.Helper()
+
+	token, err := jwtutil.GenerateToken(userID, role)
+	if err != nil {
+		t.Fatalf("GenerateToken error = %v", err)
+	}
+	return token
+}
+
+// serve sends a JSON request with the bearer token and records the response.
+func serve(r http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, path, nil)
+	} else {
+		req = httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
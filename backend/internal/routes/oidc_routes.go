@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/server/middleware"
+)
+
+func RegisterOIDCRoutes(r *gin.Engine, h *handlers.OIDCHandler, credentials, protected gin.HandlersChain) {
+	oidc := r.Group("/auth/oidc", credentials...)
+	{
+		oidc.GET("/login", h.Login)
+		oidc.GET("/link", h.Link)
+		oidc.GET("/callback", h.Callback)
+	}
+
+	// linking is account management, so personal access tokens cannot start it
+	session := r.Group("/auth/oidc", protected...).Group("", middleware.SessionOnly())
+	{
+		session.POST("/link", h.LinkTicket)
+	}
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/openapi"
+)
+
+func TestAPISpec_DescribesEveryRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	Register(r, Dependencies{
+		AuthHandler:        &handlers.AuthHandler{},
+		UserHandler:        &handlers.UserHandler{},
+		ProjectHandler:     &handlers.ProjectHandler{},
+		ReportHandler:      &handlers.ReportHandler{},
+		TaskHandler:        &handlers.TaskHandler{},
+		TaskImportHandler:  &handlers.TaskImportHandler{},
+		APIUserHandler:     &handlers.APIUserHandler{},
+		AdminExportHandler: &handlers.AdminExportHandler{},
+		AdminImportHandler: &handlers.AdminImportHandler{},
+		OIDCHandler:        &handlers.OIDCHandler{},
+		AccessTokenHandler: &handlers.AccessTokenHandler{},
+		CalendarHandler:    &handlers.CalendarHandler{},
+		TrashHandler:       &handlers.TrashHandler{},
+		TransferHandler:    &handlers.ProjectTransferHandler{},
+	})
+
+	spec := handlers.APISpec()
+	registered := map[string]bool{}
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := spec.Operation(route.Method, route.Path); !ok {
+			t.Errorf("%s is not in the OpenAPI document", key)
+		}
+	}
+
+	// the probes are registered by the server, outside Register
+	probes := map[string]bool{"GET /livez": true, "GET /readyz": true, "GET /health": true}
+	for _, key := range spec.Routes() {
+		if !registered[key] && !probes[key] {
+			t.Errorf("the OpenAPI document describes %s, which is not registered", key)
+		}
+	}
+}
+
+func TestAPISpec_Compiles(t *testing.T) {
+	if _, err := handlers.APISpec().Validator(openapi.Options{}); err != nil {
+		t.Fatalf("Validator error = %v", err)
+	}
+}
@@ -7,9 +7,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterProjectRoutes(r *gin.Engine, h *handlers.ProjectHandler) {
+func RegisterProjectRoutes(r *gin.Engine, h *handlers.ProjectHandler, reports *handlers.ReportHandler, protected gin.HandlersChain) {
 	api := r.Group("/api")
-	api.Use(middleware.AuthRequired())
+	api.Use(protected...)
+	api.Use(middleware.RequireResourceScope("projects"))
 
 	h.Register(api)
+
+	if reports != nil {
+		api.GET("/projects/:id/report.pdf", reports.ProjectPDF)
+	}
 }
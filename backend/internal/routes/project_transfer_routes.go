@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/server/middleware"
+)
+
+// RegisterProjectTransferRoutes lets owners and admins offer a project to
+// another user, who accepts or declines it.
+func RegisterProjectTransferRoutes(r *gin.Engine, h *handlers.ProjectTransferHandler, protected gin.HandlersChain) {
+	api := r.Group("/api", protected...)
+	api.Use(middleware.RequireResourceScope("projects"))
+
+	api.GET("/project-transfers", h.List)
+	api.POST("/projects/:id/transfer", h.Offer)
+	api.POST("/projects/:id/transfer/accept", h.Accept)
+	api.DELETE("/projects/:id/transfer", h.Cancel)
+}
@@ -4,39 +4,137 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/server/middleware"
 )
 
 type Dependencies struct {
 	AuthHandler        *handlers.AuthHandler
 	UserHandler        *handlers.UserHandler
 	ProjectHandler     *handlers.ProjectHandler
+	ReportHandler      *handlers.ReportHandler
 	TaskHandler        *handlers.TaskHandler
+	TaskImportHandler  *handlers.TaskImportHandler
 	APIUserHandler     *handlers.APIUserHandler
 	AdminExportHandler *handlers.AdminExportHandler
+	// OIDCHandler is nil unless single sign-on is configured.
+	OIDCHandler        *handlers.OIDCHandler
+	AccessTokenHandler *handlers.AccessTokenHandler
+	CalendarHandler    *handlers.CalendarHandler
+	AdminImportHandler *handlers.AdminImportHandler
+	TrashHandler       *handlers.TrashHandler
+	TransferHandler    *handlers.ProjectTransferHandler
+
+	// Tokens lets AuthRequired accept personal access tokens; nil accepts
+	// JWTs only.
+	Tokens middleware.TokenAuthenticator
+
+	// RateLimits provides the named policies attached to route groups; nil
+	// disables per-group limits.
+	RateLimits *middleware.RateLimitPolicies
+
+	// RequireAdminMFA makes admin-only routes reject sessions that were not
+	// established with a second factor.
+	RequireAdminMFA bool
+
+	// Validate checks authenticated requests against the OpenAPI document
+	// once the caller is known; nil skips validation.
+	Validate gin.HandlerFunc
 }
 
+// Rate limit policies referenced by the route groups. The server defines
+// their limits.
+const (
+	// PolicyAuth guards unauthenticated credential endpoints, per IP.
+	PolicyAuth = "auth"
+	// PolicyUser throttles authenticated requests per user.
+	PolicyUser = "user"
+)
+
 func Register(r *gin.Engine, deps Dependencies) {
+	protected := deps.protected()
+	admin := deps.admin()
+
 	if deps.AuthHandler != nil {
-		RegisterAuthRoutes(r, deps.AuthHandler)
+		RegisterAuthRoutes(r, deps.AuthHandler, deps.policy(PolicyAuth), protected, admin)
+	}
+
+	if deps.OIDCHandler != nil {
+		RegisterOIDCRoutes(r, deps.OIDCHandler, deps.policy(PolicyAuth), protected)
 	}
 
 	if deps.UserHandler != nil {
-		RegisterUserRoutes(r, deps.UserHandler)
+		RegisterUserRoutes(r, deps.UserHandler, admin)
 	}
 
 	if deps.ProjectHandler != nil {
-		RegisterProjectRoutes(r, deps.ProjectHandler)
+		RegisterProjectRoutes(r, deps.ProjectHandler, deps.ReportHandler, protected)
 	}
 
 	if deps.TaskHandler != nil {
-		RegisterTaskRoutes(r, deps.TaskHandler)
+		RegisterTaskRoutes(r, deps.TaskHandler, deps.TaskImportHandler, protected)
+	}
+
+	if deps.TransferHandler != nil {
+		RegisterProjectTransferRoutes(r, deps.TransferHandler, protected)
+	}
+
+	if deps.TrashHandler != nil {
+		RegisterTrashRoutes(r, deps.TrashHandler, protected)
 	}
 
 	if deps.APIUserHandler != nil {
-		RegisterAPIUserRoutes(r, deps.APIUserHandler)
+		RegisterAPIUserRoutes(r, deps.APIUserHandler, protected)
 	}
 
 	if deps.AdminExportHandler != nil {
-		RegisterAdminRoutes(r, deps.AdminExportHandler)
+		RegisterAdminRoutes(r, deps.AdminExportHandler, deps.AdminImportHandler, admin)
+	}
+
+	if deps.AccessTokenHandler != nil {
+		RegisterAccessTokenRoutes(r, deps.AccessTokenHandler, protected, admin)
+	}
+
+	if deps.CalendarHandler != nil {
+		RegisterCalendarRoutes(r, deps.CalendarHandler, protected)
+	}
+}
+
+// protected is the middleware chain every authenticated route group starts
+// with. The user policy runs after AuthRequired so the user id is known.
+func (deps Dependencies) protected() gin.HandlersChain {
+	return deps.validated(deps.authenticated())
+}
+
+// admin is the chain of admin-only route groups. Access tokens need the
+// admin scope on top of an admin owner.
+func (deps Dependencies) admin() gin.HandlersChain {
+	chain := append(deps.authenticated(),
+		middleware.RequireRole("admin"),
+		middleware.RequireScope(models.ScopeAdmin),
+	)
+	if deps.RequireAdminMFA {
+		chain = append(chain, middleware.RequireMFA())
+	}
+	return deps.validated(chain)
+}
+
+func (deps Dependencies) authenticated() gin.HandlersChain {
+	return append(gin.HandlersChain{middleware.AuthRequired(deps.Tokens)}, deps.policy(PolicyUser)...)
+}
+
+// validated ends chain with request validation, so callers that are not
+// allowed in learn nothing about the expected request shape.
+func (deps Dependencies) validated(chain gin.HandlersChain) gin.HandlersChain {
+	if deps.Validate == nil {
+		return chain
+	}
+	return append(chain, deps.Validate)
+}
+
+func (deps Dependencies) policy(name string) gin.HandlersChain {
+	if deps.RateLimits == nil {
+		return nil
 	}
+	return gin.HandlersChain{deps.RateLimits.Handler(name)}
 }
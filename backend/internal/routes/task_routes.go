@@ -7,14 +7,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterTaskRoutes(r *gin.Engine, h *handlers.TaskHandler) {
+func RegisterTaskRoutes(r *gin.Engine, h *handlers.TaskHandler, imports *handlers.TaskImportHandler, protected gin.HandlersChain) {
 	api := r.Group("/api")
-	api.Use(middleware.AuthRequired())
+	api.Use(protected...)
+	api.Use(middleware.RequireResourceScope("tasks"))
 
 	api.POST("/projects/:id/tasks", h.Create)
 	api.GET("/projects/:id/tasks", h.ListByProject)
+	if imports != nil {
+		api.POST("/projects/:id/tasks/import", imports.Import)
+	}
 
 	api.GET("/tasks/:id", h.GetByID)
 	api.PUT("/tasks/:id", h.Update)
 	api.DELETE("/tasks/:id", h.Delete)
+	api.PUT("/tasks/:id/parent", h.Move)
+
+	api.POST("/tasks/:id/checklist", h.AddChecklistItem)
+	api.PUT("/tasks/:id/checklist/:itemId", h.UpdateChecklistItem)
+	api.DELETE("/tasks/:id/checklist/:itemId", h.DeleteChecklistItem)
 }
@@ -0,0 +1,262 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/services"
+)
+
+// fakeTaskService records what the handlers pass on. Methods a test does not
+// stub fall through to the nil interface and panic.
+type fakeTaskService struct {
+	services.TaskService
+
+	err error
+
+	updated     *models.Task
+	keepDueDate bool
+
+	detail *models.TaskDetail
+	moved  *uuid.UUID
+	item   *models.ChecklistItem
+	itemID uuid.UUID
+}
+
+func (s *fakeTaskService) Update(ctx context.Context, actor models.User, task *models.Task, keepDueDate bool) error {
+	s.updated, s.keepDueDate = task, keepDueDate
+	return s.err
+}
+
+func (s *fakeTaskService) GetDetail(ctx context.Context, actor models.User, id uuid.UUID) (*models.TaskDetail, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.detail, nil
+}
+
+func (s *fakeTaskService) Move(ctx context.Context, actor models.User, id uuid.UUID, parentID *uuid.UUID) (*models.Task, error) {
+	s.moved = parentID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.Task{ID: id, ParentID: parentID, Title: "t", Status: "todo"}, nil
+}
+
+func (s *fakeTaskService) AddChecklistItem(ctx context.Context, actor models.User, taskID uuid.UUID, title string) (*models.ChecklistItem, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.item = &models.ChecklistItem{ID: uuid.New(), TaskID: taskID, Title: title}
+	return s.item, nil
+}
+
+func (s *fakeTaskService) UpdateChecklistItem(ctx context.Context, actor models.User, item *models.ChecklistItem) error {
+	s.item = item
+	return s.err
+}
+
+func (s *fakeTaskService) DeleteChecklistItem(ctx context.Context, actor models.User, taskID, itemID uuid.UUID) error {
+	s.itemID = itemID
+	return s.err
+}
+
+func newTaskTestRouter(t *testing.T, svc *fakeTaskService) (http.Handler, string) {
+	t.Helper()
+
+	r := newTestRouter(t, Dependencies{TaskHandler: handlers.NewTaskHandler(svc)})
+	return r, sessionToken(t, uuid.NewString(), "admin")
+}
+
+func TestTaskRoutes_UpdateKeepsDueDateUnlessSent(t *testing.T) {
+	svc := &fakeTaskService{}
+	r, token := newTaskTestRouter(t, svc)
+	path := "/api/tasks/" + uuid.NewString()
+
+	cases := []struct {
+		name     string
+		body     string
+		keep     bool
+		wantDate string
+	}{
+		{"omitted", `{"title":"t","status":"todo"}`, true, ""},
+		{"null", `{"title":"t","status":"todo","dueDate":null}`, false, ""},
+		{"set", `{"title":"t","status":"todo","dueDate":"2025-03-01"}`, false, "2025-03-01"},
+	}
+	for _, tc := range cases {
+		w := serve(r, http.MethodPut, path, token, tc.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200. body=%s", tc.name, w.Code, w.Body.String())
+		}
+		if svc.keepDueDate != tc.keep {
+			t.Fatalf("%s: keepDueDate = %v, want %v", tc.name, svc.keepDueDate, tc.keep)
+		}
+		got := ""
+		if svc.updated.DueDate != nil {
+			got = svc.updated.DueDate.Format("2006-01-02")
+		}
+		if got != tc.wantDate {
+			t.Fatalf("%s: due date = %q, want %q", tc.name, got, tc.wantDate)
+		}
+	}
+}
+
+func TestTaskRoutes_InvalidAssigneeNamesCamelCaseField(t *testing.T) {
+	r, token := newTaskTestRouter(t, &fakeTaskService{})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"update", http.MethodPut, "/api/tasks/" + uuid.NewString(), `{"title":"t","status":"todo","assigneeId":"nope"}`},
+		{"create", http.MethodPost, "/api/projects/" + uuid.NewString() + "/tasks", `{"title":"t","assigneeId":"nope"}`},
+		{"list", http.MethodGet, "/api/projects/" + uuid.NewString() + "/tasks?assigneeId=nope", ""},
+		{"list legacy", http.MethodGet, "/api/projects/" + uuid.NewString() + "/tasks?assignee_id=nope", ""},
+	}
+	for _, tc := range cases {
+		w := serve(r, tc.method, tc.path, token, tc.body)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400. body=%s", tc.name, w.Code, w.Body.String())
+		}
+		if fields := invalidFields(t, w.Body.Bytes()); !reflect.DeepEqual(fields, []string{"assigneeId"}) {
+			t.Fatalf("%s: invalid fields = %v, want [assigneeId]", tc.name, fields)
+		}
+	}
+}
+
+// invalidFields lists the fields a validation problem names.
+func invalidFields(t *testing.T, body []byte) []string {
+	t.Helper()
+
+	var problem struct {
+		Errors []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &problem); err != nil {
+		t.Fatalf("decode body %q: %v", body, err)
+	}
+	fields := make([]string, 0, len(problem.Errors))
+	for _, e := range problem.Errors {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
+
+func TestTaskRoutes_ValidatesOnlyAuthenticatedRequests(t *testing.T) {
+	r, _ := newTaskTestRouter(t, &fakeTaskService{})
+
+	w := serve(r, http.MethodPut, "/api/tasks/"+uuid.NewString(), "", `{"title":""}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401 before validation. body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestTaskRoutes_GetReturnsDetail(t *testing.T) {
+	id := uuid.New()
+	svc := &fakeTaskService{detail: &models.TaskDetail{
+		Task:              models.Task{ID: id, ProjectID: uuid.New(), Title: "Parent", Status: "todo"},
+		Subtasks:          []models.Task{{ID: uuid.New(), ProjectID: uuid.New(), ParentID: &id, Title: "Child", Status: "done"}},
+		SubtaskProgress:   models.Progress{Done: 1, Total: 1},
+		Checklist:         []models.ChecklistItem{{ID: uuid.New(), TaskID: id, Title: "Step", Position: 1}},
+		ChecklistProgress: models.Progress{Total: 1},
+	}}
+	r, token := newTaskTestRouter(t, svc)
+
+	w := serve(r, http.MethodGet, "/api/tasks/"+id.String(), token, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200. body=%s", w.Code, w.Body.String())
+	}
+	var got models.TaskDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if got.ID != id || len(got.Subtasks) != 1 || len(got.Checklist) != 1 ||
+		got.SubtaskProgress != (models.Progress{Done: 1, Total: 1}) || got.ChecklistProgress != (models.Progress{Total: 1}) {
+		t.Fatalf("detail = %+v", got)
+	}
+}
+
+func TestTaskRoutes_MoveParsesParent(t *testing.T) {
+	svc := &fakeTaskService{}
+	r, token := newTaskTestRouter(t, svc)
+	path := "/api/tasks/" + uuid.NewString() + "/parent"
+	parentID := uuid.New()
+
+	w := serve(r, http.MethodPut, path, token, `{"parentId":"nope"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid parent: status = %d, want 400. body=%s", w.Code, w.Body.String())
+	}
+	if fields := invalidFields(t, w.Body.Bytes()); !reflect.DeepEqual(fields, []string{"parentId"}) {
+		t.Fatalf("invalid parent: fields = %v, want [parentId]", fields)
+	}
+
+	w = serve(r, http.MethodPut, path, token, `{"parentId":"`+parentID.String()+`"}`)
+	if w.Code != http.StatusOK || svc.moved == nil || *svc.moved != parentID {
+		t.Fatalf("move: status = %d, parent = %v; want 200, %s. body=%s", w.Code, svc.moved, parentID, w.Body.String())
+	}
+
+	w = serve(r, http.MethodPut, path, token, `{"parentId":null}`)
+	if w.Code != http.StatusOK || svc.moved != nil {
+		t.Fatalf("top level: status = %d, parent = %v; want 200, nil. body=%s", w.Code, svc.moved, w.Body.String())
+	}
+}
+
+func TestTaskRoutes_ChecklistItemIDMustBeUUID(t *testing.T) {
+	svc := &fakeTaskService{}
+	r, token := newTaskTestRouter(t, svc)
+	base := "/api/tasks/" + uuid.NewString() + "/checklist/"
+	itemID := uuid.New()
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		w := serve(r, method, base+"nope", token, `{"title":"Step","done":true}`)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400. body=%s", method, w.Code, w.Body.String())
+		}
+		if fields := invalidFields(t, w.Body.Bytes()); !reflect.DeepEqual(fields, []string{"itemId"}) {
+			t.Fatalf("%s: fields = %v, want [itemId]", method, fields)
+		}
+	}
+
+	w := serve(r, http.MethodPut, base+itemID.String(), token, `{"title":"Step","done":true}`)
+	if w.Code != http.StatusOK || svc.item == nil || svc.item.ID != itemID || !svc.item.Done {
+		t.Fatalf("update: status = %d, item = %+v. body=%s", w.Code, svc.item, w.Body.String())
+	}
+	w = serve(r, http.MethodDelete, base+itemID.String(), token, "")
+	if w.Code != http.StatusNoContent || svc.itemID != itemID {
+		t.Fatalf("delete: status = %d, item id = %s. body=%s", w.Code, svc.itemID, w.Body.String())
+	}
+}
+
+func TestTaskRoutes_ArchivedProjectConflicts(t *testing.T) {
+	r, token := newTaskTestRouter(t, &fakeTaskService{err: services.ErrProjectArchived})
+	task := "/api/tasks/" + uuid.NewString()
+	item := task + "/checklist/" + uuid.NewString()
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"move", http.MethodPut, task + "/parent", `{"parentId":null}`},
+		{"add item", http.MethodPost, task + "/checklist", `{"title":"Step"}`},
+		{"update item", http.MethodPut, item, `{"title":"Step","done":true}`},
+		{"delete item", http.MethodDelete, item, ""},
+	}
+	for _, tc := range cases {
+		w := serve(r, tc.method, tc.path, token, tc.body)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("%s: status = %d, want 409. body=%s", tc.name, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/server/middleware"
+)
+
+// RegisterTrashRoutes exposes deleted projects and tasks. Restoring needs
+// the same scope as deleting; listing needs read access to both.
+func RegisterTrashRoutes(r *gin.Engine, h *handlers.TrashHandler, protected gin.HandlersChain) {
+	api := r.Group("/api", protected...)
+
+	api.GET("/trash", middleware.RequireResourceScope("projects"), middleware.RequireResourceScope("tasks"), h.List)
+	api.POST("/projects/:id/restore", middleware.RequireResourceScope("projects"), h.RestoreProject)
+	api.POST("/tasks/:id/restore", middleware.RequireResourceScope("tasks"), h.RestoreTask)
+}
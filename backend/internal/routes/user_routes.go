@@ -2,19 +2,18 @@ package routes
 
 import (
 	"task-management-platform/backend/internal/handlers"
-	"task-management-platform/backend/internal/server/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterUserRoutes(r *gin.Engine, h *handlers.UserHandler) {
+func RegisterUserRoutes(r *gin.Engine, h *handlers.UserHandler, admin gin.HandlersChain) {
 	users := r.Group("/users")
-	users.Use(middleware.AuthRequired())
-	users.Use(middleware.RequireRole("admin"))
+	users.Use(admin...)
 
 	users.GET("", h.List)
 	users.GET("/:id", h.GetByID)
 	users.POST("", h.Create)
+	users.POST("/service-accounts", h.CreateServiceAccount)
 	users.PUT("/:id", h.Update)
 	users.DELETE("/:id", h.Delete)
 }
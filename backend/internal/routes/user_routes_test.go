@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/services"
+)
+
+// fakeUserAdminRepo holds users and how many projects each owns.
+type fakeUserAdminRepo struct {
+	services.UserAdminRepo
+
+	users map[string]bool
+	owned map[string]int
+}
+
+func (r *fakeUserAdminRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	if !r.users[id] {
+		return nil, repository.ErrNotFound
+	}
+	return &models.User{ID: id, Role: "user"}, nil
+}
+
+func (r *fakeUserAdminRepo) Delete(ctx context.Context, id string, newOwnerID string) error {
+	if !r.users[id] {
+		return repository.ErrNotFound
+	}
+	if r.owned[id] > 0 {
+		if newOwnerID == "" {
+			return repository.ErrOwnsProjects
+		}
+		r.owned[newOwnerID] += r.owned[id]
+		delete(r.owned, id)
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func TestUserRoutes_DeleteTransfersProjects(t *testing.T) {
+	ownerID, heirID := uuid.NewString(), uuid.NewString()
+	path := "/users/" + ownerID
+
+	cases := []struct {
+		name       string
+		transferTo string
+		want       int
+	}{
+		{"invalid uuid", "?transferProjectsTo=nope", http.StatusBadRequest},
+		{"self transfer", "?transferProjectsTo=" + ownerID, http.StatusBadRequest},
+		{"unknown target", "?transferProjectsTo=" + uuid.NewString(), http.StatusBadRequest},
+		{"owner without target", "", http.StatusConflict},
+		{"transfer", "?transferProjectsTo=" + heirID, http.StatusOK},
+	}
+	for _, tc := range cases {
+		repo := &fakeUserAdminRepo{users: map[string]bool{ownerID: true, heirID: true}, owned: map[string]int{ownerID: 2}}
+		r := newTestRouter(t, Dependencies{UserHandler: handlers.NewUserHandler(services.NewUserService(repo, nil))})
+		token := sessionToken(t, uuid.NewString(), "admin")
+
+		w := serve(r, http.MethodDelete, path+tc.transferTo, token, "")
+		if w.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d. body=%s", tc.name, w.Code, tc.want, w.Body.String())
+		}
+		if deleted := !repo.users[ownerID]; deleted != (tc.want == http.StatusOK) {
+			t.Fatalf("%s: owner deleted = %v", tc.name, deleted)
+		}
+		if tc.want == http.StatusOK && repo.owned[heirID] != 2 {
+			t.Fatalf("%s: heir owns %d projects, want 2", tc.name, repo.owned[heirID])
+		}
+	}
+}
@@ -9,13 +9,13 @@ import (
 
 	"task-management-platform/backend/internal/server/middleware"
 	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
 )
 
 func TestUsersRoutes_UnauthorizedWhenMissingToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	r := gin.New()
 
@@ -39,8 +39,7 @@ func TestUsersRoutes_UnauthorizedWhenMissingToken(t *testing.T) {
 func TestUsersRoutes_ForbidsNonAdmin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	memberToken, err := jwtutil.GenerateToken("user-123", "user")
 	if err != nil {
@@ -70,8 +69,7 @@ func TestUsersRoutes_ForbidsNonAdmin(t *testing.T) {
 func TestUsersRoutes_AllowsAdmin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	adminToken, err := jwtutil.GenerateToken("admin-1", "admin")
 	if err != nil {
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
+)
+
+// AccessLog writes one record per request after it completes. Errors that
+// handlers attached with c.Error are logged here, so clients only ever see
+// the sanitized response. Successful requests to quietPaths, such as health
+// probes, are not logged.
+//
+// The raw path is only logged for unmatched routes: matched routes are
+// logged by pattern because some paths, like calendar feeds, embed secrets.
+func AccessLog(logger *slog.Logger, quietPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if status < http.StatusBadRequest && slices.Contains(quietPaths, route) {
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if route == "" {
+			attrs = append(attrs, slog.String("path", c.Request.URL.Path))
+		}
+		if errs := c.Errors.Errors(); len(errs) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(errs, "; ")))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case len(c.Errors) > 0:
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic into a logged 500 with a generic body.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				logger.ErrorContext(c.Request.Context(), "panic",
+					slog.Any("panic", rec),
+					slog.String("stack", string(debug.Stack())),
+				)
+				problem.Abort(c, http.StatusInternalServerError, problem.CodeInternal, "internal error")
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/logging"
+	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
+)
+
+func newLoggedRouter(t *testing.T) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "json", slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("logging.New error = %v", err)
+	}
+
+	r := gin.New()
+	r.Use(RequestID(), AccessLog(logger, "/livez"), Recovery(logger))
+	r.GET("/livez", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/feeds/:token", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/me", AuthRequired(), func(c *gin.Context) {
+		logger.InfoContext(c.Request.Context(), "inside handler")
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(errors.New("db is down"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+	})
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+	return r, &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if l == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(l), &m); err != nil {
+			t.Fatalf("log line is not JSON: %q", l)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestRequestID_HonorsOrGenerates(t *testing.T) {
+	r, _ := newLoggedRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Fatalf("expected caller id echoed, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/livez", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIDHeader); got == "" || strings.Contains(got, " ") {
+		t.Fatalf("expected a generated id, got %q", got)
+	}
+}
+
+func TestAccessLog_UserAndRouteOnEveryLine(t *testing.T) {
+	jwttest.Configure()
+	r, buf := newLoggedRouter(t)
+
+	token, err := jwtutil.GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(RequestIDHeader, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := logLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected handler and access lines, got %d", len(lines))
+	}
+	for _, l := range lines {
+		if l["request_id"] != "req-1" || l["user_id"] != "user-123" || l["route"] != "/me" {
+			t.Fatalf("missing request attributes: %v", l)
+		}
+	}
+	if lines[1]["msg"] != "request" || lines[1]["status"] != float64(http.StatusOK) {
+		t.Fatalf("unexpected access line: %v", lines[1])
+	}
+}
+
+func TestAccessLog_QuietProbesAndRedactedPaths(t *testing.T) {
+	r, buf := newLoggedRouter(t)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/livez", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/feeds/cal_secret", nil))
+
+	lines := logLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("expected only the feed request logged, got %v", lines)
+	}
+	if strings.Contains(buf.String(), "cal_secret") {
+		t.Fatalf("expected the secret path to stay out of logs: %s", buf.String())
+	}
+}
+
+func TestAccessLog_ErrorsAndPanics(t *testing.T) {
+	r, buf := newLoggedRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if strings.Contains(w.Body.String(), "db is down") {
+		t.Fatalf("expected a sanitized body, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 after panic, got %d", w.Code)
+	}
+
+	lines := logLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected error, panic and access lines, got %d", len(lines))
+	}
+	if lines[0]["level"] != "ERROR" || lines[0]["error"] != "db is down" {
+		t.Fatalf("expected the handler error in the access line: %v", lines[0])
+	}
+	if lines[1]["msg"] != "panic" || lines[2]["status"] != float64(http.StatusInternalServerError) {
+		t.Fatalf("unexpected panic lines: %v", lines[1:])
+	}
+}
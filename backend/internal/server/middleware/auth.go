@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"task-management-platform/backend/internal/logging"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
 	jwtutil "task-management-platform/backend/pkg/jwt"
 
 	"github.com/gin-gonic/gin"
@@ -12,36 +17,99 @@ import (
 const (
 	ContextUserIDKey = "userId"
 	ContextRoleKey   = "role"
+	// ContextMFAKey is true when the token was issued after a second factor.
+	ContextMFAKey = "mfa"
+	// ContextScopesKey holds the scopes of a personal access token. It is
+	// absent for JWT sessions, which are not limited by scope.
+	ContextScopesKey = "tokenScopes"
+	// ContextTokenProjectKey holds the project a personal access token is
+	// restricted to, if any.
+	ContextTokenProjectKey = "tokenProjectId"
 )
 
-func AuthRequired() gin.HandlerFunc {
+// TokenAuthenticator resolves personal access tokens. Unknown, expired and
+// revoked tokens yield a nil user and no error; an error means the lookup
+// itself failed.
+type TokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.User, *models.AccessToken, error)
+}
+
+// AuthRequired accepts a JWT access token or, when an authenticator is given,
+// a personal access token (recognised by models.AccessTokenPrefix).
+func AuthRequired(tokens ...TokenAuthenticator) gin.HandlerFunc {
+	var pats TokenAuthenticator
+	if len(tokens) > 0 {
+		pats = tokens[0]
+	}
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "missing authorization header"})
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "missing authorization header")
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid authorization header"})
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid authorization header")
+			return
+		}
+
+		if strings.HasPrefix(parts[1], models.AccessTokenPrefix) {
+			authenticateAccessToken(c, pats, parts[1])
 			return
 		}
 
 		claims, err := jwtutil.ParseToken(parts[1])
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid token"})
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid token")
 			return
 		}
 
 		if claims.UserID == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid token"})
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid token")
 			return
 		}
 
-		c.Set(ContextUserIDKey, claims.UserID)
+		setUser(c, claims.UserID)
 		c.Set(ContextRoleKey, claims.Role)
+		c.Set(ContextMFAKey, claims.MFA)
 
 		c.Next()
 	}
 }
+
+func authenticateAccessToken(c *gin.Context, pats TokenAuthenticator, raw string) {
+	if pats == nil {
+		problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid token")
+		return
+	}
+
+	user, token, err := pats.Authenticate(c.Request.Context(), raw)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "access token auth failed", "error", err)
+		problem.Abort(c, http.StatusInternalServerError, problem.CodeInternal, "internal error")
+		return
+	}
+	if user == nil {
+		problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "invalid token")
+		return
+	}
+
+	setUser(c, user.ID)
+	c.Set(ContextRoleKey, user.Role)
+	c.Set(ContextMFAKey, false)
+	c.Set(ContextScopesKey, []string(token.Scopes))
+	if token.ProjectID != nil {
+		c.Set(ContextTokenProjectKey, *token.ProjectID)
+	}
+
+	c.Next()
+}
+
+// setUser records the authenticated user for handlers and for the request's
+// log lines.
+func setUser(c *gin.Context, userID string) {
+	c.Set(ContextUserIDKey, userID)
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), "user_id", userID))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
 )
 
 func TestAuthRequired_MissingAuthorizationHeader(t *testing.T) {
@@ -51,8 +52,7 @@ func TestAuthRequired_InvalidAuthorizationHeaderFormat(t *testing.T) {
 func TestAuthRequired_InvalidToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	r := gin.New()
 	r.GET("/protected", AuthRequired(), func(c *gin.Context) {
@@ -73,8 +73,7 @@ func TestAuthRequired_InvalidToken(t *testing.T) {
 func TestAuthRequired_ValidToken_SetsContext(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	token, err := jwtutil.GenerateToken("user-123", "user")
 	if err != nil {
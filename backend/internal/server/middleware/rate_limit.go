@@ -1,82 +1,179 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
+	"math"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
 )
 
-type rateBucket struct {
-	count   int
-	resetAt time.Time
+// RateLimiter decides whether one more request for key is allowed. Keys are
+// opaque to the limiter; RateLimit builds them from the user or client IP.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (RateDecision, error)
+}
+
+type RateDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAt is when the bucket will be full again.
+	ResetAt time.Time
+	// RetryAfter is how long a rejected caller should wait for the next token.
+	RetryAfter time.Duration
+}
+
+// tokenBucket refills continuously at limit/window tokens per second up to a
+// capacity of limit, which lets short bursts through while holding the
+// long-run rate at limit per window.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
 }
 
-type RateLimiter struct {
+// MemoryRateLimiter is an in-process token-bucket limiter. Buckets that have
+// been idle long enough to refill completely carry no state worth keeping, so
+// they are swept periodically; memory is bounded by the number of keys seen
+// within one window.
+type MemoryRateLimiter struct {
 	mu      sync.Mutex
-	buckets map[string]rateBucket
+	buckets map[string]*tokenBucket
 
 	limit  int
 	window time.Duration
+	rate   float64 // tokens per second
+
+	lastSweep time.Time
+	now       func() time.Time
 }
 
-func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		buckets: make(map[string]rateBucket),
+// NewRateLimiter returns an in-process limiter allowing limit requests per
+// window for each key.
+func NewRateLimiter(limit int, window time.Duration) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets: make(map[string]*tokenBucket),
 		limit:   limit,
 		window:  window,
+		rate:    float64(limit) / window.Seconds(),
+		now:     time.Now,
 	}
 }
 
-func (rl *RateLimiter) allow(key string) (allowed bool, remaining int, resetAt time.Time) {
-	now := time.Now().UTC()
+func (rl *MemoryRateLimiter) Allow(_ context.Context, key string) (RateDecision, error) {
+	now := rl.now()
 
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	b, ok := rl.buckets[key]
-	if !ok || now.After(b.resetAt) {
-		b = rateBucket{
-			count:   0,
-			resetAt: now.Add(rl.window),
-		}
+	if now.Sub(rl.lastSweep) >= rl.window {
+		rl.sweep(now)
+		rl.lastSweep = now
 	}
 
-	if b.count >= rl.limit {
-		return false, 0, b.resetAt
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(rl.limit), last: now}
+		rl.buckets[key] = b
 	}
 
-	b.count++
-	rl.buckets[key] = b
+	b.tokens = math.Min(float64(rl.limit), b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
 
-	return true, rl.limit - b.count, b.resetAt
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return describe(allowed, b.tokens, rl.limit, rl.rate, now), nil
 }
 
-// RateLimit limits by JWT userId if present (set by AuthRequired middleware),
-// otherwise falls back to IP (for public endpoints like /auth/login).
-func RateLimit(rl *RateLimiter) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		key := ""
+// Len reports how many buckets are currently tracked.
+func (rl *MemoryRateLimiter) Len() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.buckets)
+}
 
-		if v, ok := c.Get(ContextUserIDKey); ok {
-			if s, ok := v.(string); ok && s != "" {
-				key = "user:" + s
-			}
+func (rl *MemoryRateLimiter) sweep(now time.Time) {
+	for k, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rl.rate >= float64(rl.limit) {
+			delete(rl.buckets, k)
 		}
-		if key == "" {
-			key = "ip:" + c.ClientIP()
+	}
+}
+
+// describe builds the decision from the bucket state left after the request
+// was (or was not) granted a token.
+func describe(allowed bool, tokens float64, limit int, rate float64, now time.Time) RateDecision {
+	d := RateDecision{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   now.Add(secondsToDuration((float64(limit) - tokens) / rate)),
+	}
+	if !allowed {
+		d.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return d
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// RateLimitKey derives the bucket key for a request.
+type RateLimitKey func(c *gin.Context) string
+
+// KeyByUserOrIP uses the JWT userId if present (set by AuthRequired
+// middleware), otherwise falls back to IP. Registered globally it therefore
+// throttles per IP; registered after AuthRequired on a group it throttles per
+// user.
+func KeyByUserOrIP(c *gin.Context) string {
+	if v, ok := c.Get(ContextUserIDKey); ok {
+		if s, ok := v.(string); ok && s != "" {
+			return "user:" + s
 		}
+	}
+	return KeyByIP(c)
+}
+
+// KeyByIP always uses the client IP, for endpoints where the caller is not
+// authenticated yet.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit limits by KeyByUserOrIP.
+func RateLimit(rl RateLimiter) gin.HandlerFunc {
+	return RateLimitBy(rl, KeyByUserOrIP)
+}
 
-		allowed, remaining, resetAt := rl.allow(key)
+// RateLimitBy limits requests bucketed by key.
+//
+// Limiter errors (e.g. Redis being unreachable) fail open so an outage of the
+// limiter backend does not take the API down with it.
+func RateLimitBy(rl RateLimiter, key RateLimitKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d, err := rl.Allow(c.Request.Context(), key(c))
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rate limiter unavailable, allowing request", "error", err)
+			c.Next()
+			return
+		}
 
-		c.Header("X-RateLimit-Limit", itoa(rl.limit))
-		c.Header("X-RateLimit-Remaining", itoa(remaining))
-		c.Header("X-RateLimit-Reset", itoa(int(resetAt.Unix())))
+		c.Header("X-RateLimit-Limit", itoa(d.Limit))
+		c.Header("X-RateLimit-Remaining", itoa(d.Remaining))
+		c.Header("X-RateLimit-Reset", itoa(int(d.ResetAt.Unix())))
 
-		if !allowed {
-			c.JSON(http.StatusTooManyRequests, gin.H{"message": "rate limit exceeded"})
-			c.Abort()
+		if !d.Allowed {
+			c.Header("Retry-After", itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+			problem.Abort(c, http.StatusTooManyRequests, problem.CodeRateLimited, "rate limit exceeded")
 			return
 		}
 
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy is a named limit that can be attached to route groups.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	// Key defaults to KeyByUserOrIP.
+	Key RateLimitKey
+}
+
+// RateLimitPolicies holds one limiter per policy. Each policy gets its own
+// buckets, so a request counted against "auth" does not use up "user".
+type RateLimitPolicies struct {
+	policies map[string]RateLimitPolicy
+	limiters map[string]RateLimiter
+}
+
+// NewRateLimitPolicies builds a limiter for every policy with newLimiter,
+// which lets the caller choose the backing store (memory or Redis).
+func NewRateLimitPolicies(newLimiter func(p RateLimitPolicy) RateLimiter, policies ...RateLimitPolicy) *RateLimitPolicies {
+	rp := &RateLimitPolicies{
+		policies: make(map[string]RateLimitPolicy, len(policies)),
+		limiters: make(map[string]RateLimiter, len(policies)),
+	}
+	for _, p := range policies {
+		if p.Key == nil {
+			p.Key = KeyByUserOrIP
+		}
+		rp.policies[p.Name] = p
+		rp.limiters[p.Name] = newLimiter(p)
+	}
+	return rp
+}
+
+// Handler returns the middleware enforcing the named policy. Referencing an
+// unknown policy is a wiring bug, so it panics at route registration time
+// rather than silently leaving a route unprotected.
+func (rp *RateLimitPolicies) Handler(name string) gin.HandlerFunc {
+	p, ok := rp.policies[name]
+	if !ok {
+		panic(fmt.Sprintf("rate limit policy %q is not defined", name))
+	}
+	return RateLimitBy(rp.limiters[name], p.Key)
+}
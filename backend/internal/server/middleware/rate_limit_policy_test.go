@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitPolicies_AreIndependentPerRouteGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policies := NewRateLimitPolicies(
+		func(p RateLimitPolicy) RateLimiter { return NewRateLimiter(p.Limit, p.Window) },
+		RateLimitPolicy{Name: "auth", Limit: 1, Window: time.Minute, Key: KeyByIP},
+		RateLimitPolicy{Name: "api", Limit: 5, Window: time.Minute},
+	)
+
+	r := gin.New()
+	r.POST("/auth/login", policies.Handler("auth"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/api/items", policies.Handler("api"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	if code := do(http.MethodPost, "/auth/login"); code != http.StatusOK {
+		t.Fatalf("first login: got %d, want 200", code)
+	}
+	if code := do(http.MethodPost, "/auth/login"); code != http.StatusTooManyRequests {
+		t.Fatalf("second login: got %d, want 429", code)
+	}
+	if code := do(http.MethodGet, "/api/items"); code != http.StatusOK {
+		t.Fatalf("api request after auth limit: got %d, want 200", code)
+	}
+}
+
+func TestRateLimitPolicies_UnknownPolicyPanics(t *testing.T) {
+	policies := NewRateLimitPolicies(func(p RateLimitPolicy) RateLimiter { return NewRateLimiter(p.Limit, p.Window) })
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for unknown policy")
+		}
+	}()
+	policies.Handler("missing")
+}
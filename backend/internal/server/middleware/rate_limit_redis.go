@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and consumes a bucket atomically. State is a hash
+// of the current token count and the last refill time in milliseconds; the key
+// expires once the bucket would be full again, so idle keys clean themselves
+// up.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimiter is a token-bucket limiter whose buckets live in Redis, so
+// the limit holds across every API replica instead of per process.
+type RedisRateLimiter struct {
+	client redis.UniversalClient
+	prefix string
+
+	limit int
+	rate  float64 // tokens per second
+
+	now func() time.Time
+}
+
+func NewRedisRateLimiter(client redis.UniversalClient, prefix string, limit int, window time.Duration) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client: client,
+		prefix: prefix,
+		limit:  limit,
+		rate:   float64(limit) / window.Seconds(),
+		now:    time.Now,
+	}
+}
+
+func (rl *RedisRateLimiter) Allow(ctx context.Context, key string) (RateDecision, error) {
+	now := rl.now()
+
+	res, err := tokenBucketScript.Run(
+		ctx,
+		rl.client,
+		[]string{rl.prefix + key},
+		rl.limit,
+		strconv.FormatFloat(rl.rate/1000, 'f', -1, 64),
+		now.UnixMilli(),
+	).Slice()
+	if err != nil {
+		return RateDecision{}, err
+	}
+
+	allowed, _ := res[0].(int64)
+	tokensStr, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return RateDecision{}, err
+	}
+
+	return describe(allowed == 1, tokens, rl.limit, rl.rate, now), nil
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisRateLimiter_SharesBucketsAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	// two limiters against the same Redis behave like two API replicas
+	a := NewRedisRateLimiter(client, "rl:test:", 2, time.Minute)
+	b := NewRedisRateLimiter(client, "rl:test:", 2, time.Minute)
+	a.now, b.now = clock, clock
+
+	ctx := context.Background()
+
+	if d, err := a.Allow(ctx, "ip:1"); err != nil || !d.Allowed || d.Remaining != 1 {
+		t.Fatalf("a.Allow() = %+v, %v; want allowed with 1 remaining", d, err)
+	}
+	if d, err := b.Allow(ctx, "ip:1"); err != nil || !d.Allowed || d.Remaining != 0 {
+		t.Fatalf("b.Allow() = %+v, %v; want allowed with 0 remaining", d, err)
+	}
+	d, err := a.Allow(ctx, "ip:1")
+	if err != nil || d.Allowed {
+		t.Fatalf("a.Allow() = %+v, %v; want rejected", d, err)
+	}
+	if d.RetryAfter <= 0 {
+		t.Fatalf("RetryAfter = %v, want > 0", d.RetryAfter)
+	}
+
+	now = now.Add(30 * time.Second)
+	if d, err := b.Allow(ctx, "ip:1"); err != nil || !d.Allowed {
+		t.Fatalf("b.Allow() after refill = %+v, %v; want allowed", d, err)
+	}
+}
+
+func TestRedisRateLimiter_ExpiresIdleKeys(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	rl := NewRedisRateLimiter(client, "rl:test:", 10, time.Minute)
+	if _, err := rl.Allow(context.Background(), "ip:1"); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+
+	if ttl := mr.TTL("rl:test:ip:1"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL = %v, want (0, 1m]", ttl)
+	}
+}
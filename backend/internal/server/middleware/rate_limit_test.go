@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
 )
 
 func TestRateLimit_Exceeded(t *testing.T) {
@@ -44,3 +49,111 @@ func TestRateLimit_Exceeded(t *testing.T) {
 		t.Fatalf("expected 429, got %d", w3.Code)
 	}
 }
+
+func TestRateLimiter_RefillsOverTime(t *testing.T) {
+	rl := NewRateLimiter(2, time.Minute)
+
+	now := time.Now()
+	rl.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if d, _ := rl.Allow(ctx, "k"); !d.Allowed {
+			t.Fatalf("request %d: expected allowed", i+1)
+		}
+	}
+
+	d, _ := rl.Allow(ctx, "k")
+	if d.Allowed {
+		t.Fatalf("expected third request to be rejected")
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > 30*time.Second {
+		t.Fatalf("RetryAfter = %v, want (0, 30s]", d.RetryAfter)
+	}
+
+	// one token refills every 30s at 2/min
+	now = now.Add(30 * time.Second)
+	if d, _ := rl.Allow(ctx, "k"); !d.Allowed {
+		t.Fatalf("expected request to be allowed after refill")
+	}
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	rl := NewRateLimiter(5, time.Minute)
+
+	now := time.Now()
+	rl.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		_, _ = rl.Allow(ctx, "ip:"+itoa(i))
+	}
+	if rl.Len() != 100 {
+		t.Fatalf("len = %d, want 100", rl.Len())
+	}
+
+	now = now.Add(2 * time.Minute)
+	_, _ = rl.Allow(ctx, "ip:fresh")
+
+	if rl.Len() != 1 {
+		t.Fatalf("len = %d after idle sweep, want 1", rl.Len())
+	}
+}
+
+func TestRateLimit_KeysOnAuthenticatedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwttest.Configure()
+
+	rl := NewRateLimiter(1, time.Minute)
+
+	r := gin.New()
+	r.GET("/test", AuthRequired(), RateLimit(rl), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	do := func(userID string) int {
+		token, err := jwtutil.GenerateToken(userID, "user")
+		if err != nil {
+			t.Fatalf("GenerateToken error = %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do("user-a"); code != http.StatusOK {
+		t.Fatalf("user-a first request: got %d, want 200", code)
+	}
+	if code := do("user-a"); code != http.StatusTooManyRequests {
+		t.Fatalf("user-a second request: got %d, want 429", code)
+	}
+	// same IP, different user: separate bucket
+	if code := do("user-b"); code != http.StatusOK {
+		t.Fatalf("user-b first request: got %d, want 200", code)
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string) (RateDecision, error) {
+	return RateDecision{}, errors.New("backend down")
+}
+
+func TestRateLimit_FailsOpenOnLimiterError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimit(failingLimiter{}))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
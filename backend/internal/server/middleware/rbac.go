@@ -4,23 +4,34 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/problem"
 )
 
 func RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		v, ok := c.Get(ContextRoleKey)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "unauthorized",
-			})
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "unauthorized")
 			return
 		}
 
 		currentRole, ok := v.(string)
 		if !ok || currentRole != role {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"message": "forbidden",
-			})
+			problem.Abort(c, http.StatusForbidden, problem.CodeForbidden, "forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireMFA only lets through sessions that were established with a second
+// factor. It must run after AuthRequired.
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(ContextMFAKey) {
+			problem.Abort(c, http.StatusForbidden, problem.CodeSecondFactorRequired, "two-factor authentication required")
 			return
 		}
 
@@ -3,18 +3,20 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 
+	"task-management-platform/backend/internal/problem"
 	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
 )
 
 func TestRequireRole_ForbidsNonAdmin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	memberToken, err := jwtutil.GenerateToken("user-123", "user")
 	if err != nil {
@@ -44,8 +46,7 @@ func TestRequireRole_ForbidsNonAdmin(t *testing.T) {
 func TestRequireRole_AllowsAdmin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	adminToken, err := jwtutil.GenerateToken("admin-1", "admin")
 	if err != nil {
@@ -75,8 +76,7 @@ func TestRequireRole_AllowsAdmin(t *testing.T) {
 func TestRequireRole_UnauthorizedWhenMissingToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	jwttest.Configure()
 
 	r := gin.New()
 	r.GET("/admin",
@@ -96,3 +96,50 @@ func TestRequireRole_UnauthorizedWhenMissingToken(t *testing.T) {
 		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
 	}
 }
+
+func TestRequireMFA_ForbidsPasswordOnlySession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwttest.Configure()
+
+	passwordOnly, err := jwtutil.GenerateToken("admin-1", "admin")
+	if err != nil {
+		t.Fatalf("GenerateToken error = %v", err)
+	}
+	withMFA, err := jwtutil.GenerateToken("admin-1", "admin", jwtutil.WithMFA())
+	if err != nil {
+		t.Fatalf("GenerateToken error = %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/admin",
+		AuthRequired(),
+		RequireRole("admin"),
+		RequireMFA(),
+		func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		},
+	)
+
+	for _, tc := range []struct {
+		token string
+		want  int
+		code  string
+	}{
+		{passwordOnly, http.StatusForbidden, problem.CodeSecondFactorRequired},
+		{withMFA, http.StatusOK, ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.want {
+			t.Fatalf("status = %d, want %d. body=%s", w.Code, tc.want, w.Body.String())
+		}
+		if tc.code != "" && !strings.Contains(w.Body.String(), `"code":"`+tc.code+`"`) {
+			t.Fatalf("expected code %q, body=%s", tc.code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/logging"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// ContextRequestIDKey holds the id of the current request.
+	ContextRequestIDKey = "requestId"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID keeps a well-formed X-Request-ID from the caller, or a proxy in
+// front, and generates one otherwise. The id is echoed in the response and
+// added, with the matched route, to the request's log context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(ContextRequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := logging.With(c.Request.Context(), "request_id", id, "route", c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts short ids of visible ASCII so a caller cannot
+// inject newlines or huge values into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/problem"
+)
+
+// RequireScope limits personal access tokens to those carrying scope. JWT
+// sessions are not scoped and always pass. It must run after AuthRequired.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !scopeAllowed(c, scope) {
+			problem.Abort(c, http.StatusForbidden, problem.CodeForbidden, "token lacks scope "+scope)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireResourceScope asks for resource:read on safe methods and
+// resource:write on everything else.
+func RequireResourceScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := resource + ":write"
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = resource + ":read"
+		}
+
+		if !scopeAllowed(c, scope) {
+			problem.Abort(c, http.StatusForbidden, problem.CodeForbidden, "token lacks scope "+scope)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SessionOnly rejects personal access tokens, for account management that
+// automation must not reach (passwords, two-factor, issuing more tokens).
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ContextScopesKey); ok {
+			problem.Abort(c, http.StatusForbidden, problem.CodeForbidden, "not available to access tokens")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TokenProject returns the project a personal access token is restricted to.
+func TokenProject(c *gin.Context) (string, bool) {
+	id := c.GetString(ContextTokenProjectKey)
+	return id, id != ""
+}
+
+func scopeAllowed(c *gin.Context, scope string) bool {
+	v, ok := c.Get(ContextScopesKey)
+	if !ok {
+		return true
+	}
+	scopes, _ := v.([]string)
+	return models.ScopesAllow(scopes, scope)
+}
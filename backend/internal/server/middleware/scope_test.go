@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management-platform/backend/internal/models"
+	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/jwt/jwttest"
+)
+
+type fakeTokenAuthenticator map[string]*models.AccessToken
+
+func (f fakeTokenAuthenticator) Authenticate(ctx context.Context, token string) (*models.User, *models.AccessToken, error) {
+	t, ok := f[token]
+	if !ok {
+		return nil, nil, nil
+	}
+	return &models.User{ID: t.UserID, Role: "user"}, t, nil
+}
+
+func newScopedRouter(tokens TokenAuthenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	tasks := r.Group("/tasks", AuthRequired(tokens), RequireResourceScope("tasks"))
+	tasks.GET("", func(c *gin.Context) { c.Status(http.StatusOK) })
+	tasks.POST("", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	r.POST("/change-password", AuthRequired(tokens), SessionOnly(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestAuthRequired_AccessTokenScopes(t *testing.T) {
+	jwttest.Configure()
+
+	readOnly := models.AccessTokenPrefix + "readonly"
+	r := newScopedRouter(fakeTokenAuthenticator{
+		readOnly: {ID: "t1", UserID: "user-123", Scopes: []string{models.ScopeTasksRead}},
+	})
+
+	session, err := jwtutil.GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"read with read scope", http.MethodGet, "/tasks", readOnly, http.StatusOK},
+		{"write with read scope", http.MethodPost, "/tasks", readOnly, http.StatusForbidden},
+		{"unknown access token", http.MethodGet, "/tasks", models.AccessTokenPrefix + "nope", http.StatusUnauthorized},
+		{"session is not scoped", http.MethodPost, "/tasks", session, http.StatusCreated},
+		{"access token on session-only route", http.MethodPost, "/change-password", readOnly, http.StatusForbidden},
+		{"session on session-only route", http.MethodPost, "/change-password", session, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d. body=%s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthRequired_AccessTokenWithoutAuthenticator(t *testing.T) {
+	r := newScopedRouter(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+models.AccessTokenPrefix+"anything")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
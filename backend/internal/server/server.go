@@ -1,87 +1,450 @@
 package server
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"task-management-platform/backend/internal/cache"
 	"task-management-platform/backend/internal/config"
 	"task-management-platform/backend/internal/handlers"
+	"task-management-platform/backend/internal/metrics"
+	"task-management-platform/backend/internal/migrate"
+	"task-management-platform/backend/internal/openapi"
+	"task-management-platform/backend/internal/problem"
 	"task-management-platform/backend/internal/repository"
 	"task-management-platform/backend/internal/routes"
 	"task-management-platform/backend/internal/server/middleware"
 	"task-management-platform/backend/internal/services"
+	"task-management-platform/backend/internal/tracing"
+	jwtutil "task-management-platform/backend/pkg/jwt"
+	"task-management-platform/backend/pkg/seal"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
 )
 
-func New(cfg config.Config) *gin.Engine {
-	r := gin.Default()
-	rl := middleware.NewRateLimiter(120, time.Minute)
-	r.Use(middleware.RateLimit(rl))
-	origins := []string{
-		"http://localhost:5173",
-		"http://localhost:3000",
+// trashPurgeInterval is how often the trash is checked for items past the
+// retention period.
+const trashPurgeInterval = time.Hour
+
+// exportSweepInterval is how often expired export results are deleted.
+const exportSweepInterval = time.Minute
+
+// docsAssetsRoute serves the Swagger UI files behind /docs.
+const docsAssetsRoute = "/docs/assets/*file"
+
+// Server is the API process: the HTTP server plus the resources it has to
+// release on shutdown.
+type Server struct {
+	cfg     config.Config
+	engine  *gin.Engine
+	http    *http.Server
+	db      *sqlx.DB
+	redis   *redis.Client
+	exports *services.ExportJobs
+	trash   *services.TrashService
+
+	// draining flips on SIGTERM so /readyz fails while requests drain.
+	draining atomic.Bool
+}
+
+// New wires the application. Errors are returned rather than fatal so the
+// caller decides how to exit; resources opened before a failure are closed.
+func New(cfg config.Config) (_ *Server, err error) {
+	tokens, err := tokenSettings(cfg)
+	if err != nil {
+		return nil, err
 	}
+	jwtutil.Configure(tokens)
 
-	if v := os.Getenv("CORS_ORIGINS"); v != "" {
-		origins = strings.Split(v, ",")
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
 	}
+	m := metrics.New()
+	r.Use(
+		// outermost, so log lines written further in carry the trace id;
+		// feed URLs hold their secret in the path and stay out of traces
+		tracing.Middleware(cfg.TraceServiceName, "/livez", "/readyz", "/health", "/metrics", "/openapi.json", "/docs", docsAssetsRoute, routes.CalendarFeedPath),
+		middleware.RequestID(),
+		middleware.AccessLog(slog.Default(), "/livez", "/readyz", "/health", "/metrics", "/openapi.json", "/docs", docsAssetsRoute),
+		// outside Recovery, so requests that panic are counted as 500s
+		m.Middleware(),
+		middleware.Recovery(slog.Default()),
+	)
+
+	// unknown paths get the same error envelope as everything else
+	r.NoRoute(func(c *gin.Context) {
+		problem.Abort(c, http.StatusNotFound, problem.CodeNotFound, "route not found")
+	})
 
+	s := &Server{cfg: cfg, engine: r}
+	defer func() {
+		if err != nil {
+			s.close()
+		}
+	}()
+
+	// probes are registered before the rate limiter so orchestrators are
+	// never throttled
+	health := handlers.NewHealthHandler(
+		func() *sql.DB { return s.db.DB },
+		func() *redis.Client { return s.redis },
+		s.draining.Load,
+	)
+	r.GET("/livez", health.Live)
+	r.GET("/readyz", health.Ready)
+	// /health predates the split probes and stays a liveness check
+	r.GET("/health", health.Live)
+	// scrapers are not rate limited either, so /metrics is only served
+	// behind METRICS_TOKEN
+	if cfg.MetricsToken != "" {
+		r.GET("/metrics", m.Handler(cfg.MetricsToken))
+	} else {
+		slog.Info("METRICS_TOKEN not set, /metrics disabled")
+	}
+
+	spec := handlers.APISpec()
+	r.GET("/openapi.json", spec.Handler())
+	r.GET("/docs", openapi.UI(spec.Info.Title, "/openapi.json", "/docs/assets"))
+	r.GET(docsAssetsRoute, openapi.UIAssets())
+
+	redisClient := newRedisClient(cfg)
+	s.redis = redisClient
+
+	rateLimits := middleware.NewRateLimitPolicies(
+		func(p middleware.RateLimitPolicy) middleware.RateLimiter {
+			return m.RateLimiter(p.Name, newRateLimiter(redisClient, p.Name, p.Limit, p.Window))
+		},
+		middleware.RateLimitPolicy{Name: "global", Limit: cfg.RateLimitIP, Window: cfg.RateLimitWindow, Key: middleware.KeyByIP},
+		middleware.RateLimitPolicy{Name: routes.PolicyAuth, Limit: cfg.RateLimitAuth, Window: cfg.RateLimitWindow, Key: middleware.KeyByIP},
+		middleware.RateLimitPolicy{Name: routes.PolicyUser, Limit: cfg.RateLimitUser, Window: cfg.RateLimitWindow},
+	)
+	r.Use(rateLimits.Handler("global"))
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     origins,
+		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Disposition"}, // 👈 importante para export ZIP/CSV
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Disposition", middleware.RequestIDHeader}, // 👈 importante para export ZIP/CSV
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	db, err := repository.NewDB(cfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("connect database: %w", err)
 	}
+	s.db = db
+	if err := migrate.Check(context.Background(), db.DB); err != nil {
+		return nil, err
+	}
+	m.WatchDB(db.DB, cfg.DBName)
+	m.WatchTasks(repository.NewStatsRepository(db))
+
+	loader := cache.NewLoader(newCache(cfg, redisClient), cfg.CacheTTL)
 
 	userRepo := repository.NewUserRepository(db)
 	projectRepo := repository.NewProjectRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
 	apiUserRepo := repository.NewAPIUserRepository(db)
 
-	authService := services.NewAuthService(userRepo)
-	authHandler := handlers.NewAuthHandler(authService)
+	cachedProjectRepo := repository.NewCachedProjectRepository(projectRepo, loader)
+	cachedTaskRepo := repository.NewCachedTaskRepository(taskRepo, loader)
+	cachedAPIUserRepo := repository.NewCachedAPIUserRepository(apiUserRepo, loader)
 
-	userService := services.NewUserService(userRepo)
+	totpBox, err := seal.New(cfg.TOTPEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("totp encryption key: %w", err)
+	}
+	twoFactorService := services.NewTwoFactorService(userRepo, cfg.TOTPIssuer, totpBox)
+	if err := twoFactorService.SealStoredSecrets(context.Background()); err != nil {
+		return nil, fmt.Errorf("seal stored totp secrets: %w", err)
+	}
+	loginGuard := newLoginGuard(redisClient)
+	authService := services.NewAuthService(userRepo, loader, loginGuard, twoFactorService)
+	authService.ObserveLogins(m)
+	authHandler := handlers.NewAuthHandler(authService, twoFactorService)
+
+	userService := services.NewUserService(userRepo, loader)
 	userHandler := handlers.NewUserHandler(userService)
 
-	projectService := services.NewProjectService(projectRepo)
+	projectService := services.NewProjectService(cachedProjectRepo)
 	projectHandler := handlers.NewProjectHandler(projectService)
+	reportHandler := handlers.NewReportHandler(services.NewReportService(projectService, cachedTaskRepo, cachedAPIUserRepo))
 
-	taskService := services.NewTaskService(taskRepo, projectRepo)
+	taskService := services.NewTaskService(cachedTaskRepo, cachedProjectRepo, repository.NewChecklistRepository(db))
 	taskHandler := handlers.NewTaskHandler(taskService)
+	taskImportHandler := handlers.NewTaskImportHandler(services.NewTaskImportService(cachedTaskRepo, cachedProjectRepo, cachedAPIUserRepo))
 
-	apiUserSvc := services.NewAPIUserService(apiUserRepo)
+	apiUserSvc := services.NewAPIUserService(cachedAPIUserRepo)
 	apiUserHandler := handlers.NewAPIUserHandler(apiUserSvc)
-	adminExportHandler := handlers.NewAdminExportHandler(userRepo, projectRepo, taskRepo)
+	exportService := services.NewExportService(repository.NewExportRepository(db))
+	s.exports = newExportJobs(cfg, exportService)
+	adminExportHandler := handlers.NewAdminExportHandler(exportService, s.exports)
+	importService := services.NewImportService(repository.NewImportRepository(db), loader)
+	adminImportHandler := handlers.NewAdminImportHandler(importService)
+	oidcHandler := newOIDCHandler(cfg, userRepo, loader)
+
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
+	accessTokenService := services.NewAccessTokenService(accessTokenRepo, userRepo, projectRepo)
+	accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
+	calendarService := services.NewCalendarService(repository.NewCalendarFeedRepository(db), userRepo, projectService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	s.trash = services.NewTrashService(repository.NewTrashRepository(db), cachedProjectRepo, loader, cfg.TrashRetention)
+	trashHandler := handlers.NewTrashHandler(s.trash)
+	transferService := services.NewProjectTransferService(repository.NewProjectTransferRepository(db), cachedProjectRepo, userRepo, loader)
+	transferHandler := handlers.NewProjectTransferHandler(transferService)
 
 	r.GET("/", func(c *gin.Context) {
 		handlers.RespondOK(c, http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	r.GET("/health", func(c *gin.Context) {
-		handlers.RespondOK(c, http.StatusOK, gin.H{"status": "ok"})
-	})
+	var validate gin.HandlerFunc
+	if cfg.OpenAPIValidate {
+		validate, err = spec.Validator(openapi.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("compile openapi document: %w", err)
+		}
+	}
 
 	routes.Register(r, routes.Dependencies{
 		AuthHandler:        authHandler,
 		UserHandler:        userHandler,
 		ProjectHandler:     projectHandler,
+		ReportHandler:      reportHandler,
 		TaskHandler:        taskHandler,
+		TaskImportHandler:  taskImportHandler,
 		APIUserHandler:     apiUserHandler,
 		AdminExportHandler: adminExportHandler,
+		AdminImportHandler: adminImportHandler,
+		OIDCHandler:        oidcHandler,
+		AccessTokenHandler: accessTokenHandler,
+		CalendarHandler:    calendarHandler,
+		TrashHandler:       trashHandler,
+		TransferHandler:    transferHandler,
+		Tokens:             accessTokenService,
+		RateLimits:         rateLimits,
+		Validate:           validate,
+		RequireAdminMFA:    cfg.RequireAdminMFA,
 	})
 
-	return r
+	s.http = &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           r,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	return s, nil
+}
+
+// Handler exposes the router, for tests and embedding.
+func (s *Server) Handler() http.Handler {
+	return s.engine
+}
+
+// Run serves until ctx is cancelled, then shuts down gracefully: /readyz
+// fails for ShutdownDelay, in-flight requests and background exports get
+// ShutdownTimeout to finish, and the database and Redis are closed. The
+// trash retention job and the export sweeper run alongside and stop first.
+func (s *Server) Run(ctx context.Context) error {
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("api starting", "addr", s.http.Addr)
+		errc <- s.http.ListenAndServe()
+	}()
+
+	retentionCtx, cancelRetention := context.WithCancel(ctx)
+	var retention sync.WaitGroup
+	retention.Add(1)
+	go func() {
+		defer retention.Done()
+		s.trash.RunRetention(retentionCtx, trashPurgeInterval)
+	}()
+	if s.exports != nil {
+		retention.Add(1)
+		go func() {
+			defer retention.Done()
+			s.exports.RunSweeper(retentionCtx, exportSweepInterval)
+		}()
+	}
+	stopRetention := func() {
+		cancelRetention()
+		retention.Wait()
+	}
+
+	select {
+	case err := <-errc:
+		stopRetention()
+		s.close()
+		return err
+	case <-ctx.Done():
+	}
+	stopRetention()
+
+	s.draining.Store(true)
+	if s.cfg.ShutdownDelay > 0 {
+		slog.Info("shutting down: reporting not ready before draining", "delay", s.cfg.ShutdownDelay.String())
+		time.Sleep(s.cfg.ShutdownDelay)
+	}
+
+	slog.Info("shutting down: draining", "timeout", s.cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	err := s.http.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error("shutdown did not complete", "error", err)
+	}
+	if s.exports != nil {
+		done := make(chan struct{})
+		go func() {
+			s.exports.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+			slog.Warn("shutdown: abandoning background exports still running")
+		}
+	}
+
+	s.close()
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("server stopped unexpectedly", "error", err)
+	}
+	slog.Info("shutdown complete")
+	return err
+}
+
+func (s *Server) close() {
+	if s.db != nil {
+		if err := s.db.Close(); err != nil {
+			slog.Error("close database", "error", err)
+		}
+	}
+	if s.redis != nil {
+		if err := s.redis.Close(); err != nil {
+			slog.Error("close redis", "error", err)
+		}
+	}
+}
+
+// tokenSettings picks the JWT signing key: the PEM private key when one is
+// configured, else the HS256 secret.
+func tokenSettings(cfg config.Config) (jwtutil.Settings, error) {
+	s := jwtutil.Settings{TTL: cfg.JWTTTL, Issuer: cfg.JWTIssuer, Audience: cfg.JWTAudience}
+
+	if cfg.JWTPrivateKey == "" {
+		s.SigningKey = jwtutil.HMACKey(cfg.JWTKeyID, []byte(cfg.JWTSecret))
+	} else {
+		key, err := jwtutil.ParsePrivateKey([]byte(cfg.JWTPrivateKey))
+		if err != nil {
+			return s, fmt.Errorf("JWT_PRIVATE_KEY: %w", err)
+		}
+		s.SigningKey = key
+	}
+
+	if cfg.JWTVerificationKeys != "" {
+		keys, err := jwtutil.ParsePublicKeys([]byte(cfg.JWTVerificationKeys))
+		if err != nil {
+			return s, fmt.Errorf("JWT_VERIFICATION_KEYS: %w", err)
+		}
+		s.VerificationKeys = keys
+	}
+	return s, nil
+}
+
+// newRedisClient connects to REDIS_URL when set. A missing or unreachable
+// Redis is not fatal: callers fall back to in-process implementations.
+func newRedisClient(cfg config.Config) *redis.Client {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := cache.NewRedisClient(ctx, cfg.RedisURL)
+	if err != nil {
+		slog.Warn("redis unavailable, using in-process cache and rate limits", "error", err)
+		return nil
+	}
+	return client
+}
+
+// newOIDCHandler runs discovery against the configured provider. Failure
+// only disables single sign-on so an IdP outage does not block password
+// logins.
+func newOIDCHandler(cfg config.Config, userRepo *repository.UserRepository, loader *cache.Loader) *handlers.OIDCHandler {
+	if cfg.OIDCIssuerURL == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	svc, err := services.NewOIDCService(ctx, services.OIDCConfig{
+		IssuerURL:    cfg.OIDCIssuerURL,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Scopes:       cfg.OIDCScopes,
+		GroupsClaim:  cfg.OIDCGroupsClaim,
+		RoleMapping:  cfg.OIDCRoleMapping,
+		StateSecret:  []byte(cfg.OIDCStateSecret),
+		HTTPClient:   tracing.HTTPClient(),
+	}, userRepo, loader)
+	if err != nil {
+		slog.Warn("oidc discovery failed, single sign-on disabled", "error", err)
+		return nil
+	}
+
+	return handlers.NewOIDCHandler(svc, cfg.OIDCPostLoginURL)
+}
+
+// newExportJobs enables background exports when the export directory is
+// usable.
+func newExportJobs(cfg config.Config, exports *services.ExportService) *services.ExportJobs {
+	jobs, err := services.NewExportJobs(exports, cfg.ExportDir, []byte(cfg.ExportLinkSecret), cfg.ExportLinkTTL, cfg.ExportRetention)
+	if err != nil {
+		slog.Warn("export directory unavailable, background exports disabled", "dir", cfg.ExportDir, "error", err)
+		return nil
+	}
+	return jobs
+}
+
+func newCache(cfg config.Config, client *redis.Client) cache.Cache {
+	if client != nil {
+		return cache.NewRedisCache(client, cache.RedisNamespace)
+	}
+	return cache.NewMemoryCache(cfg.CacheMaxEntries)
+}
+
+// newLoginGuard shares login failure counts across replicas through Redis
+// when available.
+func newLoginGuard(client *redis.Client) *services.LoginLockout {
+	if client != nil {
+		return services.NewRedisLoginLockout(client, services.LockoutRedisNamespace, services.DefaultEmailLockoutPolicy, services.DefaultAccountLockoutPolicy, services.DefaultIPLockoutPolicy)
+	}
+	return services.NewLoginLockout(services.DefaultEmailLockoutPolicy, services.DefaultAccountLockoutPolicy, services.DefaultIPLockoutPolicy)
+}
+
+// newRateLimiter shares buckets across replicas through Redis when available.
+func newRateLimiter(client *redis.Client, name string, limit int, window time.Duration) middleware.RateLimiter {
+	if client != nil {
+		return middleware.NewRedisRateLimiter(client, "taskmgr:rl:"+name+":", limit, window)
+	}
+	return middleware.NewRateLimiter(limit, window)
 }
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"task-management-platform/backend/internal/config"
+	"task-management-platform/backend/internal/repository"
+	"task-management-platform/backend/internal/services"
+)
+
+// emptyTrash lets the retention job run without a database.
+type emptyTrash struct {
+	repository.TrashRepository
+}
+
+func (emptyTrash) Purge(context.Context, time.Time) (repository.TrashPurge, error) {
+	return repository.TrashPurge{}, nil
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestServer_RunDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "done")
+	})
+
+	addr := freeAddr(t)
+	s := &Server{
+		cfg:   config.Config{ShutdownTimeout: 5 * time.Second},
+		http:  &http.Server{Addr: addr, Handler: mux},
+		trash: services.NewTrashService(emptyTrash{}, nil, nil, time.Hour),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+
+	base := "http://" + addr
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(base + "/ping")
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		slow <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	cancel()
+	deadline = time.Now().Add(5 * time.Second)
+	for !s.draining.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("server did not start draining")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-stopped:
+		t.Fatalf("Run returned %v before the in-flight request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	got := <-slow
+	if got.err != nil || got.status != http.StatusOK || got.body != "done" {
+		t.Fatalf("in-flight request = %d %q, %v; want 200 \"done\"", got.status, got.body, got.err)
+	}
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Run error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after draining")
+	}
+
+	if _, err := http.Get(base + "/ping"); err == nil {
+		t.Fatal("server still accepts requests after shutdown")
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+const (
+	DefaultAccessTokenTTL = 90 * 24 * time.Hour
+	MaxAccessTokenTTL     = 365 * 24 * time.Hour
+
+	// lastUsedResolution limits last_used_at writes to one per token per
+	// minute instead of one per request.
+	lastUsedResolution = time.Minute
+)
+
+type AccessTokenUserRepo interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+type AccessTokenProjectRepo interface {
+	GetByID(ctx context.Context, id string) (*models.Project, error)
+}
+
+type CreateAccessTokenInput struct {
+	Name      string
+	Scopes    []string
+	ProjectID *string
+	// TTL of zero means DefaultAccessTokenTTL.
+	TTL time.Duration
+}
+
+// AccessTokenService issues and checks personal access tokens. Only a hash of
+// each token is stored; the plaintext is returned once, at creation.
+type AccessTokenService struct {
+	tokens   repository.AccessTokenRepository
+	users    AccessTokenUserRepo
+	projects AccessTokenProjectRepo
+	now      func() time.Time
+}
+
+func NewAccessTokenService(tokens repository.AccessTokenRepository, users AccessTokenUserRepo, projects AccessTokenProjectRepo) *AccessTokenService {
+	return &AccessTokenService{tokens: tokens, users: users, projects: projects, now: time.Now}
+}
+
+// Create issues a token owned by ownerID. Users create tokens for themselves;
+// admins can also create them for service accounts.
+func (s *AccessTokenService) Create(ctx context.Context, actor models.User, ownerID string, in CreateAccessTokenInput) (_ string, _ *models.AccessToken, err error) {
+	ctx, span := startSpan(ctx, "AccessTokenService.Create", attribute.String("user.id", ownerID))
+	defer func() { endSpan(span, err) }()
+
+	owner, err := s.users.GetByID(ctx, ownerID)
+	if err != nil {
+		return "", nil, err
+	}
+	if owner.ID != actor.ID && !(actor.Role == "admin" && owner.Kind == models.UserKindService) {
+		return "", nil, ErrForbidden
+	}
+
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len(in.Scopes) == 0 {
+		return "", nil, ErrBadRequest
+	}
+	for _, scope := range in.Scopes {
+		if !slices.Contains(models.AccessTokenScopes, scope) {
+			return "", nil, ErrBadRequest
+		}
+	}
+	if slices.Contains(in.Scopes, models.ScopeAdmin) && (owner.Role != "admin" || in.ProjectID != nil) {
+		return "", nil, ErrBadRequest
+	}
+
+	if in.TTL == 0 {
+		in.TTL = DefaultAccessTokenTTL
+	}
+	if in.TTL < 0 || in.TTL > MaxAccessTokenTTL {
+		return "", nil, ErrBadRequest
+	}
+
+	if in.ProjectID != nil {
+		project, err := s.projects.GetByID(ctx, *in.ProjectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return "", nil, ErrBadRequest
+			}
+			return "", nil, err
+		}
+		if owner.Role != "admin" && project.OwnerID != owner.ID {
+			return "", nil, ErrForbidden
+		}
+	}
+
+	plaintext, err := newAccessTokenSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := s.now().UTC()
+	token := &models.AccessToken{
+		ID:        uuid.NewString(),
+		UserID:    owner.ID,
+		Name:      in.Name,
+		Prefix:    plaintext[:len(models.AccessTokenPrefix)+8],
+		TokenHash: hashAccessToken(plaintext),
+		Scopes:    in.Scopes,
+		ProjectID: in.ProjectID,
+		ExpiresAt: now.Add(in.TTL),
+		CreatedAt: now,
+	}
+
+	if err := s.tokens.Create(ctx, token); err != nil {
+		return "", nil, err
+	}
+	return plaintext, token, nil
+}
+
+// List returns the tokens of ownerID, visible to the owner and to admins.
+func (s *AccessTokenService) List(ctx context.Context, actor models.User, ownerID string) (_ []models.AccessToken, err error) {
+	ctx, span := startSpan(ctx, "AccessTokenService.List", attribute.String("user.id", ownerID))
+	defer func() { endSpan(span, err) }()
+
+	if actor.ID != ownerID && actor.Role != "admin" {
+		return nil, ErrForbidden
+	}
+	return s.tokens.ListByUser(ctx, ownerID)
+}
+
+// Revoke disables a token for good. Owners revoke their own tokens; admins
+// can revoke any.
+func (s *AccessTokenService) Revoke(ctx context.Context, actor models.User, tokenID string) (err error) {
+	ctx, span := startSpan(ctx, "AccessTokenService.Revoke", attribute.String("access_token.id", tokenID))
+	defer func() { endSpan(span, err) }()
+
+	token, err := s.tokens.GetByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if token.UserID != actor.ID && actor.Role != "admin" {
+		// do not reveal that someone else's token exists
+		return repository.ErrNotFound
+	}
+	return s.tokens.Revoke(ctx, tokenID)
+}
+
+// Authenticate resolves a presented token to its owner. Unknown, expired and
+// revoked tokens yield a nil user without an error, as
+// middleware.TokenAuthenticator expects.
+func (s *AccessTokenService) Authenticate(ctx context.Context, plaintext string) (_ *models.User, _ *models.AccessToken, err error) {
+	ctx, span := startSpan(ctx, "AccessTokenService.Authenticate")
+	defer func() { endSpan(span, err) }()
+
+	token, err := s.tokens.GetByHash(ctx, hashAccessToken(plaintext))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	now := s.now()
+	if token.RevokedAt != nil || !now.Before(token.ExpiresAt) {
+		return nil, nil, nil
+	}
+
+	user, err := s.users.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		// best effort: failing to record usage must not fail the request
+		_ = s.tokens.TouchLastUsed(ctx, token.ID, now.UTC())
+	}
+
+	return user, token, nil
+}
+
+func newAccessTokenSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return models.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashAccessToken uses a fast hash: the token carries 256 random bits, so
+// there is nothing for a slow hash to protect, and the hash doubles as the
+// lookup key.
+func hashAccessToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+type fakeAccessTokenRepo struct {
+	byID map[string]*models.AccessToken
+}
+
+func newFakeAccessTokenRepo() *fakeAccessTokenRepo {
+	return &fakeAccessTokenRepo{byID: map[string]*models.AccessToken{}}
+}
+
+func (r *fakeAccessTokenRepo) Create(ctx context.Context, token *models.AccessToken) error {
+	r.byID[token.ID] = token
+	return nil
+}
+
+func (r *fakeAccessTokenRepo) GetByID(ctx context.Context, id string) (*models.AccessToken, error) {
+	t, ok := r.byID[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *fakeAccessTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*models.AccessToken, error) {
+	for _, t := range r.byID {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeAccessTokenRepo) ListByUser(ctx context.Context, userID string) ([]models.AccessToken, error) {
+	out := make([]models.AccessToken, 0)
+	for _, t := range r.byID {
+		if t.UserID == userID {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeAccessTokenRepo) Revoke(ctx context.Context, id string) error {
+	t, ok := r.byID[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeAccessTokenRepo) RevokeAllForUser(ctx context.Context, userID string) (int64, error) {
+	var n int64
+	now := time.Now()
+	for _, t := range r.byID {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeAccessTokenRepo) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	r.byID[id].LastUsedAt = &at
+	return nil
+}
+
+func newAccessTokenFixture(t *testing.T) (*fakeAccessTokenRepo, *fakeUserRepo, *fakeProjectRepo, *AccessTokenService) {
+	t.Helper()
+
+	tokens := newFakeAccessTokenRepo()
+	users := newFakeUserRepo()
+	projects := newFakeProjectRepo()
+
+	for _, u := range []*models.User{
+		{ID: "u1", Email: "u1@test.com", Role: "user", Kind: models.UserKindHuman},
+		{ID: "u2", Email: "u2@test.com", Role: "user", Kind: models.UserKindHuman},
+		{ID: "admin", Email: "admin@test.com", Role: "admin", Kind: models.UserKindHuman},
+		{ID: "bot", Email: "bot@test.com", Role: "user", Kind: models.UserKindService},
+	} {
+		_ = users.Create(context.Background(), u)
+	}
+	_ = projects.Create(context.Background(), &models.Project{ID: "p1", OwnerID: "u1", Name: "P1"})
+
+	return tokens, users, projects, NewAccessTokenService(tokens, users, projects)
+}
+
+func TestAccessTokenService_CreateAndAuthenticate(t *testing.T) {
+	tokens, _, _, svc := newAccessTokenFixture(t)
+	u1 := models.User{ID: "u1", Role: "user"}
+
+	plaintext, token, err := svc.Create(context.Background(), u1, "u1", CreateAccessTokenInput{
+		Name:   "ci",
+		Scopes: []string{models.ScopeTasksRead},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasPrefix(plaintext, models.AccessTokenPrefix) || !strings.HasPrefix(plaintext, token.Prefix) {
+		t.Fatalf("plaintext %q does not start with prefix %q", plaintext, token.Prefix)
+	}
+	if token.TokenHash == plaintext || strings.Contains(token.TokenHash, plaintext) {
+		t.Fatalf("token stored in plaintext")
+	}
+
+	user, got, err := svc.Authenticate(context.Background(), plaintext)
+	if err != nil || user == nil {
+		t.Fatalf("Authenticate() = %v, %v", user, err)
+	}
+	if user.ID != "u1" || got.ID != token.ID {
+		t.Fatalf("Authenticate() resolved user %q token %q", user.ID, got.ID)
+	}
+	if tokens.byID[token.ID].LastUsedAt == nil {
+		t.Fatalf("last_used_at not recorded")
+	}
+}
+
+func TestAccessTokenService_AuthenticateRejectsExpiredAndRevoked(t *testing.T) {
+	_, _, _, svc := newAccessTokenFixture(t)
+	u1 := models.User{ID: "u1", Role: "user"}
+
+	expiring, _, err := svc.Create(context.Background(), u1, "u1", CreateAccessTokenInput{
+		Name: "short", Scopes: []string{models.ScopeTasksRead}, TTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	revoked, token, err := svc.Create(context.Background(), u1, "u1", CreateAccessTokenInput{
+		Name: "revoked", Scopes: []string{models.ScopeTasksRead},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := svc.Revoke(context.Background(), u1, token.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	later := time.Now().Add(2 * time.Hour)
+	svc.now = func() time.Time { return later }
+
+	for _, plaintext := range []string{expiring, revoked, models.AccessTokenPrefix + "unknown"} {
+		user, _, err := svc.Authenticate(context.Background(), plaintext)
+		if err != nil || user != nil {
+			t.Fatalf("Authenticate(%q) = %v, %v; want nil, nil", plaintext, user, err)
+		}
+	}
+}
+
+func TestAccessTokenService_CreateValidatesScopesAndOwnership(t *testing.T) {
+	_, _, _, svc := newAccessTokenFixture(t)
+	u1 := models.User{ID: "u1", Role: "user"}
+	u2 := models.User{ID: "u2", Role: "user"}
+	admin := models.User{ID: "admin", Role: "admin"}
+	p1 := "p1"
+
+	tests := []struct {
+		name    string
+		actor   models.User
+		owner   string
+		in      CreateAccessTokenInput
+		wantErr error
+	}{
+		{"unknown scope", u1, "u1", CreateAccessTokenInput{Name: "x", Scopes: []string{"tasks:delete"}}, ErrBadRequest},
+		{"no scopes", u1, "u1", CreateAccessTokenInput{Name: "x"}, ErrBadRequest},
+		{"admin scope for non-admin", u1, "u1", CreateAccessTokenInput{Name: "x", Scopes: []string{models.ScopeAdmin}}, ErrBadRequest},
+		{"ttl too long", u1, "u1", CreateAccessTokenInput{Name: "x", Scopes: []string{models.ScopeTasksRead}, TTL: 2 * MaxAccessTokenTTL}, ErrBadRequest},
+		{"someone else's project", u2, "u2", CreateAccessTokenInput{Name: "x", Scopes: []string{models.ScopeTasksRead}, ProjectID: &p1}, ErrForbidden},
+		{"token for another human", admin, "u1", CreateAccessTokenInput{Name: "x", Scopes: []string{models.ScopeTasksRead}}, ErrForbidden},
+		{"own project", u1, "u1", CreateAccessTokenInput{Name: "x", Scopes: []string{models.ScopeTasksWrite}, ProjectID: &p1}, nil},
+		{"admin for service account", admin, "bot", CreateAccessTokenInput{Name: "x", Scopes: []string{models.ScopeTasksRead}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := svc.Create(context.Background(), tt.actor, tt.owner, tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccessTokenService_RevokeHidesOtherUsersTokens(t *testing.T) {
+	_, _, _, svc := newAccessTokenFixture(t)
+	u1 := models.User{ID: "u1", Role: "user"}
+	u2 := models.User{ID: "u2", Role: "user"}
+
+	_, token, err := svc.Create(context.Background(), u1, "u1", CreateAccessTokenInput{
+		Name: "ci", Scopes: []string{models.ScopeTasksRead},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := svc.Revoke(context.Background(), u2, token.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Revoke() by other user error = %v, want ErrNotFound", err)
+	}
+}
@@ -19,6 +19,9 @@ func NewAPIUserService(repo repository.APIUserRepository) APIUserService {
 	return &apiUserService{repo: repo}
 }
 
-func (s *apiUserService) ListMinimal(ctx context.Context) ([]models.MinimalUser, error) {
+func (s *apiUserService) ListMinimal(ctx context.Context) (_ []models.MinimalUser, err error) {
+	ctx, span := startSpan(ctx, "APIUserService.ListMinimal")
+	defer func() { endSpan(span, err) }()
+
 	return s.repo.ListMinimal(ctx)
 }
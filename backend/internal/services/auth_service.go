@@ -2,13 +2,18 @@ package services
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/bcrypt"
 
+	"task-management-platform/backend/internal/cache"
 	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+	jwtutil "task-management-platform/backend/pkg/jwt"
 )
 
 type UserRepo interface {
@@ -18,15 +23,67 @@ type UserRepo interface {
 	UpdatePasswordHash(ctx context.Context, id string, passwordHash string) error
 }
 
+// Login outcomes reported to a LoginObserver. A password accepted for an
+// account with two-factor enabled is not an outcome yet; the login counts once
+// the second factor is checked.
+const (
+	LoginSucceeded          = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginInvalidCode        = "invalid_code"
+	LoginLocked             = "locked"
+)
+
+// LoginObserver is told the outcome of every login attempt, e.g. to count
+// them in metrics.
+type LoginObserver interface {
+	ObserveLogin(outcome string)
+}
+
 type AuthService struct {
-	userRepo UserRepo
+	userRepo  UserRepo
+	cache     *cache.Loader
+	guard     LoginGuard
+	twoFactor *TwoFactorService
+	observer  LoginObserver
+}
+
+// NewAuthService wires the auth flows. loader, guard and twoFactor are
+// optional: a nil loader disables cache invalidation, a nil guard disables
+// login lockout and a nil twoFactor makes every login single-step.
+func NewAuthService(userRepo UserRepo, loader *cache.Loader, guard LoginGuard, twoFactor *TwoFactorService) *AuthService {
+	return &AuthService{userRepo: userRepo, cache: loader, guard: guard, twoFactor: twoFactor}
+}
+
+// ObserveLogins reports login outcomes to o.
+func (s *AuthService) ObserveLogins(o LoginObserver) {
+	s.observer = o
+}
+
+// LoginResult is the outcome of a correct password. When ChallengeToken is
+// set the account has two-factor enabled and the login is not finished until
+// the token is exchanged with CompleteTwoFactorLogin.
+type LoginResult struct {
+	User           *models.User
+	ChallengeToken string
 }
 
-func NewAuthService(userRepo UserRepo) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func (s *AuthService) Register(ctx context.Context, email, password string) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "AuthService.Register")
+	defer func() { endSpan(span, err) }()
+
+	return s.createUser(ctx, email, password, "user")
 }
 
-func (s *AuthService) Register(ctx context.Context, email, password string) (*models.User, error) {
+// CreateAdmin creates an admin account. It is not exposed over HTTP; the
+// admin CLI uses it to bootstrap the first admin.
+func (s *AuthService) CreateAdmin(ctx context.Context, email, password string) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "AuthService.CreateAdmin")
+	defer func() { endSpan(span, err) }()
+
+	return s.createUser(ctx, email, password, "admin")
+}
+
+func (s *AuthService) createUser(ctx context.Context, email, password, role string) (*models.User, error) {
 	email = normalizeEmail(email)
 
 	if email == "" || password == "" {
@@ -47,26 +104,50 @@ func (s *AuthService) Register(ctx context.Context, email, password string) (*mo
 		ID:           uuid.NewString(),
 		Email:        email,
 		PasswordHash: string(hash),
-		Role:         "user",
+		Role:         role,
 		CreatedAt:    time.Now().UTC(),
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
+		// lost a race with a concurrent registration
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return nil, ErrEmailAlreadyExists
+		}
 		return nil, err
 	}
 
+	s.cache.Invalidate(ctx, repository.CacheKeyMinimalUsers)
+
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (*models.User, error) {
+// Login verifies credentials. clientIP feeds the lockout guard, which is
+// checked before the password so a locked account gives no signal about
+// whether a guess was right.
+func (s *AuthService) Login(ctx context.Context, email, password, clientIP string) (_ *LoginResult, err error) {
+	ctx, span := startSpan(ctx, "AuthService.Login")
+	defer func() { endSpan(span, err) }()
+
 	email = normalizeEmail(email)
 
 	if email == "" || password == "" {
+		s.observe(LoginInvalidCredentials)
 		return nil, ErrInvalidCredentials
 	}
 
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, email, clientIP); err != nil {
+			s.observe(LoginLocked)
+			return nil, err
+		}
+	}
+
 	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
+	if err != nil || user.Kind == models.UserKindService {
+		// no account to protect: only the address is counted, so guessing
+		// unknown emails cannot fill the store
+		s.recordLoginFailure(ctx, "", clientIP)
+		s.observe(LoginInvalidCredentials)
 		return nil, ErrInvalidCredentials
 	}
 
@@ -74,17 +155,107 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*model
 		[]byte(user.PasswordHash),
 		[]byte(password),
 	); err != nil {
+		s.recordLoginFailure(ctx, email, clientIP)
+		s.observe(LoginInvalidCredentials)
 		return nil, ErrInvalidCredentials
 	}
 
+	if s.twoFactor != nil && user.TOTPEnabled {
+		// The lockout history is only cleared once the second factor is
+		// verified, so guessing codes counts against the same budget.
+		challenge, err := jwtutil.GenerateChallengeToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{User: user, ChallengeToken: challenge}, nil
+	}
+
+	if s.guard != nil {
+		s.guard.RecordSuccess(ctx, email, clientIP)
+	}
+	s.observe(LoginSucceeded)
+
+	return &LoginResult{User: user}, nil
+}
+
+// CompleteTwoFactorLogin exchanges a challenge token from Login and a TOTP or
+// recovery code for the authenticated user.
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code, clientIP string) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "AuthService.CompleteTwoFactorLogin")
+	defer func() { endSpan(span, err) }()
+
+	if s.twoFactor == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	userID, err := jwtutil.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, user.Email, clientIP); err != nil {
+			s.observe(LoginLocked)
+			return nil, err
+		}
+	}
+
+	if err := s.twoFactor.Verify(ctx, user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.recordLoginFailure(ctx, user.Email, clientIP)
+			s.observe(LoginInvalidCode)
+		}
+		return nil, err
+	}
+
+	if s.guard != nil {
+		s.guard.RecordSuccess(ctx, user.Email, clientIP)
+	}
+	s.observe(LoginSucceeded)
+
 	return user, nil
 }
 
+// UnlockAccount clears the failed-login history of a user.
+func (s *AuthService) UnlockAccount(ctx context.Context, userID string) (err error) {
+	ctx, span := startSpan(ctx, "AuthService.UnlockAccount", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.guard != nil {
+		s.guard.Unlock(ctx, user.Email)
+	}
+	return nil
+}
+
+func (s *AuthService) recordLoginFailure(ctx context.Context, email, clientIP string) {
+	if s.guard != nil {
+		s.guard.RecordFailure(ctx, email, clientIP)
+	}
+}
+
+func (s *AuthService) observe(outcome string) {
+	if s.observer != nil {
+		s.observer.ObserveLogin(outcome)
+	}
+}
+
 func normalizeEmail(email string) string {
 	return strings.TrimSpace(strings.ToLower(email))
 }
 
-func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) (err error) {
+	ctx, span := startSpan(ctx, "AuthService.ChangePassword", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
 	currentPassword = strings.TrimSpace(currentPassword)
 	newPassword = strings.TrimSpace(newPassword)
 
@@ -116,3 +287,34 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPasswor
 
 	return nil
 }
+
+// ResetPassword sets a new password without knowing the current one and
+// clears the lockout tracked by this service's guard. It is for operators,
+// not for end users.
+func (s *AuthService) ResetPassword(ctx context.Context, userID, newPassword string) (err error) {
+	ctx, span := startSpan(ctx, "AuthService.ResetPassword", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	newPassword = strings.TrimSpace(newPassword)
+	if len(newPassword) < 4 {
+		return ErrBadRequest
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePasswordHash(ctx, user.ID, string(hash)); err != nil {
+		return err
+	}
+
+	if s.guard != nil {
+		s.guard.Unlock(ctx, user.Email)
+	}
+	return nil
+}
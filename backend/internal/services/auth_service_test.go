@@ -9,6 +9,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
 )
 
 type fakeUserRepo struct {
@@ -32,7 +33,7 @@ func (r *fakeUserRepo) Create(ctx context.Context, user *models.User) error {
 func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	u, ok := r.byEmail[email]
 	if !ok {
-		return nil, ErrNotFound
+		return nil, repository.ErrNotFound
 	}
 	return u, nil
 }
@@ -40,7 +41,7 @@ func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*models.Us
 func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
 	u, ok := r.byID[id]
 	if !ok {
-		return nil, ErrNotFound
+		return nil, repository.ErrNotFound
 	}
 	return u, nil
 }
@@ -56,7 +57,7 @@ func (r *fakeUserRepo) UpdatePasswordHash(ctx context.Context, id string, passwo
 
 func TestAuthService_Register_Success(t *testing.T) {
 	repo := newFakeUserRepo()
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, nil, nil, nil)
 
 	user, err := svc.Register(context.Background(), "Test@TEST.com", "1234")
 	if err != nil {
@@ -83,7 +84,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 
 func TestAuthService_Register_EmailAlreadyExists(t *testing.T) {
 	repo := newFakeUserRepo()
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, nil, nil, nil)
 
 	_, err := svc.Register(context.Background(), "test@test.com", "1234")
 	if err != nil {
@@ -98,7 +99,7 @@ func TestAuthService_Register_EmailAlreadyExists(t *testing.T) {
 
 func TestAuthService_Login_Success(t *testing.T) {
 	repo := newFakeUserRepo()
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, nil, nil, nil)
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.DefaultCost)
 	u := &models.User{
@@ -110,18 +111,21 @@ func TestAuthService_Login_Success(t *testing.T) {
 	}
 	_ = repo.Create(context.Background(), u)
 
-	got, err := svc.Login(context.Background(), "test@test.com", "1234")
+	got, err := svc.Login(context.Background(), "test@test.com", "1234", "10.0.0.1")
 	if err != nil {
 		t.Fatalf("Login() error = %v", err)
 	}
-	if got.ID != "u1" {
-		t.Fatalf("got.ID = %q, want %q", got.ID, "u1")
+	if got.User.ID != "u1" {
+		t.Fatalf("got.User.ID = %q, want %q", got.User.ID, "u1")
+	}
+	if got.ChallengeToken != "" {
+		t.Fatalf("got.ChallengeToken = %q, want empty without two-factor", got.ChallengeToken)
 	}
 }
 
 func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 	repo := newFakeUserRepo()
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, nil, nil, nil)
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.DefaultCost)
 	u := &models.User{
@@ -133,12 +137,12 @@ func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 	}
 	_ = repo.Create(context.Background(), u)
 
-	_, err := svc.Login(context.Background(), "test@test.com", "WRONG")
+	_, err := svc.Login(context.Background(), "test@test.com", "WRONG", "10.0.0.1")
 	if !errors.Is(err, ErrInvalidCredentials) {
 		t.Fatalf("Login() err = %v, want ErrInvalidCredentials", err)
 	}
 
-	_, err = svc.Login(context.Background(), "missing@test.com", "1234")
+	_, err = svc.Login(context.Background(), "missing@test.com", "1234", "10.0.0.1")
 	if !errors.Is(err, ErrInvalidCredentials) {
 		t.Fatalf("Login() err = %v, want ErrInvalidCredentials", err)
 	}
@@ -146,7 +150,7 @@ func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 
 func TestAuthService_ChangePassword_Success(t *testing.T) {
 	repo := newFakeUserRepo()
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, nil, nil, nil)
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("oldpass123"), bcrypt.DefaultCost)
 	u := &models.User{
@@ -170,7 +174,7 @@ func TestAuthService_ChangePassword_Success(t *testing.T) {
 
 func TestAuthService_ChangePassword_WrongCurrent(t *testing.T) {
 	repo := newFakeUserRepo()
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, nil, nil, nil)
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("oldpass123"), bcrypt.DefaultCost)
 	u := &models.User{
@@ -187,3 +191,77 @@ func TestAuthService_ChangePassword_WrongCurrent(t *testing.T) {
 		t.Fatalf("ChangePassword() err = %v, want ErrInvalidCredentials", err)
 	}
 }
+
+func TestAuthService_CreateAdmin(t *testing.T) {
+	repo := newFakeUserRepo()
+	svc := NewAuthService(repo, nil, nil, nil)
+
+	user, err := svc.CreateAdmin(context.Background(), "Root@Test.com", "rootpass")
+	if err != nil {
+		t.Fatalf("CreateAdmin() error = %v", err)
+	}
+	if user.Role != "admin" || user.Email != "root@test.com" {
+		t.Fatalf("CreateAdmin() = %q %q, want root@test.com admin", user.Email, user.Role)
+	}
+
+	if _, err := svc.CreateAdmin(context.Background(), "root@test.com", "other"); !errors.Is(err, ErrEmailAlreadyExists) {
+		t.Fatalf("CreateAdmin() duplicate err = %v, want ErrEmailAlreadyExists", err)
+	}
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	repo := newFakeUserRepo()
+	svc := NewAuthService(repo, nil, nil, nil)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("forgotten"), bcrypt.DefaultCost)
+	_ = repo.Create(context.Background(), &models.User{
+		ID:           "u1",
+		Email:        "test@test.com",
+		PasswordHash: string(hash),
+		Role:         "user",
+	})
+
+	if err := svc.ResetPassword(context.Background(), "u1", "abc"); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("ResetPassword() short password err = %v, want ErrBadRequest", err)
+	}
+	if err := svc.ResetPassword(context.Background(), "u1", "newpass123"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	updated, _ := repo.GetByID(context.Background(), "u1")
+	if err := bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("newpass123")); err != nil {
+		t.Fatalf("password was not updated")
+	}
+}
+
+type recordingLoginObserver []string
+
+func (o *recordingLoginObserver) ObserveLogin(outcome string) {
+	*o = append(*o, outcome)
+}
+
+func TestAuthService_Login_ReportsOutcomes(t *testing.T) {
+	repo := newFakeUserRepo()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.MinCost)
+	_ = repo.Create(context.Background(), &models.User{ID: "u1", Email: "test@test.com", PasswordHash: string(hash), Role: "user"})
+
+	svc := NewAuthService(repo, nil, nil, nil)
+	var outcomes recordingLoginObserver
+	svc.ObserveLogins(&outcomes)
+
+	_, _ = svc.Login(context.Background(), "test@test.com", "wrong", "10.0.0.1")
+	_, _ = svc.Login(context.Background(), "nobody@test.com", "1234", "10.0.0.1")
+	if _, err := svc.Login(context.Background(), "test@test.com", "1234", "10.0.0.1"); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	want := []string{LoginInvalidCredentials, LoginInvalidCredentials, LoginSucceeded}
+	if len(outcomes) != len(want) {
+		t.Fatalf("outcomes = %v, want %v", outcomes, want)
+	}
+	for i := range want {
+		if outcomes[i] != want[i] {
+			t.Fatalf("outcomes = %v, want %v", outcomes, want)
+		}
+	}
+}
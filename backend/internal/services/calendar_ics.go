@@ -0,0 +1,107 @@
+package services
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/pkg/ical"
+)
+
+// CalendarComponent selects how tasks appear in a feed. Events show up in
+// every calendar app; to-dos only in apps with task lists.
+type CalendarComponent string
+
+const (
+	CalendarEvents CalendarComponent = "event"
+	CalendarTodos  CalendarComponent = "todo"
+)
+
+const (
+	calendarProdID = "-//Task Management Platform//Calendar Feed//EN"
+	calendarUIDTag = "@task-management-platform"
+	// calendarRefresh is the polling interval suggested to clients.
+	calendarRefresh = "PT1H"
+)
+
+var todoStatuses = map[string]string{
+	"todo":        "NEEDS-ACTION",
+	"in_progress": "IN-PROCESS",
+	"done":        "COMPLETED",
+}
+
+// WriteCalendarFeed renders tasks as an RFC 5545 calendar with one all-day
+// entry per task on its due date.
+func WriteCalendarFeed(w io.Writer, name string, component CalendarComponent, tasks []models.CalendarTask, now time.Time) error {
+	cal := ical.NewWriter(w)
+
+	cal.Begin("VCALENDAR")
+	cal.Prop("VERSION", "2.0")
+	cal.Text("PRODID", calendarProdID)
+	cal.Prop("CALSCALE", "GREGORIAN")
+	cal.Prop("METHOD", "PUBLISH")
+	cal.Text("X-WR-CALNAME", name)
+	cal.Prop("REFRESH-INTERVAL", calendarRefresh, "VALUE", "DURATION")
+	cal.Prop("X-PUBLISHED-TTL", calendarRefresh)
+
+	for _, t := range tasks {
+		if t.DueDate == nil {
+			continue
+		}
+		due := *t.DueDate
+
+		if component == CalendarTodos {
+			cal.Begin("VTODO")
+		} else {
+			cal.Begin("VEVENT")
+		}
+		cal.Prop("UID", t.ID.String()+calendarUIDTag)
+		cal.DateTime("DTSTAMP", now)
+		cal.DateTime("CREATED", t.CreatedAt)
+		cal.DateTime("LAST-MODIFIED", t.UpdatedAt)
+		cal.Text("SUMMARY", calendarSummary(t, component))
+		cal.Text("DESCRIPTION", calendarDescription(t))
+		cal.Text("CATEGORIES", t.ProjectName)
+
+		if component == CalendarTodos {
+			cal.Date("DUE", due)
+			cal.Prop("STATUS", todoStatuses[t.Status])
+			if t.Status == "done" {
+				cal.Prop("PERCENT-COMPLETE", "100")
+				cal.DateTime("COMPLETED", t.UpdatedAt)
+			}
+			cal.End("VTODO")
+		} else {
+			cal.Date("DTSTART", due)
+			cal.Date("DTEND", due.AddDate(0, 0, 1))
+			cal.Prop("TRANSP", "TRANSPARENT")
+			cal.End("VEVENT")
+		}
+	}
+
+	cal.End("VCALENDAR")
+	return cal.Flush()
+}
+
+// calendarSummary marks finished tasks in event feeds, which have no
+// completion status of their own.
+func calendarSummary(t models.CalendarTask, component CalendarComponent) string {
+	if component != CalendarTodos && t.Status == "done" {
+		return "✓ " + t.Title
+	}
+	return t.Title
+}
+
+func calendarDescription(t models.CalendarTask) string {
+	var b strings.Builder
+	if t.Description != "" {
+		b.WriteString(t.Description)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Project: ")
+	b.WriteString(t.ProjectName)
+	b.WriteString("\nStatus: ")
+	b.WriteString(t.Status)
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+const (
+	// MaxCalendarFeedProjects bounds how many whole projects one feed adds.
+	MaxCalendarFeedProjects = 20
+
+	defaultCalendarFeedName = "My tasks"
+)
+
+type CreateCalendarFeedInput struct {
+	Name       string
+	ProjectIDs []string
+}
+
+// ProjectViewer looks up a project as a given user; *ProjectService
+// implements it with the project visibility rule.
+type ProjectViewer interface {
+	GetByID(ctx context.Context, requesterID, requesterRole, projectID string) (*models.Project, error)
+}
+
+// CalendarService manages secret calendar feed URLs. Like access tokens, only
+// a hash of each feed token is stored and the plaintext is shown once.
+type CalendarService struct {
+	feeds    repository.CalendarFeedRepository
+	users    AccessTokenUserRepo
+	projects ProjectViewer
+	now      func() time.Time
+}
+
+func NewCalendarService(feeds repository.CalendarFeedRepository, users AccessTokenUserRepo, projects ProjectViewer) *CalendarService {
+	return &CalendarService{feeds: feeds, users: users, projects: projects, now: time.Now}
+}
+
+// Create issues a feed for the actor. Included projects must be visible to
+// the actor.
+func (s *CalendarService) Create(ctx context.Context, actor models.User, in CreateCalendarFeedInput) (_ string, _ *models.CalendarFeed, err error) {
+	ctx, span := startSpan(ctx, "CalendarService.Create")
+	defer func() { endSpan(span, err) }()
+
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		name = defaultCalendarFeedName
+	}
+
+	projectIDs := make([]string, 0, len(in.ProjectIDs))
+	for _, id := range in.ProjectIDs {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return "", nil, ErrBadRequest
+		}
+		if !slices.Contains(projectIDs, parsed.String()) {
+			projectIDs = append(projectIDs, parsed.String())
+		}
+	}
+	if len(projectIDs) > MaxCalendarFeedProjects {
+		return "", nil, ErrBadRequest
+	}
+
+	for _, id := range projectIDs {
+		if _, err := s.projects.GetByID(ctx, actor.ID, actor.Role, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return "", nil, ErrBadRequest
+			}
+			return "", nil, err
+		}
+	}
+
+	plaintext, err := newCalendarFeedSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	feed := &models.CalendarFeed{
+		ID:         uuid.NewString(),
+		UserID:     actor.ID,
+		Name:       name,
+		Prefix:     plaintext[:len(models.CalendarFeedPrefix)+8],
+		TokenHash:  hashAccessToken(plaintext),
+		ProjectIDs: projectIDs,
+		CreatedAt:  s.now().UTC(),
+	}
+	if err := s.feeds.Create(ctx, feed); err != nil {
+		return "", nil, err
+	}
+	return plaintext, feed, nil
+}
+
+func (s *CalendarService) List(ctx context.Context, actor models.User) (_ []models.CalendarFeed, err error) {
+	ctx, span := startSpan(ctx, "CalendarService.List")
+	defer func() { endSpan(span, err) }()
+
+	return s.feeds.ListByUser(ctx, actor.ID)
+}
+
+// Revoke disables a feed URL for good. Owners revoke their own feeds; admins
+// can revoke any.
+func (s *CalendarService) Revoke(ctx context.Context, actor models.User, feedID string) (err error) {
+	ctx, span := startSpan(ctx, "CalendarService.Revoke", attribute.String("calendar_feed.id", feedID))
+	defer func() { endSpan(span, err) }()
+
+	feed, err := s.feeds.GetByID(ctx, feedID)
+	if err != nil {
+		return err
+	}
+	if feed.UserID != actor.ID && actor.Role != roleAdmin {
+		return repository.ErrNotFound
+	}
+	return s.feeds.Revoke(ctx, feedID)
+}
+
+// Tasks resolves a feed token to the feed's name and tasks. Unknown and
+// revoked tokens yield repository.ErrNotFound. Project access is checked again
+// on every fetch, so a feed stops showing a project its owner lost access to.
+func (s *CalendarService) Tasks(ctx context.Context, plaintext string) (_ *models.CalendarFeed, _ []models.CalendarTask, err error) {
+	ctx, span := startSpan(ctx, "CalendarService.Tasks")
+	defer func() { endSpan(span, err) }()
+
+	if !strings.HasPrefix(plaintext, models.CalendarFeedPrefix) {
+		return nil, nil, repository.ErrNotFound
+	}
+	feed, err := s.feeds.GetByHash(ctx, hashAccessToken(plaintext))
+	if err != nil {
+		return nil, nil, err
+	}
+	if feed.RevokedAt != nil {
+		return nil, nil, repository.ErrNotFound
+	}
+
+	user, err := s.users.GetByID(ctx, feed.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	visible := make([]string, 0, len(feed.ProjectIDs))
+	for _, id := range feed.ProjectIDs {
+		_, err := s.projects.GetByID(ctx, user.ID, user.Role, id)
+		if errors.Is(err, repository.ErrNotFound) || errors.Is(err, ErrForbidden) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		visible = append(visible, id)
+	}
+
+	tasks, err := s.feeds.ListTasks(ctx, user.ID, visible)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.now()
+	if feed.LastUsedAt == nil || now.Sub(*feed.LastUsedAt) >= lastUsedResolution {
+		// best effort: failing to record usage must not fail the request
+		_ = s.feeds.TouchLastUsed(ctx, feed.ID, now.UTC())
+	}
+
+	return feed, tasks, nil
+}
+
+func newCalendarFeedSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return models.CalendarFeedPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
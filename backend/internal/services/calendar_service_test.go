@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+type fakeCalendarFeedRepo struct {
+	feeds map[string]*models.CalendarFeed
+	tasks []models.CalendarTask
+	// lastProjects records the projects passed to ListTasks.
+	lastProjects []string
+}
+
+func (f *fakeCalendarFeedRepo) Create(ctx context.Context, feed *models.CalendarFeed) error {
+	f.feeds[feed.ID] = feed
+	return nil
+}
+
+func (f *fakeCalendarFeedRepo) GetByID(ctx context.Context, id string) (*models.CalendarFeed, error) {
+	feed, ok := f.feeds[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return feed, nil
+}
+
+func (f *fakeCalendarFeedRepo) GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	for _, feed := range f.feeds {
+		if feed.TokenHash == tokenHash {
+			return feed, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeCalendarFeedRepo) ListByUser(ctx context.Context, userID string) ([]models.CalendarFeed, error) {
+	out := make([]models.CalendarFeed, 0)
+	for _, feed := range f.feeds {
+		if feed.UserID == userID {
+			out = append(out, *feed)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCalendarFeedRepo) Revoke(ctx context.Context, id string) error {
+	feed, ok := f.feeds[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	feed.RevokedAt = &now
+	return nil
+}
+
+func (f *fakeCalendarFeedRepo) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	f.feeds[id].LastUsedAt = &at
+	return nil
+}
+
+func (f *fakeCalendarFeedRepo) ListTasks(ctx context.Context, userID string, projectIDs []string) ([]models.CalendarTask, error) {
+	f.lastProjects = projectIDs
+	out := make([]models.CalendarTask, 0)
+	for _, t := range f.tasks {
+		assigned := t.AssigneeID != nil && t.AssigneeID.String() == userID
+		if t.DueDate != nil && (assigned || slices.Contains(projectIDs, t.ProjectID.String())) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func newCalendarFixture(t *testing.T) (*CalendarService, *fakeCalendarFeedRepo, *fakeProjectRepo, string) {
+	t.Helper()
+
+	users := newFakeUserRepo()
+	_ = users.Create(context.Background(), &models.User{ID: "u1", Email: "u1@test.com", Role: "user"})
+	projects := newFakeProjectRepo()
+	projectID := uuid.NewString()
+	_ = projects.Create(context.Background(), &models.Project{ID: projectID, OwnerID: "u1", Name: "Launch"})
+
+	feeds := &fakeCalendarFeedRepo{feeds: map[string]*models.CalendarFeed{}}
+	return NewCalendarService(feeds, users, NewProjectService(projects)), feeds, projects, projectID
+}
+
+func TestCalendarService_CreateAndResolve(t *testing.T) {
+	svc, feeds, _, projectID := newCalendarFixture(t)
+	u1 := models.User{ID: "u1", Role: "user"}
+
+	plaintext, feed, err := svc.Create(context.Background(), u1, CreateCalendarFeedInput{ProjectIDs: []string{projectID, projectID}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(plaintext, models.CalendarFeedPrefix) || feed.TokenHash == plaintext {
+		t.Fatalf("expected a prefixed token stored only as a hash")
+	}
+	if feed.Name != defaultCalendarFeedName || len(feed.ProjectIDs) != 1 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	got, _, err := svc.Tasks(context.Background(), plaintext)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.ID != feed.ID || !slices.Equal(feeds.lastProjects, []string{projectID}) {
+		t.Fatalf("expected feed with its project, got %+v and %v", got, feeds.lastProjects)
+	}
+	if feeds.feeds[feed.ID].LastUsedAt == nil {
+		t.Fatalf("expected last used time to be recorded")
+	}
+
+	if _, _, err := svc.Tasks(context.Background(), models.CalendarFeedPrefix+"wrong"); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for an unknown token, got %v", err)
+	}
+}
+
+func TestCalendarService_Create_RejectsInvisibleProjects(t *testing.T) {
+	svc, _, _, projectID := newCalendarFixture(t)
+
+	other := models.User{ID: "u2", Role: "user"}
+	if _, _, err := svc.Create(context.Background(), other, CreateCalendarFeedInput{ProjectIDs: []string{projectID}}); err != ErrForbidden {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, _, err := svc.Create(context.Background(), other, CreateCalendarFeedInput{ProjectIDs: []string{uuid.NewString()}}); err != ErrBadRequest {
+		t.Fatalf("expected ErrBadRequest for unknown project, got %v", err)
+	}
+	admin := models.User{ID: "admin", Role: "admin"}
+	if _, _, err := svc.Create(context.Background(), admin, CreateCalendarFeedInput{ProjectIDs: []string{projectID}}); err != nil {
+		t.Fatalf("expected admin to include any project, got %v", err)
+	}
+}
+
+func TestCalendarService_RevokeAndLostAccess(t *testing.T) {
+	svc, feeds, projects, projectID := newCalendarFixture(t)
+	u1 := models.User{ID: "u1", Role: "user"}
+
+	plaintext, feed, err := svc.Create(context.Background(), u1, CreateCalendarFeedInput{ProjectIDs: []string{projectID}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// the project changes hands: the feed keeps working without it
+	p := projects.projects[projectID]
+	p.OwnerID = "u2"
+	projects.projects[projectID] = p
+	if _, _, err := svc.Tasks(context.Background(), plaintext); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(feeds.lastProjects) != 0 {
+		t.Fatalf("expected the project to be dropped, got %v", feeds.lastProjects)
+	}
+
+	if err := svc.Revoke(context.Background(), models.User{ID: "u2", Role: "user"}, feed.ID); err != repository.ErrNotFound {
+		t.Fatalf("expected other users to get ErrNotFound, got %v", err)
+	}
+	if err := svc.Revoke(context.Background(), u1, feed.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, _, err := svc.Tasks(context.Background(), plaintext); err != repository.ErrNotFound {
+		t.Fatalf("expected revoked feed to be gone, got %v", err)
+	}
+}
+
+func TestWriteCalendarFeed(t *testing.T) {
+	due := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 3, 30, 9, 0, 0, 0, time.UTC)
+	tasks := []models.CalendarTask{
+		{Task: models.Task{ID: uuid.New(), Title: "Ship, finally", Status: "done", DueDate: &due, UpdatedAt: updated}, ProjectName: "Launch"},
+		{Task: models.Task{ID: uuid.New(), Title: "No date", Status: "todo"}, ProjectName: "Launch"},
+	}
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	var events bytes.Buffer
+	if err := WriteCalendarFeed(&events, "My tasks", CalendarEvents, tasks, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	out := events.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n", "VERSION:2.0\r\n", "X-WR-CALNAME:My tasks\r\n",
+		"DTSTART;VALUE=DATE:20260401\r\n", "DTEND;VALUE=DATE:20260402\r\n",
+		"SUMMARY:✓ Ship\\, finally\r\n", "END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in feed:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 1 {
+		t.Fatalf("expected tasks without a due date to be skipped:\n%s", out)
+	}
+
+	var todos bytes.Buffer
+	if err := WriteCalendarFeed(&todos, "My tasks", CalendarTodos, tasks, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{"BEGIN:VTODO\r\n", "DUE;VALUE=DATE:20260401\r\n", "STATUS:COMPLETED\r\n", "COMPLETED:20260330T090000Z\r\n"} {
+		if !strings.Contains(todos.String(), want) {
+			t.Fatalf("expected %q in feed:\n%s", want, todos.String())
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrForbidden                = errors.New("forbidden")
@@ -13,4 +16,56 @@ var (
 	ErrJWTSecretNotSet          = errors.New("JWT_SECRET is not set")
 	ErrBadRequest               = errors.New("bad request")
 	ErrCannotUpdateOwnRole      = errors.New("cannot update own role")
+	ErrLoginLocked              = errors.New("too many failed login attempts")
+	ErrTwoFactorAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode     = errors.New("invalid two-factor code")
+	ErrOIDCState                = errors.New("invalid or expired sign-in state")
+	ErrOIDCExchange             = errors.New("identity provider sign-in failed")
+	ErrOIDCEmailNotVerified     = errors.New("identity provider did not supply a verified email")
+	ErrOIDCAccountExists        = errors.New("an account with this email already exists; sign in with your password and link single sign-on from your account page")
+	ErrOIDCIdentityLinked       = errors.New("this identity provider account is linked to another user")
+	ErrOIDCEmailMismatch        = errors.New("identity provider email does not match this account")
+	ErrProjectArchived          = errors.New("project is archived and read-only")
+	ErrUserOwnsProjects         = errors.New("user owns projects; name a user to transfer them to")
 )
+
+// FieldError explains why one input field was rejected. Field uses the
+// field's JSON name.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ValidationError lists the fields that failed validation. It matches
+// ErrBadRequest, so callers that only distinguish bad input keep working.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "invalid input: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrBadRequest
+}
+
+// add records a failed field; validators return e.err() once done.
+func (e *ValidationError) add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// err returns nil when no field failed, so a validator can end with
+// "return v.err()".
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidExportLink covers download links that are forged, expired or
+// point at a result that is gone.
+var ErrInvalidExportLink = errors.New("invalid or expired download link")
+
+// maxConcurrentExports bounds how many background exports hold a database
+// connection at once; further jobs wait in the queued state.
+const maxConcurrentExports = 2
+
+type ExportJobStatus string
+
+const (
+	ExportQueued  ExportJobStatus = "queued"
+	ExportRunning ExportJobStatus = "running"
+	ExportDone    ExportJobStatus = "done"
+	ExportFailed  ExportJobStatus = "failed"
+)
+
+type ExportJob struct {
+	ID          string          `json:"id"`
+	Status      ExportJobStatus `json:"status"`
+	Request     ExportRequest   `json:"request"`
+	RequestedBy string          `json:"requestedBy"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+	Size        int64           `json:"size,omitempty"`
+	Error       string          `json:"error,omitempty"`
+
+	path string
+}
+
+// ExportJobs runs exports in the background and keeps each result on disk
+// for the retention period. Jobs live in this process only, so status and
+// downloads must reach the replica that started the job. Files no job owns,
+// such as those of an earlier process, are deleted once past the retention
+// period too.
+type ExportJobs struct {
+	exports   *ExportService
+	dir       string
+	secret    []byte
+	linkTTL   time.Duration
+	retention time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	jobs  map[string]*ExportJob
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewExportJobs(exports *ExportService, dir string, secret []byte, linkTTL, retention time.Duration) (*ExportJobs, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	j := &ExportJobs{
+		exports:   exports,
+		dir:       dir,
+		secret:    secret,
+		linkTTL:   linkTTL,
+		retention: retention,
+		now:       time.Now,
+		jobs:      map[string]*ExportJob{},
+		slots:     make(chan struct{}, maxConcurrentExports),
+	}
+	j.sweep()
+	return j, nil
+}
+
+// RunSweeper deletes expired results every interval until ctx is done, so
+// they go even when no request comes in to trigger a sweep.
+func (j *ExportJobs) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		j.sweep()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Start queues an export and returns immediately.
+func (j *ExportJobs) Start(actorID string, req ExportRequest) (ExportJob, error) {
+	if err := req.Normalize(); err != nil {
+		return ExportJob{}, err
+	}
+	j.sweep()
+
+	job := &ExportJob{
+		ID:          uuid.NewString(),
+		Status:      ExportQueued,
+		Request:     req,
+		RequestedBy: actorID,
+		CreatedAt:   j.now().UTC(),
+	}
+
+	j.mu.Lock()
+	j.jobs[job.ID] = job
+	snapshot := *job
+	j.mu.Unlock()
+
+	j.wg.Add(1)
+	go j.run(job)
+
+	return snapshot, nil
+}
+
+// Get returns a copy of the job, if it is still known.
+func (j *ExportJobs) Get(id string) (ExportJob, bool) {
+	j.sweep()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job, ok := j.jobs[id]
+	if !ok {
+		return ExportJob{}, false
+	}
+	return *job, true
+}
+
+// Link signs a download link for a finished job. It expires after the link
+// TTL or when the result is swept, whichever comes first.
+func (j *ExportJobs) Link(job ExportJob) (expires time.Time, signature string) {
+	expires = j.now().Add(j.linkTTL)
+	if job.FinishedAt != nil {
+		if end := job.FinishedAt.Add(j.retention); end.Before(expires) {
+			expires = end
+		}
+	}
+	expires = expires.Truncate(time.Second)
+	return expires, j.sign(job.ID, expires.Unix())
+}
+
+// Open checks a download link and opens the result for reading.
+func (j *ExportJobs) Open(id, expires, signature string) (*os.File, ExportJob, error) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(j.sign(id, unix))) {
+		return nil, ExportJob{}, ErrInvalidExportLink
+	}
+	if !j.now().Before(time.Unix(unix, 0)) {
+		return nil, ExportJob{}, ErrInvalidExportLink
+	}
+
+	job, ok := j.Get(id)
+	if !ok || job.Status != ExportDone {
+		return nil, ExportJob{}, ErrInvalidExportLink
+	}
+
+	f, err := os.Open(job.path)
+	if err != nil {
+		return nil, ExportJob{}, err
+	}
+	return f, job, nil
+}
+
+// Wait blocks until running jobs have finished.
+func (j *ExportJobs) Wait() {
+	j.wg.Wait()
+}
+
+func (j *ExportJobs) run(job *ExportJob) {
+	defer j.wg.Done()
+
+	j.slots <- struct{}{}
+	defer func() { <-j.slots }()
+
+	j.update(job, func() { job.Status = ExportRunning })
+
+	path := filepath.Join(j.dir, job.ID+".zip")
+	size, err := j.write(path, job.Request)
+
+	finished := j.now().UTC()
+	j.update(job, func() {
+		job.FinishedAt = &finished
+		if err != nil {
+			slog.Error("export job failed", "job_id", job.ID, "user_id", job.RequestedBy, "error", err)
+			job.Status = ExportFailed
+			job.Error = "export failed"
+			return
+		}
+		job.Status = ExportDone
+		job.Size = size
+		job.path = path
+	})
+}
+
+// write produces the archive under a temporary name so a crash never
+// leaves a truncated file that looks finished.
+func (j *ExportJobs) write(path string, req ExportRequest) (int64, error) {
+	tmp := path + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, err
+	}
+
+	err = j.exports.WriteArchive(context.Background(), f, req)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (j *ExportJobs) update(job *ExportJob, fn func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn()
+}
+
+// sweep forgets finished jobs older than the retention period and deletes
+// their files, along with results no job owns that were last written before
+// the cutoff.
+func (j *ExportJobs) sweep() {
+	cutoff := j.now().Add(-j.retention)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for id, job := range j.jobs {
+		if job.FinishedAt == nil || job.FinishedAt.After(cutoff) {
+			continue
+		}
+		if job.path != "" {
+			_ = os.Remove(job.path)
+		}
+		delete(j.jobs, id)
+	}
+
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		slog.Warn("sweep export directory", "dir", j.dir, "error", err)
+		return
+	}
+	for _, e := range entries {
+		// the directory may be shared with other storage; only results and
+		// their temporary files are ours
+		id, ok := strings.CutSuffix(e.Name(), ".zip")
+		if !ok {
+			id, ok = strings.CutSuffix(e.Name(), ".zip.part")
+		}
+		if _, err := uuid.Parse(id); !ok || err != nil || j.jobs[id] != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(j.dir, e.Name()))
+	}
+}
+
+func (j *ExportJobs) sign(id string, expires int64) string {
+	mac := hmac.New(sha256.New, j.secret)
+	mac.Write([]byte(id + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
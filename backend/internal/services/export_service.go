@@ -0,0 +1,279 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportJSON   ExportFormat = "json"
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// ExportEntities are the files an export can contain, in archive order.
+var ExportEntities = []string{"users", "projects", "tasks", "checklist_items"}
+
+// ExportRequest selects what an export contains. Empty Entities means all of
+// them; the date range applies to created_at and is half-open, [From, To).
+// Projects, parent tasks and users that exported rows refer to are included
+// whatever their created_at, so a filtered export restores as-is.
+type ExportRequest struct {
+	Format    ExportFormat `json:"format"`
+	Entities  []string     `json:"entities"`
+	ProjectID *string      `json:"projectId,omitempty"`
+	From      *time.Time   `json:"from,omitempty"`
+	To        *time.Time   `json:"to,omitempty"`
+}
+
+// Normalize fills in defaults and rejects unknown formats, entities and
+// inverted date ranges with ErrBadRequest.
+func (r *ExportRequest) Normalize() error {
+	if r.Format == "" {
+		r.Format = ExportCSV
+	}
+	if r.Format != ExportCSV && r.Format != ExportJSON && r.Format != ExportNDJSON {
+		return ErrBadRequest
+	}
+
+	if len(r.Entities) == 0 {
+		r.Entities = slices.Clone(ExportEntities)
+	}
+	for _, e := range r.Entities {
+		if !slices.Contains(ExportEntities, e) {
+			return ErrBadRequest
+		}
+	}
+	// keep archive order stable whatever order was asked for
+	r.Entities = slices.DeleteFunc(slices.Clone(ExportEntities), func(e string) bool {
+		return !slices.Contains(r.Entities, e)
+	})
+
+	if r.ProjectID != nil {
+		if _, err := uuid.Parse(*r.ProjectID); err != nil {
+			return ErrBadRequest
+		}
+	}
+	if r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return ErrBadRequest
+	}
+	return nil
+}
+
+func (r ExportRequest) filter() repository.ExportFilter {
+	return repository.ExportFilter{ProjectID: r.ProjectID, From: r.From, To: r.To}
+}
+
+type ExportSource interface {
+	StreamUsers(ctx context.Context, f repository.ExportFilter, fn func(models.User) error) error
+	StreamProjects(ctx context.Context, f repository.ExportFilter, fn func(models.Project) error) error
+	StreamTasks(ctx context.Context, f repository.ExportFilter, fn func(models.Task) error) error
+	StreamChecklistItems(ctx context.Context, f repository.ExportFilter, fn func(models.ChecklistItem) error) error
+}
+
+// ExportService writes admin exports as ZIP archives, one file per entity,
+// streaming rows from the database as it goes. CSV files keep the columns
+// the importer reads.
+type ExportService struct {
+	source ExportSource
+	now    func() time.Time
+}
+
+func NewExportService(source ExportSource) *ExportService {
+	return &ExportService{source: source, now: time.Now}
+}
+
+// exportUser leaves out credentials and two-factor state.
+type exportUser struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WriteArchive streams the export to w. On error the archive is left
+// unterminated, so a partial export cannot pass for a complete one.
+func (s *ExportService) WriteArchive(ctx context.Context, w io.Writer, req ExportRequest) (err error) {
+	ctx, span := startSpan(ctx, "ExportService.WriteArchive")
+	defer func() { endSpan(span, err) }()
+
+	if err := req.Normalize(); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if err := s.writeMeta(zw, req); err != nil {
+		return err
+	}
+
+	filter := req.filter()
+	for _, entity := range req.Entities {
+		var err error
+		switch entity {
+		case "users":
+			err = writeEntity(zw, entity, req.Format,
+				[]string{"id", "email", "role", "kind", "created_at"},
+				func(u models.User) ([]string, any) {
+					return []string{u.ID, u.Email, u.Role, u.Kind, formatExportTime(u.CreatedAt)},
+						exportUser{ID: u.ID, Email: u.Email, Role: u.Role, Kind: u.Kind, CreatedAt: u.CreatedAt}
+				},
+				func(fn func(models.User) error) error { return s.source.StreamUsers(ctx, filter, fn) })
+		case "projects":
+			err = writeEntity(zw, entity, req.Format,
+				[]string{"id", "name", "owner_id", "created_at", "archived_at"},
+				func(p models.Project) ([]string, any) {
+					archived := ""
+					if p.ArchivedAt != nil {
+						archived = formatExportTime(*p.ArchivedAt)
+					}
+					return []string{p.ID, p.Name, p.OwnerID, formatExportTime(p.CreatedAt), archived}, p
+				},
+				func(fn func(models.Project) error) error { return s.source.StreamProjects(ctx, filter, fn) })
+		case "tasks":
+			err = writeEntity(zw, entity, req.Format,
+				[]string{"id", "project_id", "parent_id", "title", "description", "status", "assignee_id", "due_date", "created_at", "updated_at"},
+				func(t models.Task) ([]string, any) {
+					parent, assignee, due := "", "", ""
+					if t.ParentID != nil {
+						parent = t.ParentID.String()
+					}
+					if t.AssigneeID != nil {
+						assignee = t.AssigneeID.String()
+					}
+					if t.DueDate != nil {
+						due = t.DueDate.Format(time.DateOnly)
+					}
+					return []string{
+						t.ID.String(), t.ProjectID.String(), parent, t.Title, t.Description, t.Status, assignee, due,
+						formatExportTime(t.CreatedAt), formatExportTime(t.UpdatedAt),
+					}, t
+				},
+				func(fn func(models.Task) error) error { return s.source.StreamTasks(ctx, filter, fn) })
+		case "checklist_items":
+			err = writeEntity(zw, entity, req.Format,
+				[]string{"id", "task_id", "title", "done", "position", "created_at", "updated_at"},
+				func(c models.ChecklistItem) ([]string, any) {
+					return []string{
+						c.ID.String(), c.TaskID.String(), c.Title, strconv.FormatBool(c.Done), strconv.Itoa(c.Position),
+						formatExportTime(c.CreatedAt), formatExportTime(c.UpdatedAt),
+					}, c
+				},
+				func(fn func(models.ChecklistItem) error) error {
+					return s.source.StreamChecklistItems(ctx, filter, fn)
+				})
+		}
+		if err != nil {
+			return fmt.Errorf("export %s: %w", entity, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func (s *ExportService) writeMeta(zw *zip.Writer, req ExportRequest) error {
+	exportedAt := s.now().UTC()
+
+	if req.Format == ExportCSV {
+		f, err := zw.Create("meta.csv")
+		if err != nil {
+			return err
+		}
+		w := csv.NewWriter(f)
+		_ = w.Write([]string{"exported_at_utc"})
+		_ = w.Write([]string{exportedAt.Format(time.RFC3339)})
+		w.Flush()
+		return w.Error()
+	}
+
+	f, err := zw.Create("meta.json")
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(struct {
+		ExportedAt time.Time     `json:"exportedAt"`
+		Request    ExportRequest `json:"request"`
+	}{exportedAt, req})
+}
+
+// writeEntity writes one archive file. row returns the CSV columns and the
+// JSON record of an item.
+func writeEntity[T any](
+	zw *zip.Writer,
+	entity string,
+	format ExportFormat,
+	columns []string,
+	row func(T) ([]string, any),
+	each func(fn func(T) error) error,
+) error {
+	f, err := zw.Create(entity + "." + string(format))
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case ExportCSV:
+		w := csv.NewWriter(f)
+		if err := w.Write(columns); err != nil {
+			return err
+		}
+		if err := each(func(item T) error {
+			values, _ := row(item)
+			return w.Write(values)
+		}); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+
+	case ExportNDJSON:
+		enc := json.NewEncoder(f)
+		return each(func(item T) error {
+			_, record := row(item)
+			return enc.Encode(record)
+		})
+
+	default:
+		if _, err := io.WriteString(f, "["); err != nil {
+			return err
+		}
+		first := true
+		if err := each(func(item T) error {
+			_, record := row(item)
+			b, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(f, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			_, err = f.Write(b)
+			return err
+		}); err != nil {
+			return err
+		}
+		_, err := io.WriteString(f, "]\n")
+		return err
+	}
+}
+
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
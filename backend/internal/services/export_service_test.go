@@ -0,0 +1,328 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+type fakeExportSource struct {
+	users    []models.User
+	projects []models.Project
+	tasks    []models.Task
+	items    []models.ChecklistItem
+	failOn   string
+	filters  []repository.ExportFilter
+}
+
+func newFakeExportSource() *fakeExportSource {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	archived := created.Add(24 * time.Hour)
+	parentID := uuid.MustParse(importTaskID)
+	return &fakeExportSource{
+		users: []models.User{{ID: importUserID, Email: "alice@test.com", Role: "user", Kind: "human", CreatedAt: created}},
+		projects: []models.Project{
+			{ID: importProjectID, Name: "Alpha", OwnerID: importUserID, CreatedAt: created, ArchivedAt: &archived},
+		},
+		// the subtask comes first, as one created before its parent and moved
+		// under it later would
+		tasks: []models.Task{
+			{
+				ID:        uuid.MustParse(importSubtaskID),
+				ProjectID: uuid.MustParse(importProjectID),
+				ParentID:  &parentID,
+				Title:     "Proofread",
+				Status:    "todo",
+				CreatedAt: created,
+				UpdatedAt: created,
+			},
+			{
+				ID:        parentID,
+				ProjectID: uuid.MustParse(importProjectID),
+				Title:     "Write docs",
+				Status:    "todo",
+				CreatedAt: created,
+				UpdatedAt: created,
+			},
+		},
+		items: []models.ChecklistItem{{
+			ID:        uuid.MustParse(importItemID),
+			TaskID:    uuid.MustParse(importSubtaskID),
+			Title:     "Spelling",
+			Done:      true,
+			Position:  1,
+			CreatedAt: created,
+			UpdatedAt: created,
+		}},
+	}
+}
+
+func streamFake[T any](name, failOn string, items []T, fn func(T) error) error {
+	if name == failOn {
+		return errors.New("cursor broke")
+	}
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeExportSource) StreamUsers(ctx context.Context, f repository.ExportFilter, fn func(models.User) error) error {
+	s.filters = append(s.filters, f)
+	return streamFake("users", s.failOn, s.users, fn)
+}
+
+func (s *fakeExportSource) StreamProjects(ctx context.Context, f repository.ExportFilter, fn func(models.Project) error) error {
+	s.filters = append(s.filters, f)
+	return streamFake("projects", s.failOn, s.projects, fn)
+}
+
+func (s *fakeExportSource) StreamTasks(ctx context.Context, f repository.ExportFilter, fn func(models.Task) error) error {
+	s.filters = append(s.filters, f)
+	return streamFake("tasks", s.failOn, s.tasks, fn)
+}
+
+func (s *fakeExportSource) StreamChecklistItems(ctx context.Context, f repository.ExportFilter, fn func(models.ChecklistItem) error) error {
+	s.filters = append(s.filters, f)
+	return streamFake("checklist_items", s.failOn, s.items, fn)
+}
+
+func openZip(t *testing.T, data []byte) *zip.Reader {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader error = %v", err)
+	}
+	return zr
+}
+
+func readZipFile(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("archive has no %s: %v", name, err)
+	}
+	defer f.Close()
+	b, _ := io.ReadAll(f)
+	return b
+}
+
+func TestExportService_CSVRoundTripsThroughImport(t *testing.T) {
+	svc := NewExportService(newFakeExportSource())
+
+	var buf bytes.Buffer
+	if err := svc.WriteArchive(context.Background(), &buf, ExportRequest{}); err != nil {
+		t.Fatalf("WriteArchive() error = %v", err)
+	}
+
+	archive, err := ParseExportArchive(openZip(t, buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ParseExportArchive() error = %v", err)
+	}
+	if len(archive.Users) != 1 || len(archive.Projects) != 1 || len(archive.Tasks) != 2 || len(archive.ChecklistItems) != 1 {
+		t.Fatalf("archive has %d users, %d projects, %d tasks, %d checklist items; want 1, 1, 2, 1",
+			len(archive.Users), len(archive.Projects), len(archive.Tasks), len(archive.ChecklistItems))
+	}
+
+	store := newFakeImportStore()
+	if _, err := NewImportService(store, nil).Import(context.Background(), archive, ImportOptions{}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if archived := store.projects[importProjectID].ArchivedAt; archived == nil || !archived.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("project archived at %v, want 2024-01-02", archived)
+	}
+	subtask := store.tasks[uuid.MustParse(importSubtaskID)]
+	if subtask.ParentID == nil || subtask.ParentID.String() != importTaskID {
+		t.Fatalf("subtask parent = %v, want %s", subtask.ParentID, importTaskID)
+	}
+	if item := store.items[uuid.MustParse(importItemID)]; item.TaskID != subtask.ID || !item.Done || item.Position != 1 {
+		t.Fatalf("checklist item = %+v, want done at position 1 on the subtask", item)
+	}
+}
+
+func TestExportService_JSONFormatsAndEntityFilter(t *testing.T) {
+	source := newFakeExportSource()
+	svc := NewExportService(source)
+	projectID := importProjectID
+
+	var buf bytes.Buffer
+	err := svc.WriteArchive(context.Background(), &buf, ExportRequest{
+		Format:    ExportNDJSON,
+		Entities:  []string{"tasks", "projects"},
+		ProjectID: &projectID,
+	})
+	if err != nil {
+		t.Fatalf("WriteArchive(ndjson) error = %v", err)
+	}
+
+	zr := openZip(t, buf.Bytes())
+	if _, err := zr.Open("users.ndjson"); err == nil {
+		t.Fatalf("users were exported although not requested")
+	}
+	sc := bufio.NewScanner(bytes.NewReader(readZipFile(t, zr, "tasks.ndjson")))
+	lines := 0
+	for sc.Scan() {
+		var task models.Task
+		if err := json.Unmarshal(sc.Bytes(), &task); err != nil {
+			t.Fatalf("ndjson line %q: %v", sc.Text(), err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("tasks.ndjson has %d lines, want 2", lines)
+	}
+	for _, f := range source.filters {
+		if f.ProjectID == nil || *f.ProjectID != projectID {
+			t.Fatalf("project filter not passed to the source: %+v", f)
+		}
+	}
+
+	buf.Reset()
+	if err := svc.WriteArchive(context.Background(), &buf, ExportRequest{Format: ExportJSON}); err != nil {
+		t.Fatalf("WriteArchive(json) error = %v", err)
+	}
+	var users []map[string]any
+	if err := json.Unmarshal(readZipFile(t, openZip(t, buf.Bytes()), "users.json"), &users); err != nil {
+		t.Fatalf("users.json: %v", err)
+	}
+	if len(users) != 1 || users[0]["passwordHash"] != nil {
+		t.Fatalf("users.json = %v, want one user without credentials", users)
+	}
+}
+
+func TestExportService_RejectsBadRequests(t *testing.T) {
+	svc := NewExportService(newFakeExportSource())
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	for _, req := range []ExportRequest{
+		{Format: "xml"},
+		{Entities: []string{"comments"}},
+		{From: &from, To: &to},
+	} {
+		if err := svc.WriteArchive(context.Background(), io.Discard, req); !errors.Is(err, ErrBadRequest) {
+			t.Fatalf("WriteArchive(%+v) error = %v, want ErrBadRequest", req, err)
+		}
+	}
+}
+
+func TestExportService_FailureLeavesArchiveUnterminated(t *testing.T) {
+	source := newFakeExportSource()
+	source.failOn = "tasks"
+	svc := NewExportService(source)
+
+	var buf bytes.Buffer
+	if err := svc.WriteArchive(context.Background(), &buf, ExportRequest{}); err == nil {
+		t.Fatalf("WriteArchive() error = nil, want the source error")
+	}
+	if _, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Fatalf("a failed export produced a readable archive")
+	}
+}
+
+func TestExportJobs_RunAndSignedDownload(t *testing.T) {
+	jobs, err := NewExportJobs(NewExportService(newFakeExportSource()), t.TempDir(), []byte("secret"), 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("NewExportJobs() error = %v", err)
+	}
+
+	started, err := jobs.Start("admin-1", ExportRequest{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	jobs.Wait()
+
+	job, ok := jobs.Get(started.ID)
+	if !ok || job.Status != ExportDone {
+		t.Fatalf("job = %+v, want done", job)
+	}
+
+	expires, signature := jobs.Link(job)
+	f, _, err := jobs.Open(job.ID, strconv.FormatInt(expires.Unix(), 10), signature)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	f.Close()
+
+	if _, _, err := jobs.Open(job.ID, strconv.FormatInt(expires.Unix()+60, 10), signature); !errors.Is(err, ErrInvalidExportLink) {
+		t.Fatalf("Open() with tampered expiry error = %v, want ErrInvalidExportLink", err)
+	}
+
+	later := expires.Add(time.Second)
+	jobs.now = func() time.Time { return later }
+	if _, _, err := jobs.Open(job.ID, strconv.FormatInt(expires.Unix(), 10), signature); !errors.Is(err, ErrInvalidExportLink) {
+		t.Fatalf("Open() after expiry error = %v, want ErrInvalidExportLink", err)
+	}
+}
+
+func TestExportJobs_SweepsExpiredAndOrphanedResults(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour)
+	orphan := filepath.Join(dir, uuid.NewString()+".zip")
+	partial := filepath.Join(dir, uuid.NewString()+".zip.part")
+	fresh := filepath.Join(dir, uuid.NewString()+".zip")
+	other := filepath.Join(dir, "avatar.zip")
+	for _, path := range []string{orphan, partial, fresh, other} {
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	for _, path := range []string{orphan, partial, other} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	// results left by an earlier process go at startup
+	jobs, err := NewExportJobs(NewExportService(newFakeExportSource()), dir, []byte("secret"), 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("NewExportJobs() error = %v", err)
+	}
+	for path, want := range map[string]bool{orphan: false, partial: false, fresh: true, other: true} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Fatalf("%s exists = %v, want %v", filepath.Base(path), err == nil, want)
+		}
+	}
+
+	started, err := jobs.Start("admin-1", ExportRequest{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	jobs.Wait()
+	job, _ := jobs.Get(started.ID)
+
+	// the sweeper deletes the finished result without any request coming in
+	later := time.Now().Add(2 * time.Hour)
+	jobs.now = func() time.Time { return later }
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	jobs.RunSweeper(ctx, time.Minute)
+
+	if _, err := os.Stat(job.path); !os.IsNotExist(err) {
+		t.Fatalf("expired result still on disk: %v", err)
+	}
+	if _, err := os.Stat(fresh); !os.IsNotExist(err) {
+		t.Fatalf("orphaned result past retention still on disk: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("unrelated file was deleted: %v", err)
+	}
+}
@@ -0,0 +1,726 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"task-management-platform/backend/internal/cache"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// ErrInvalidImport wraps every problem that makes an archive unimportable:
+// malformed files, unknown references, conflicting emails.
+var ErrInvalidImport = errors.New("invalid import archive")
+
+// errDryRun rolls back the import transaction once the report is complete.
+var errDryRun = errors.New("dry run")
+
+// unusablePasswordHash is stored for imported users, since exports carry no
+// password hashes. No password matches it; an operator resets it or the user
+// signs in through SSO.
+const unusablePasswordHash = "!"
+
+type ImportMode string
+
+const (
+	// ImportSkip leaves rows whose id already exists untouched.
+	ImportSkip ImportMode = "skip"
+	// ImportUpsert overwrites rows whose id already exists.
+	ImportUpsert ImportMode = "upsert"
+)
+
+type ImportOptions struct {
+	Mode   ImportMode
+	DryRun bool
+}
+
+// ExportArchive is the parsed content of an admin export ZIP.
+type ExportArchive struct {
+	Users          []models.User
+	Projects       []models.Project
+	Tasks          []models.Task
+	ChecklistItems []models.ChecklistItem
+}
+
+type ImportCounts struct {
+	Created  int `json:"created"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"`
+	Remapped int `json:"remapped"`
+}
+
+// ImportConflict is a row that matched existing data, and what was done
+// about it.
+type ImportConflict struct {
+	Entity string `json:"entity"`
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type ImportReport struct {
+	DryRun         bool             `json:"dryRun"`
+	Mode           ImportMode       `json:"mode"`
+	Users          ImportCounts     `json:"users"`
+	Projects       ImportCounts     `json:"projects"`
+	Tasks          ImportCounts     `json:"tasks"`
+	ChecklistItems ImportCounts     `json:"checklistItems"`
+	Conflicts      []ImportConflict `json:"conflicts"`
+	// Problems block the import; when present nothing was written.
+	Problems []string `json:"problems"`
+}
+
+type ImportStore interface {
+	WithTx(ctx context.Context, fn func(tx repository.ImportTx) error) error
+}
+
+// ImportService loads an admin export back into the database, turning the
+// export into a backup and restore path.
+type ImportService struct {
+	store ImportStore
+	cache *cache.Loader
+}
+
+func NewImportService(store ImportStore, loader *cache.Loader) *ImportService {
+	return &ImportService{store: store, cache: loader}
+}
+
+// Import applies an archive in one transaction. Users are matched by id and
+// then by email; a user whose email already belongs to another id is mapped
+// onto that user and the archive's references follow. Projects and tasks are
+// matched by id, and subtasks are written after their parents. The report
+// is returned even when the import fails with
+// ErrInvalidImport.
+func (s *ImportService) Import(ctx context.Context, archive *ExportArchive, opts ImportOptions) (_ *ImportReport, err error) {
+	ctx, span := startSpan(ctx, "ImportService.Import", attribute.Bool("import.dry_run", opts.DryRun))
+	defer func() { endSpan(span, err) }()
+
+	if opts.Mode == "" {
+		opts.Mode = ImportSkip
+	}
+	if opts.Mode != ImportSkip && opts.Mode != ImportUpsert {
+		return nil, ErrBadRequest
+	}
+
+	report := &ImportReport{
+		DryRun:    opts.DryRun,
+		Mode:      opts.Mode,
+		Conflicts: []ImportConflict{},
+		Problems:  []string{},
+	}
+
+	err = s.store.WithTx(ctx, func(tx repository.ImportTx) error {
+		run := &importRun{tx: tx, mode: opts.Mode, report: report, users: map[string]string{}}
+		if err := run.apply(ctx, archive); err != nil {
+			return err
+		}
+		if len(report.Problems) > 0 {
+			return ErrInvalidImport
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errDryRun):
+		return report, nil
+	case err != nil:
+		return report, err
+	}
+
+	if s.cache != nil {
+		s.cache.InvalidatePrefix(ctx,
+			repository.CachePrefixUsers,
+			repository.CachePrefixProjects,
+			repository.CachePrefixTasks,
+		)
+	}
+	return report, nil
+}
+
+type importRun struct {
+	tx     repository.ImportTx
+	mode   ImportMode
+	report *ImportReport
+	// users maps archive user ids to the ids they resolved to.
+	users map[string]string
+	// projects holds the archive projects that were written or kept, so their
+	// tasks need no lookup. A rejected project is left out.
+	projects map[string]bool
+	// tasks does the same for archive tasks and their checklist items.
+	tasks map[uuid.UUID]bool
+	// trashed holds the archive tasks skipped because they or their project
+	// are in the trash; their subtasks and checklist items are skipped too.
+	trashed map[uuid.UUID]bool
+	// locked holds the projects whose tree lock the import already took.
+	locked map[uuid.UUID]bool
+}
+
+func (r *importRun) apply(ctx context.Context, archive *ExportArchive) error {
+	for i := range archive.Users {
+		if err := r.importUser(ctx, archive.Users[i]); err != nil {
+			return err
+		}
+	}
+
+	r.projects = make(map[string]bool, len(archive.Projects))
+	for i := range archive.Projects {
+		if err := r.importProject(ctx, archive.Projects[i]); err != nil {
+			return err
+		}
+	}
+
+	r.tasks = make(map[uuid.UUID]bool, len(archive.Tasks))
+	r.trashed = map[uuid.UUID]bool{}
+	r.locked = map[uuid.UUID]bool{}
+	tasks, cyclic := parentsFirst(archive.Tasks)
+	for _, t := range cyclic {
+		r.problem("task %s: parent %s: a task cannot be nested under itself or its subtasks", t.ID, t.ParentID)
+	}
+	for i := range tasks {
+		if err := r.importTask(ctx, tasks[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range archive.ChecklistItems {
+		if err := r.importChecklistItem(ctx, archive.ChecklistItems[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentsFirst orders tasks so that every parent in the archive comes before
+// its subtasks, keeping the archive order otherwise. Tasks on a parent cycle,
+// and their subtasks, are returned apart.
+func parentsFirst(tasks []models.Task) (ordered, cyclic []models.Task) {
+	index := make(map[uuid.UUID]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+
+	const (
+		unseen = iota
+		visiting
+		placed
+		looped
+	)
+	state := make([]int, len(tasks))
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		switch state[i] {
+		case placed:
+			return true
+		case visiting, looped:
+			return false
+		}
+		state[i] = visiting
+		ok := true
+		if p := tasks[i].ParentID; p != nil {
+			if j, in := index[*p]; in {
+				ok = visit(j)
+			}
+		}
+		if ok {
+			state[i] = placed
+			ordered = append(ordered, tasks[i])
+		} else {
+			state[i] = looped
+			cyclic = append(cyclic, tasks[i])
+		}
+		return ok
+	}
+	for i := range tasks {
+		visit(i)
+	}
+	return ordered, cyclic
+}
+
+func (r *importRun) importUser(ctx context.Context, u models.User) error {
+	existing, err := r.tx.UserByID(ctx, u.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	byEmail, err := r.tx.UserByEmail(ctx, u.Email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	if existing == nil {
+		if byEmail != nil {
+			r.users[u.ID] = byEmail.ID
+			r.report.Users.Remapped++
+			r.conflict("user", u.ID, "email "+u.Email+" belongs to user "+byEmail.ID+"; references remapped")
+			return nil
+		}
+		u.PasswordHash = unusablePasswordHash
+		r.users[u.ID] = u.ID
+		r.report.Users.Created++
+		return r.tx.InsertUser(ctx, &u)
+	}
+
+	r.users[u.ID] = u.ID
+	if r.mode == ImportSkip {
+		r.report.Users.Skipped++
+		r.conflict("user", u.ID, "exists; skipped")
+		return nil
+	}
+	if byEmail != nil && byEmail.ID != u.ID {
+		r.problem("user %s: email %s belongs to user %s", u.ID, u.Email, byEmail.ID)
+		return nil
+	}
+	r.report.Users.Updated++
+	r.conflict("user", u.ID, "exists; updated")
+	return r.tx.UpdateUser(ctx, &u)
+}
+
+func (r *importRun) importProject(ctx context.Context, p models.Project) error {
+	owner, err := r.resolveUser(ctx, p.OwnerID)
+	if err != nil {
+		return err
+	}
+	if owner == "" {
+		r.problem("project %s: unknown owner %s", p.ID, p.OwnerID)
+		return nil
+	}
+	p.OwnerID = owner
+
+	state, err := r.tx.ProjectState(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	if state == repository.RowTrashed {
+		r.report.Projects.Skipped++
+		r.conflict("project", p.ID, "in the trash; skipped with its tasks")
+		return nil
+	}
+	r.projects[p.ID] = true
+	if state == repository.RowMissing {
+		r.report.Projects.Created++
+		return r.tx.InsertProject(ctx, &p)
+	}
+	if r.mode == ImportSkip {
+		r.report.Projects.Skipped++
+		r.conflict("project", p.ID, "exists; skipped")
+		return nil
+	}
+	r.report.Projects.Updated++
+	r.conflict("project", p.ID, "exists; updated")
+	return r.tx.UpdateProject(ctx, &p)
+}
+
+func (r *importRun) importTask(ctx context.Context, t models.Task) error {
+	projectID := t.ProjectID.String()
+	if !r.projects[projectID] {
+		state, err := r.tx.ProjectState(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		switch state {
+		case repository.RowMissing:
+			r.problem("task %s: unknown project %s", t.ID, projectID)
+			return nil
+		case repository.RowTrashed:
+			r.trashed[t.ID] = true
+			r.report.Tasks.Skipped++
+			r.conflict("task", t.ID.String(), "project "+projectID+" is in the trash; skipped")
+			return nil
+		}
+	}
+
+	if t.ParentID != nil {
+		trashed := r.trashed[*t.ParentID]
+		if !trashed {
+			state, err := r.tx.TaskState(ctx, *t.ParentID)
+			if err != nil {
+				return err
+			}
+			trashed = state == repository.RowTrashed
+		}
+		if trashed {
+			r.trashed[t.ID] = true
+			r.report.Tasks.Skipped++
+			r.conflict("task", t.ID.String(), "parent task "+t.ParentID.String()+" is in the trash; skipped")
+			return nil
+		}
+	}
+
+	if t.AssigneeID != nil {
+		assignee, err := r.resolveUser(ctx, t.AssigneeID.String())
+		if err != nil {
+			return err
+		}
+		if assignee == "" {
+			r.problem("task %s: unknown assignee %s", t.ID, t.AssigneeID)
+			return nil
+		}
+		id := uuid.MustParse(assignee)
+		t.AssigneeID = &id
+	}
+
+	state, err := r.tx.TaskState(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if state == repository.RowTrashed {
+		r.trashed[t.ID] = true
+		r.report.Tasks.Skipped++
+		r.conflict("task", t.ID.String(), "in the trash; skipped")
+		return nil
+	}
+	if state == repository.RowLive && r.mode == ImportSkip {
+		r.tasks[t.ID] = true
+		r.report.Tasks.Skipped++
+		r.conflict("task", t.ID.String(), "exists; skipped")
+		return nil
+	}
+	if state == repository.RowLive {
+		// moving a task would strand its subtasks under a parent in
+		// another project
+		existing, err := r.tx.GetByID(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+		if existing.ProjectID != t.ProjectID {
+			r.problem("task %s: belongs to project %s, not %s; tasks cannot move between projects", t.ID, existing.ProjectID, projectID)
+			return nil
+		}
+	}
+
+	if ok, err := r.parentValid(ctx, &t); !ok {
+		return err
+	}
+	r.tasks[t.ID] = true
+	if state == repository.RowMissing {
+		r.report.Tasks.Created++
+		return r.tx.InsertTask(ctx, &t)
+	}
+	r.report.Tasks.Updated++
+	r.conflict("task", t.ID.String(), "exists; updated")
+	return r.tx.UpdateTask(ctx, &t)
+}
+
+// parentValid checks a subtask's parent by the rules of a move, reporting a
+// problem when it breaks them. Parents from the archive are written by now.
+func (r *importRun) parentValid(ctx context.Context, t *models.Task) (bool, error) {
+	if t.ParentID == nil {
+		return true, nil
+	}
+	if !r.locked[t.ProjectID] {
+		if err := r.tx.LockTree(ctx, t.ProjectID); err != nil {
+			return false, err
+		}
+		r.locked[t.ProjectID] = true
+	}
+
+	err := validateParent(ctx, r.tx, t, *t.ParentID)
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		for _, f := range invalid.Fields {
+			r.problem("task %s: parent %s: %s", t.ID, t.ParentID, f.Message)
+		}
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *importRun) importChecklistItem(ctx context.Context, c models.ChecklistItem) error {
+	taskID := c.TaskID.String()
+	trashed := r.trashed[c.TaskID]
+	if !trashed && !r.tasks[c.TaskID] {
+		state, err := r.tx.TaskState(ctx, c.TaskID)
+		if err != nil {
+			return err
+		}
+		if state == repository.RowMissing {
+			r.problem("checklist item %s: unknown task %s", c.ID, taskID)
+			return nil
+		}
+		trashed = state == repository.RowTrashed
+	}
+	if trashed {
+		r.report.ChecklistItems.Skipped++
+		r.conflict("checklist_item", c.ID.String(), "task "+taskID+" is in the trash; skipped")
+		return nil
+	}
+
+	state, err := r.tx.ChecklistItemState(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if state == repository.RowMissing {
+		r.report.ChecklistItems.Created++
+		return r.tx.InsertChecklistItem(ctx, &c)
+	}
+	if r.mode == ImportSkip {
+		r.report.ChecklistItems.Skipped++
+		r.conflict("checklist_item", c.ID.String(), "exists; skipped")
+		return nil
+	}
+	r.report.ChecklistItems.Updated++
+	r.conflict("checklist_item", c.ID.String(), "exists; updated")
+	return r.tx.UpdateChecklistItem(ctx, &c)
+}
+
+// resolveUser maps an archive user id to a database user id, or "" when the
+// user is neither in the archive nor in the database.
+func (r *importRun) resolveUser(ctx context.Context, id string) (string, error) {
+	if mapped, ok := r.users[id]; ok {
+		return mapped, nil
+	}
+	u, err := r.tx.UserByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
+
+func (r *importRun) conflict(entity, id, reason string) {
+	r.report.Conflicts = append(r.report.Conflicts, ImportConflict{Entity: entity, ID: id, Reason: reason})
+}
+
+func (r *importRun) problem(format string, args ...any) {
+	r.report.Problems = append(r.report.Problems, fmt.Sprintf(format, args...))
+}
+
+// ParseExportArchive reads and validates the CSV files of an admin export.
+// Every malformed row is reported, not just the first.
+func ParseExportArchive(zr *zip.Reader) (*ExportArchive, error) {
+	p := &archiveParser{files: map[string]*zip.File{}}
+	for _, f := range zr.File {
+		p.files[f.Name] = f
+	}
+
+	archive := &ExportArchive{}
+	p.each("users.csv", []string{"id", "email", "role", "created_at"}, func(row csvRow) {
+		u := models.User{
+			ID:        row.uuid("id").String(),
+			Email:     normalizeEmail(row.get("email")),
+			Role:      row.get("role"),
+			Kind:      row.get("kind"),
+			CreatedAt: row.time("created_at"),
+		}
+		if u.Kind == "" {
+			u.Kind = models.UserKindHuman
+		}
+		if u.Email == "" {
+			row.fail("email is empty")
+		}
+		if u.Role != "user" && u.Role != "admin" {
+			row.fail("invalid role %q", u.Role)
+		}
+		if u.Kind != models.UserKindHuman && u.Kind != models.UserKindService {
+			row.fail("invalid kind %q", u.Kind)
+		}
+		archive.Users = append(archive.Users, u)
+	})
+	p.each("projects.csv", []string{"id", "name", "owner_id", "created_at"}, func(row csvRow) {
+		project := models.Project{
+			ID:        row.uuid("id").String(),
+			Name:      row.get("name"),
+			OwnerID:   row.uuid("owner_id").String(),
+			CreatedAt: row.time("created_at"),
+		}
+		// archived_at is optional; empty means the project is not archived
+		if row.get("archived_at") != "" {
+			archived := row.time("archived_at")
+			project.ArchivedAt = &archived
+		}
+		archive.Projects = append(archive.Projects, project)
+	})
+	p.each("tasks.csv", []string{"id", "project_id", "title", "description", "status", "assignee_id", "created_at", "updated_at"}, func(row csvRow) {
+		t := models.Task{
+			ID:          row.uuid("id"),
+			ProjectID:   row.uuid("project_id"),
+			Title:       row.get("title"),
+			Description: row.get("description"),
+			Status:      row.get("status"),
+			CreatedAt:   row.time("created_at"),
+			UpdatedAt:   row.time("updated_at"),
+		}
+		if row.get("assignee_id") != "" {
+			id := row.uuid("assignee_id")
+			t.AssigneeID = &id
+		}
+		// parent_id is optional like due_date
+		if row.get("parent_id") != "" {
+			id := row.uuid("parent_id")
+			t.ParentID = &id
+		}
+		// due_date is optional so archives from before it existed still load
+		if v := row.get("due_date"); v != "" {
+			due, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				row.fail("invalid due_date %q", v)
+			}
+			t.DueDate = &due
+		}
+		if !isValidStatus(t.Status) {
+			row.fail("invalid status %q", t.Status)
+		}
+		archive.Tasks = append(archive.Tasks, t)
+	})
+
+	// checklist_items.csv is optional; archives from before checklists have none
+	if p.has("checklist_items.csv") {
+		p.each("checklist_items.csv", []string{"id", "task_id", "title", "done", "position", "created_at", "updated_at"}, func(row csvRow) {
+			c := models.ChecklistItem{
+				ID:        row.uuid("id"),
+				TaskID:    row.uuid("task_id"),
+				Title:     row.get("title"),
+				CreatedAt: row.time("created_at"),
+				UpdatedAt: row.time("updated_at"),
+			}
+			done, err := strconv.ParseBool(row.get("done"))
+			if err != nil {
+				row.fail("invalid done %q", row.get("done"))
+			}
+			c.Done = done
+			position, err := strconv.Atoi(row.get("position"))
+			if err != nil {
+				row.fail("invalid position %q", row.get("position"))
+			}
+			c.Position = position
+			if c.Title == "" {
+				row.fail("title is empty")
+			}
+			archive.ChecklistItems = append(archive.ChecklistItems, c)
+		})
+	}
+
+	p.unique("users.csv", len(archive.Users), func(i int) string { return archive.Users[i].ID })
+	p.unique("users.csv", len(archive.Users), func(i int) string { return archive.Users[i].Email })
+	p.unique("projects.csv", len(archive.Projects), func(i int) string { return archive.Projects[i].ID })
+	p.unique("tasks.csv", len(archive.Tasks), func(i int) string { return archive.Tasks[i].ID.String() })
+	p.unique("checklist_items.csv", len(archive.ChecklistItems), func(i int) string { return archive.ChecklistItems[i].ID.String() })
+
+	if len(p.problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImport, strings.Join(p.problems, "; "))
+	}
+	return archive, nil
+}
+
+type archiveParser struct {
+	files    map[string]*zip.File
+	problems []string
+}
+
+func (p *archiveParser) fail(format string, args ...any) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+func (p *archiveParser) has(name string) bool {
+	_, ok := p.files[name]
+	return ok
+}
+
+// each calls fn for every data row of name after checking that the header
+// carries the required columns. Extra columns are ignored.
+func (p *archiveParser) each(name string, required []string, fn func(row csvRow)) {
+	f, ok := p.files[name]
+	if !ok {
+		p.fail("%s is missing", name)
+		return
+	}
+	rc, err := f.Open()
+	if err != nil {
+		p.fail("%s: %v", name, err)
+		return
+	}
+	defer rc.Close()
+
+	r := csv.NewReader(rc)
+	header, err := r.Read()
+	if err != nil {
+		p.fail("%s: cannot read header: %v", name, err)
+		return
+	}
+	columns := make(map[string]int, len(header))
+	for i, col := range header {
+		columns[strings.TrimSpace(col)] = i
+	}
+	missing := false
+	for _, col := range required {
+		if _, ok := columns[col]; !ok {
+			p.fail("%s: missing column %s", name, col)
+			missing = true
+		}
+	}
+	if missing {
+		return
+	}
+
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			p.fail("%s: %v", name, err)
+			return
+		}
+		fn(csvRow{parser: p, file: name, line: line, columns: columns, record: record})
+	}
+}
+
+func (p *archiveParser) unique(name string, n int, key func(i int) string) {
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		k := key(i)
+		if seen[k] {
+			p.fail("%s: duplicate %s", name, k)
+		}
+		seen[k] = true
+	}
+}
+
+type csvRow struct {
+	parser  *archiveParser
+	file    string
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+func (r csvRow) fail(format string, args ...any) {
+	r.parser.fail("%s line %d: %s", r.file, r.line, fmt.Sprintf(format, args...))
+}
+
+func (r csvRow) get(col string) string {
+	i, ok := r.columns[col]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return r.record[i]
+}
+
+func (r csvRow) uuid(col string) uuid.UUID {
+	id, err := uuid.Parse(r.get(col))
+	if err != nil {
+		r.fail("invalid %s %q", col, r.get(col))
+	}
+	return id
+}
+
+func (r csvRow) time(col string) time.Time {
+	t, err := time.Parse(time.RFC3339, r.get(col))
+	if err != nil {
+		r.fail("invalid %s %q", col, r.get(col))
+	}
+	return t
+}
@@ -0,0 +1,546 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// fakeImportStore keeps committed state and hands each transaction a copy,
+// so a failed or dry-run import leaves it untouched.
+type fakeImportStore struct {
+	users    map[string]models.User
+	projects map[string]models.Project
+	tasks    map[uuid.UUID]models.Task
+	items    map[uuid.UUID]models.ChecklistItem
+}
+
+func newFakeImportStore() *fakeImportStore {
+	return &fakeImportStore{
+		users:    map[string]models.User{},
+		projects: map[string]models.Project{},
+		tasks:    map[uuid.UUID]models.Task{},
+		items:    map[uuid.UUID]models.ChecklistItem{},
+	}
+}
+
+func (s *fakeImportStore) WithTx(ctx context.Context, fn func(tx repository.ImportTx) error) error {
+	tx := &fakeImportStore{
+		users:    maps.Clone(s.users),
+		projects: maps.Clone(s.projects),
+		tasks:    maps.Clone(s.tasks),
+		items:    maps.Clone(s.items),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	*s = *tx
+	return nil
+}
+
+func (s *fakeImportStore) UserByID(ctx context.Context, id string) (*models.User, error) {
+	u, ok := s.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *fakeImportStore) UserByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, u := range s.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (s *fakeImportStore) InsertUser(ctx context.Context, user *models.User) error {
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s *fakeImportStore) UpdateUser(ctx context.Context, user *models.User) error {
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s *fakeImportStore) ProjectState(ctx context.Context, id string) (repository.RowState, error) {
+	p, ok := s.projects[id]
+	return rowState(ok, p.DeletedAt), nil
+}
+
+func rowState(found bool, deletedAt *time.Time) repository.RowState {
+	switch {
+	case !found:
+		return repository.RowMissing
+	case deletedAt != nil:
+		return repository.RowTrashed
+	}
+	return repository.RowLive
+}
+
+func (s *fakeImportStore) InsertProject(ctx context.Context, project *models.Project) error {
+	s.projects[project.ID] = *project
+	return nil
+}
+
+func (s *fakeImportStore) UpdateProject(ctx context.Context, project *models.Project) error {
+	if p, ok := s.projects[project.ID]; ok && p.DeletedAt == nil {
+		s.projects[project.ID] = *project
+	}
+	return nil
+}
+
+func (s *fakeImportStore) TaskState(ctx context.Context, id uuid.UUID) (repository.RowState, error) {
+	t, ok := s.tasks[id]
+	return rowState(ok, t.DeletedAt), nil
+}
+
+// InsertTask enforces the project and parent foreign keys like the database
+// does.
+func (s *fakeImportStore) InsertTask(ctx context.Context, task *models.Task) error {
+	if _, ok := s.projects[task.ProjectID.String()]; !ok {
+		return errors.New("insert task: violates foreign key constraint tasks_project_id_fkey")
+	}
+	if task.ParentID != nil {
+		if _, ok := s.tasks[*task.ParentID]; !ok {
+			return errors.New("insert task: violates foreign key constraint tasks_parent_id_fkey")
+		}
+	}
+	s.tasks[task.ID] = *task
+	return nil
+}
+
+func (s *fakeImportStore) UpdateTask(ctx context.Context, task *models.Task) error {
+	if t, ok := s.tasks[task.ID]; ok && t.DeletedAt == nil {
+		s.tasks[task.ID] = *task
+	}
+	return nil
+}
+
+func (s *fakeImportStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	t, ok := s.tasks[id]
+	if !ok || t.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (s *fakeImportStore) Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for p := s.tasks[id].ParentID; p != nil && len(ids) < 100; p = s.tasks[*p].ParentID {
+		ids = append(ids, *p)
+	}
+	return ids, nil
+}
+
+func (s *fakeImportStore) SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error) {
+	height := 0
+	for _, t := range s.tasks {
+		if t.ParentID != nil && *t.ParentID == id && t.DeletedAt == nil {
+			h, _ := s.SubtreeHeight(ctx, t.ID)
+			height = max(height, h+1)
+		}
+	}
+	return height, nil
+}
+
+func (s *fakeImportStore) Create(ctx context.Context, task *models.Task) error {
+	return s.InsertTask(ctx, task)
+}
+
+func (s *fakeImportStore) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	t := s.tasks[id]
+	t.ParentID = parentID
+	s.tasks[id] = t
+	return nil
+}
+
+func (s *fakeImportStore) LockTree(ctx context.Context, projectID uuid.UUID) error {
+	return nil
+}
+
+func (s *fakeImportStore) ChecklistItemState(ctx context.Context, id uuid.UUID) (repository.RowState, error) {
+	_, ok := s.items[id]
+	return rowState(ok, nil), nil
+}
+
+func (s *fakeImportStore) InsertChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	if _, ok := s.tasks[item.TaskID]; !ok {
+		return errors.New("insert checklist item: violates foreign key constraint task_checklist_items_task_id_fkey")
+	}
+	s.items[item.ID] = *item
+	return nil
+}
+
+func (s *fakeImportStore) UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	s.items[item.ID] = *item
+	return nil
+}
+
+const (
+	importUserID    = "11111111-1111-1111-1111-111111111111"
+	importProjectID = "22222222-2222-2222-2222-222222222222"
+	importTaskID    = "33333333-3333-3333-3333-333333333333"
+	importSubtaskID = "44444444-4444-4444-4444-444444444444"
+	importItemID    = "55555555-5555-5555-5555-555555555555"
+)
+
+func buildArchive(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create error = %v", err)
+		}
+		_, _ = f.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip NewReader error = %v", err)
+	}
+	return zr
+}
+
+func validArchiveFiles() map[string]string {
+	return map[string]string{
+		"users.csv": "id,email,role,kind,created_at\n" +
+			importUserID + ",alice@test.com,user,human,2024-01-01T00:00:00Z\n",
+		"projects.csv": "id,name,owner_id,created_at\n" +
+			importProjectID + ",Alpha," + importUserID + ",2024-01-02T00:00:00Z\n",
+		"tasks.csv": "id,project_id,title,description,status,assignee_id,created_at,updated_at\n" +
+			importTaskID + "," + importProjectID + ",Write docs,,todo," + importUserID + ",2024-01-03T00:00:00Z,2024-01-03T00:00:00Z\n",
+	}
+}
+
+func parseArchive(t *testing.T, files map[string]string) *ExportArchive {
+	t.Helper()
+
+	archive, err := ParseExportArchive(buildArchive(t, files))
+	if err != nil {
+		t.Fatalf("ParseExportArchive() error = %v", err)
+	}
+	return archive
+}
+
+func TestParseExportArchive_ReportsProblems(t *testing.T) {
+	files := validArchiveFiles()
+	delete(files, "projects.csv")
+	files["tasks.csv"] = "id,project_id,title,description,status,assignee_id,created_at,updated_at\n" +
+		"not-a-uuid," + importProjectID + ",x,,blocked,,2024-01-03T00:00:00Z,2024-01-03T00:00:00Z\n"
+
+	_, err := ParseExportArchive(buildArchive(t, files))
+	if !errors.Is(err, ErrInvalidImport) {
+		t.Fatalf("ParseExportArchive() error = %v, want ErrInvalidImport", err)
+	}
+	for _, want := range []string{"projects.csv is missing", "invalid id", "invalid status"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestImportService_CreatesAndDryRunWritesNothing(t *testing.T) {
+	store := newFakeImportStore()
+	svc := NewImportService(store, nil)
+	archive := parseArchive(t, validArchiveFiles())
+
+	report, err := svc.Import(context.Background(), archive, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Import(dry run) error = %v", err)
+	}
+	if report.Tasks.Created != 1 || len(store.tasks) != 0 {
+		t.Fatalf("dry run: report created %d tasks, store has %d", report.Tasks.Created, len(store.tasks))
+	}
+
+	if _, err := svc.Import(context.Background(), archive, ImportOptions{}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(store.users) != 1 || len(store.projects) != 1 || len(store.tasks) != 1 {
+		t.Fatalf("store has %d users, %d projects, %d tasks; want 1 each",
+			len(store.users), len(store.projects), len(store.tasks))
+	}
+	if store.users[importUserID].PasswordHash != unusablePasswordHash {
+		t.Fatalf("imported user got a usable password hash")
+	}
+}
+
+func TestImportService_RemapsUserByEmail(t *testing.T) {
+	store := newFakeImportStore()
+	existingID := uuid.NewString()
+	store.users[existingID] = models.User{ID: existingID, Email: "alice@test.com", Role: "user"}
+
+	svc := NewImportService(store, nil)
+	report, err := svc.Import(context.Background(), parseArchive(t, validArchiveFiles()), ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if report.Users.Remapped != 1 || len(store.users) != 1 {
+		t.Fatalf("remapped %d users, store has %d; want 1, 1", report.Users.Remapped, len(store.users))
+	}
+	if owner := store.projects[importProjectID].OwnerID; owner != existingID {
+		t.Fatalf("project owner = %s, want %s", owner, existingID)
+	}
+	if assignee := store.tasks[uuid.MustParse(importTaskID)].AssigneeID; assignee == nil || assignee.String() != existingID {
+		t.Fatalf("task assignee = %v, want %s", assignee, existingID)
+	}
+}
+
+func TestImportService_SkipOrUpsertExistingRows(t *testing.T) {
+	store := newFakeImportStore()
+	svc := NewImportService(store, nil)
+	if _, err := svc.Import(context.Background(), parseArchive(t, validArchiveFiles()), ImportOptions{}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	files := validArchiveFiles()
+	files["projects.csv"] = "id,name,owner_id,created_at,archived_at\n" +
+		importProjectID + ",Renamed," + importUserID + ",2024-01-02T00:00:00Z,2024-01-05T00:00:00Z\n"
+	archive := parseArchive(t, files)
+
+	report, err := svc.Import(context.Background(), archive, ImportOptions{Mode: ImportSkip})
+	if err != nil {
+		t.Fatalf("Import(skip) error = %v", err)
+	}
+	if report.Projects.Skipped != 1 || store.projects[importProjectID].Name != "Alpha" {
+		t.Fatalf("skip mode changed the project: %+v", store.projects[importProjectID])
+	}
+
+	report, err = svc.Import(context.Background(), archive, ImportOptions{Mode: ImportUpsert})
+	if err != nil {
+		t.Fatalf("Import(upsert) error = %v", err)
+	}
+	if p := store.projects[importProjectID]; report.Projects.Updated != 1 || p.Name != "Renamed" || p.ArchivedAt == nil {
+		t.Fatalf("upsert mode did not update and archive the project: %+v", p)
+	}
+}
+
+func TestImportService_UnknownReferenceRollsBack(t *testing.T) {
+	files := validArchiveFiles()
+	files["users.csv"] = "id,email,role,created_at\n"
+
+	store := newFakeImportStore()
+	svc := NewImportService(store, nil)
+
+	// the project's owner is unknown, so its task must be reported rather
+	// than inserted against a project that was never written
+	for _, dryRun := range []bool{true, false} {
+		report, err := svc.Import(context.Background(), parseArchive(t, files), ImportOptions{DryRun: dryRun})
+		if !errors.Is(err, ErrInvalidImport) {
+			t.Fatalf("Import(dry run %v) error = %v, want ErrInvalidImport", dryRun, err)
+		}
+		problems := strings.Join(report.Problems, "\n")
+		for _, want := range []string{"unknown owner " + importUserID, "task " + importTaskID + ": unknown project"} {
+			if !strings.Contains(problems, want) {
+				t.Fatalf("problems %q do not mention %q", problems, want)
+			}
+		}
+	}
+	if len(store.projects) != 0 || len(store.tasks) != 0 {
+		t.Fatalf("failed import wrote %d projects, %d tasks", len(store.projects), len(store.tasks))
+	}
+}
+
+func TestImportService_SkipsTrashedRows(t *testing.T) {
+	deleted := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	taskID := uuid.MustParse(importTaskID)
+
+	cases := []struct {
+		name  string
+		trash func(s *fakeImportStore)
+		want  []ImportConflict
+	}{
+		{
+			name: "project",
+			trash: func(s *fakeImportStore) {
+				s.projects[importProjectID] = models.Project{ID: importProjectID, Name: "Old", OwnerID: importUserID, DeletedAt: &deleted}
+			},
+			want: []ImportConflict{
+				{Entity: "project", ID: importProjectID, Reason: "in the trash; skipped with its tasks"},
+				{Entity: "task", ID: importTaskID, Reason: "project " + importProjectID + " is in the trash; skipped"},
+			},
+		},
+		{
+			name: "task",
+			trash: func(s *fakeImportStore) {
+				s.tasks[taskID] = models.Task{ID: taskID, ProjectID: uuid.MustParse(importProjectID), Title: "Old", Status: "todo", DeletedAt: &deleted}
+			},
+			want: []ImportConflict{
+				{Entity: "task", ID: importTaskID, Reason: "in the trash; skipped"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		for _, mode := range []ImportMode{ImportSkip, ImportUpsert} {
+			store := newFakeImportStore()
+			tc.trash(store)
+			svc := NewImportService(store, nil)
+
+			report, err := svc.Import(context.Background(), parseArchive(t, validArchiveFiles()), ImportOptions{Mode: mode})
+			if err != nil {
+				t.Fatalf("%s/%s: Import() error = %v", tc.name, mode, err)
+			}
+			if !reflect.DeepEqual(report.Conflicts, tc.want) {
+				t.Fatalf("%s/%s: conflicts = %+v, want %+v", tc.name, mode, report.Conflicts, tc.want)
+			}
+			if report.Tasks.Created != 0 || report.Tasks.Updated != 0 || report.Tasks.Skipped != 1 {
+				t.Fatalf("%s/%s: task counts = %+v, want one skipped", tc.name, mode, report.Tasks)
+			}
+			if p := store.projects[importProjectID]; tc.name == "project" && (p.Name != "Old" || p.DeletedAt == nil) {
+				t.Fatalf("%s/%s: trashed project changed: %+v", tc.name, mode, p)
+			}
+			if tk := store.tasks[taskID]; tc.name == "task" && (tk.Title != "Old" || tk.DeletedAt == nil) {
+				t.Fatalf("%s/%s: trashed task changed: %+v", tc.name, mode, tk)
+			}
+		}
+	}
+}
+
+// subtaskArchiveFiles lists the subtask before its parent and gives it a
+// checklist item.
+func subtaskArchiveFiles() map[string]string {
+	files := validArchiveFiles()
+	files["tasks.csv"] = "id,project_id,parent_id,title,description,status,assignee_id,created_at,updated_at\n" +
+		importSubtaskID + "," + importProjectID + "," + importTaskID + ",Proofread,,todo,,2024-01-03T00:00:00Z,2024-01-03T00:00:00Z\n" +
+		importTaskID + "," + importProjectID + ",,Write docs,,todo,,2024-01-03T00:00:00Z,2024-01-03T00:00:00Z\n"
+	files["checklist_items.csv"] = "id,task_id,title,done,position,created_at,updated_at\n" +
+		importItemID + "," + importSubtaskID + ",Spelling,true,1,2024-01-04T00:00:00Z,2024-01-04T00:00:00Z\n"
+	return files
+}
+
+func TestImportService_SubtasksAndChecklistItems(t *testing.T) {
+	store := newFakeImportStore()
+	svc := NewImportService(store, nil)
+
+	report, err := svc.Import(context.Background(), parseArchive(t, subtaskArchiveFiles()), ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import() error = %v, problems %v", err, report.Problems)
+	}
+	if report.Tasks.Created != 2 || report.ChecklistItems.Created != 1 {
+		t.Fatalf("created %d tasks, %d checklist items; want 2, 1", report.Tasks.Created, report.ChecklistItems.Created)
+	}
+	subtask := store.tasks[uuid.MustParse(importSubtaskID)]
+	if subtask.ParentID == nil || subtask.ParentID.String() != importTaskID {
+		t.Fatalf("subtask parent = %v, want %s", subtask.ParentID, importTaskID)
+	}
+	if item := store.items[uuid.MustParse(importItemID)]; item.Title != "Spelling" || !item.Done {
+		t.Fatalf("checklist item = %+v", item)
+	}
+
+	files := subtaskArchiveFiles()
+	files["checklist_items.csv"] = "id,task_id,title,done,position,created_at,updated_at\n" +
+		importItemID + "," + importSubtaskID + ",Grammar,false,2,2024-01-04T00:00:00Z,2024-01-05T00:00:00Z\n"
+	report, err = svc.Import(context.Background(), parseArchive(t, files), ImportOptions{Mode: ImportUpsert})
+	if err != nil {
+		t.Fatalf("Import(upsert) error = %v", err)
+	}
+	if item := store.items[uuid.MustParse(importItemID)]; report.ChecklistItems.Updated != 1 || item.Title != "Grammar" || item.Done {
+		t.Fatalf("upsert: %+v, item %+v", report.ChecklistItems, item)
+	}
+}
+
+func TestImportService_RejectsBadParents(t *testing.T) {
+	const header = "id,project_id,parent_id,title,description,status,assignee_id,created_at,updated_at\n"
+	row := func(id, parent string) string {
+		return id + "," + importProjectID + "," + parent + ",T,,todo,,2024-01-03T00:00:00Z,2024-01-03T00:00:00Z\n"
+	}
+	ids := make([]string, 4)
+	for i := range ids {
+		ids[i] = uuid.NewString()
+	}
+
+	cases := []struct {
+		name  string
+		tasks string
+		want  string
+	}{
+		{"cycle", row(ids[0], ids[1]) + row(ids[1], ids[0]), "cannot be nested under itself"},
+		{"unknown parent", row(ids[0], uuid.NewString()), "parent task not found"},
+		{"too deep", row(ids[0], "") + row(ids[1], ids[0]) + row(ids[2], ids[1]) + row(ids[3], ids[2]), "at most 3 levels"},
+	}
+	for _, tc := range cases {
+		files := validArchiveFiles()
+		files["tasks.csv"] = header + tc.tasks
+		store := newFakeImportStore()
+
+		report, err := NewImportService(store, nil).Import(context.Background(), parseArchive(t, files), ImportOptions{})
+		if !errors.Is(err, ErrInvalidImport) {
+			t.Fatalf("%s: Import() error = %v, want ErrInvalidImport", tc.name, err)
+		}
+		if problems := strings.Join(report.Problems, "\n"); !strings.Contains(problems, tc.want) {
+			t.Fatalf("%s: problems %q do not mention %q", tc.name, problems, tc.want)
+		}
+		if len(store.tasks) != 0 {
+			t.Fatalf("%s: failed import wrote %d tasks", tc.name, len(store.tasks))
+		}
+	}
+}
+
+func TestImportService_SkipsSubtasksOfTrashedTasks(t *testing.T) {
+	deleted := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	taskID := uuid.MustParse(importTaskID)
+	store := newFakeImportStore()
+	store.tasks[taskID] = models.Task{ID: taskID, ProjectID: uuid.MustParse(importProjectID), Title: "Old", Status: "todo", DeletedAt: &deleted}
+
+	report, err := NewImportService(store, nil).Import(context.Background(), parseArchive(t, subtaskArchiveFiles()), ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import() error = %v, problems %v", err, report.Problems)
+	}
+	want := []ImportConflict{
+		{Entity: "task", ID: importTaskID, Reason: "in the trash; skipped"},
+		{Entity: "task", ID: importSubtaskID, Reason: "parent task " + importTaskID + " is in the trash; skipped"},
+		{Entity: "checklist_item", ID: importItemID, Reason: "task " + importSubtaskID + " is in the trash; skipped"},
+	}
+	if !reflect.DeepEqual(report.Conflicts, want) {
+		t.Fatalf("conflicts = %+v, want %+v", report.Conflicts, want)
+	}
+	if len(store.items) != 0 || len(store.tasks) != 1 {
+		t.Fatalf("store has %d tasks, %d checklist items; want only the trashed task", len(store.tasks), len(store.items))
+	}
+}
+
+func TestImportService_RejectsMovingTaskToAnotherProject(t *testing.T) {
+	store := newFakeImportStore()
+	svc := NewImportService(store, nil)
+	if _, err := svc.Import(context.Background(), parseArchive(t, validArchiveFiles()), ImportOptions{}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	otherID := uuid.NewString()
+	files := validArchiveFiles()
+	files["projects.csv"] += otherID + ",Beta," + importUserID + ",2024-01-02T00:00:00Z\n"
+	files["tasks.csv"] = "id,project_id,title,description,status,assignee_id,created_at,updated_at\n" +
+		importTaskID + "," + otherID + ",Write docs,,todo,,2024-01-03T00:00:00Z,2024-01-03T00:00:00Z\n"
+
+	report, err := svc.Import(context.Background(), parseArchive(t, files), ImportOptions{Mode: ImportUpsert})
+	if !errors.Is(err, ErrInvalidImport) {
+		t.Fatalf("Import(upsert) error = %v, want ErrInvalidImport", err)
+	}
+	want := "task " + importTaskID + ": belongs to project " + importProjectID
+	if problems := strings.Join(report.Problems, "\n"); !strings.Contains(problems, want) {
+		t.Fatalf("problems %q do not mention %q", problems, want)
+	}
+	if got := store.tasks[uuid.MustParse(importTaskID)].ProjectID.String(); got != importProjectID {
+		t.Fatalf("task moved to project %s", got)
+	}
+}
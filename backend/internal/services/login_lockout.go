@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginGuard throttles password guessing. AuthService consults it before
+// checking a password and reports the outcome afterwards.
+type LoginGuard interface {
+	// Check returns a *LoginLockedError while email from ip, or ip as a
+	// whole, is blocked.
+	Check(ctx context.Context, email, ip string) error
+	// RecordFailure counts a failed attempt. An empty email counts against
+	// the IP only, for addresses that match no account.
+	RecordFailure(ctx context.Context, email, ip string)
+	RecordSuccess(ctx context.Context, email, ip string)
+	// Unlock clears the failure history of an account from every address.
+	Unlock(ctx context.Context, email string)
+}
+
+// LoginLockedError carries how long the caller has to wait before trying
+// again. It matches ErrLoginLocked with errors.Is.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LockoutPolicy describes how failures escalate for one kind of key.
+type LockoutPolicy struct {
+	// FreeAttempts failures are allowed without any delay.
+	FreeAttempts int
+	// Each further failure blocks for BaseDelay, doubling per failure up to
+	// MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockAfter failures block for LockDuration; zero never locks, so the
+	// delays stay capped at MaxDelay.
+	LockAfter    int
+	LockDuration time.Duration
+	// ResetAfter without a failure forgets the history.
+	ResetAfter time.Duration
+}
+
+// DefaultEmailLockoutPolicy protects a single account from one address.
+// Failures are counted per email and IP together, so guessing from one
+// address cannot lock the owner out everywhere else.
+var DefaultEmailLockoutPolicy = LockoutPolicy{
+	FreeAttempts: 3,
+	BaseDelay:    time.Second,
+	MaxDelay:     time.Minute,
+	LockAfter:    10,
+	LockDuration: 15 * time.Minute,
+	ResetAfter:   time.Hour,
+}
+
+// DefaultAccountLockoutPolicy counts failures for an account from every
+// address, so rotating IPs buys no extra guesses. It only ever delays:
+// anyone can fail logins for any email, and a lock here would let them keep
+// the owner out.
+var DefaultAccountLockoutPolicy = LockoutPolicy{
+	FreeAttempts: 20,
+	BaseDelay:    time.Second,
+	MaxDelay:     30 * time.Second,
+	ResetAfter:   time.Hour,
+}
+
+// DefaultIPLockoutPolicy is looser than the per-account one because many
+// users can share an address behind NAT; it exists to slow credential
+// stuffing across accounts from a single source.
+var DefaultIPLockoutPolicy = LockoutPolicy{
+	FreeAttempts: 10,
+	BaseDelay:    time.Second,
+	MaxDelay:     time.Minute,
+	LockAfter:    50,
+	LockDuration: 15 * time.Minute,
+	ResetAfter:   time.Hour,
+}
+
+// delay is how long the failures-th failure in a row blocks for.
+func (p LockoutPolicy) delay(failures int) time.Duration {
+	switch {
+	case p.LockAfter > 0 && failures >= p.LockAfter:
+		return p.LockDuration
+	case failures > p.FreeAttempts:
+		d := p.BaseDelay << (failures - p.FreeAttempts - 1)
+		if d <= 0 || d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+		return d
+	}
+	return 0
+}
+
+// lockoutStore keeps failure counts and blocks by key; LoginLockout decides
+// what they mean.
+type lockoutStore interface {
+	// fail counts a failure and returns the count so far. A key forgets its
+	// failures after idle without one.
+	fail(ctx context.Context, key string, idle time.Duration) (int, error)
+	block(ctx context.Context, key string, d time.Duration) error
+	blockedFor(ctx context.Context, key string) (time.Duration, error)
+	clear(ctx context.Context, key string) error
+	clearPrefix(ctx context.Context, prefix string) error
+}
+
+// LoginLockout is a LoginGuard tracking failures per email and IP pair, per
+// email across IPs and per IP. Store errors are logged and let the attempt through: a Redis outage
+// must not lock everyone out.
+type LoginLockout struct {
+	store    lockoutStore
+	emailP   LockoutPolicy
+	accountP LockoutPolicy
+	ipP      LockoutPolicy
+	now      func() time.Time
+}
+
+// NewLoginLockout keeps the counters in process, for single-instance setups.
+func NewLoginLockout(emailPolicy, accountPolicy, ipPolicy LockoutPolicy) *LoginLockout {
+	l := &LoginLockout{emailP: emailPolicy, accountP: accountPolicy, ipP: ipPolicy, now: time.Now}
+	l.store = &memoryLockoutStore{entries: make(map[string]*loginAttempts), now: func() time.Time { return l.now() }}
+	return l
+}
+
+// LockoutRedisNamespace is where the API and the admin CLI keep lockout
+// counters, so reset-password clears what the API tracks.
+const LockoutRedisNamespace = "taskmgr:lockout:"
+
+// NewRedisLoginLockout keeps the counters in Redis under namespace, so the
+// limits hold across every API replica and survive restarts.
+func NewRedisLoginLockout(client redis.UniversalClient, namespace string, emailPolicy, accountPolicy, ipPolicy LockoutPolicy) *LoginLockout {
+	return &LoginLockout{
+		store:    &redisLockoutStore{client: client, namespace: namespace},
+		emailP:   emailPolicy,
+		accountP: accountPolicy,
+		ipP:      ipPolicy,
+		now:      time.Now,
+	}
+}
+
+func accountKey(email, ip string) string { return "acct:" + email + "|" + ip }
+func emailKey(email string) string       { return "email:" + email }
+func ipKey(ip string) string             { return "ip:" + ip }
+
+func (l *LoginLockout) Check(ctx context.Context, email, ip string) error {
+	keys := []string{accountKey(email, ip)}
+	if email != "" {
+		keys = append(keys, emailKey(email))
+	}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+
+	var wait time.Duration
+	for _, key := range keys {
+		d, err := l.store.blockedFor(ctx, key)
+		if err != nil {
+			slog.Warn("login lockout unavailable", "error", err)
+			continue
+		}
+		wait = max(wait, d)
+	}
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (l *LoginLockout) RecordFailure(ctx context.Context, email, ip string) {
+	if email != "" {
+		l.recordFailure(ctx, accountKey(email, ip), l.emailP)
+		l.recordFailure(ctx, emailKey(email), l.accountP)
+	}
+	if ip != "" {
+		l.recordFailure(ctx, ipKey(ip), l.ipP)
+	}
+}
+
+func (l *LoginLockout) recordFailure(ctx context.Context, key string, p LockoutPolicy) {
+	n, err := l.store.fail(ctx, key, p.ResetAfter)
+	if err == nil {
+		if d := p.delay(n); d > 0 {
+			err = l.store.block(ctx, key, d)
+		}
+	}
+	if err != nil {
+		slog.Warn("login lockout unavailable", "error", err)
+	}
+}
+
+// RecordSuccess clears the account's history from this address and across
+// addresses, but not the IP's: otherwise an attacker could reset their IP
+// counter by logging into an account they own between guesses.
+func (l *LoginLockout) RecordSuccess(ctx context.Context, email, ip string) {
+	err := l.store.clear(ctx, accountKey(email, ip))
+	if err == nil {
+		err = l.store.clear(ctx, emailKey(email))
+	}
+	if err != nil {
+		slog.Warn("login lockout unavailable", "error", err)
+	}
+}
+
+func (l *LoginLockout) Unlock(ctx context.Context, email string) {
+	err := l.store.clearPrefix(ctx, accountKey(email, ""))
+	if err == nil {
+		err = l.store.clear(ctx, emailKey(email))
+	}
+	if err != nil {
+		slog.Warn("login lockout unavailable", "error", err)
+	}
+}
+
+type loginAttempts struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+	idle         time.Duration
+}
+
+type memoryLockoutStore struct {
+	mu      sync.Mutex
+	entries map[string]*loginAttempts
+	swept   time.Time
+	now     func() time.Time
+}
+
+func (m *memoryLockoutStore) fail(_ context.Context, key string, idle time.Duration) (int, error) {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+	a, ok := m.entries[key]
+	if !ok || now.Sub(a.lastFailure) >= idle {
+		a = &loginAttempts{}
+		m.entries[key] = a
+	}
+	a.failures++
+	a.lastFailure = now
+	a.idle = idle
+	return a.failures, nil
+}
+
+func (m *memoryLockoutStore) block(_ context.Context, key string, d time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.entries[key]; ok {
+		a.blockedUntil = m.now().Add(d)
+	}
+	return nil
+}
+
+func (m *memoryLockoutStore) blockedFor(_ context.Context, key string) (time.Duration, error) {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.entries[key]
+	if !ok || !now.Before(a.blockedUntil) {
+		return 0, nil
+	}
+	return a.blockedUntil.Sub(now), nil
+}
+
+func (m *memoryLockoutStore) clear(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *memoryLockoutStore) clearPrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k := range m.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.entries, k)
+		}
+	}
+	return nil
+}
+
+func (m *memoryLockoutStore) sweep(now time.Time) {
+	if now.Sub(m.swept) < time.Minute {
+		return
+	}
+	m.swept = now
+
+	for k, a := range m.entries {
+		if now.After(a.blockedUntil) && now.Sub(a.lastFailure) >= a.idle {
+			delete(m.entries, k)
+		}
+	}
+}
+
+// redisLockoutStore keeps a counter key that expires after the idle time and
+// a block key that expires when the block ends.
+type redisLockoutStore struct {
+	client    redis.UniversalClient
+	namespace string
+}
+
+func (r *redisLockoutStore) fail(ctx context.Context, key string, idle time.Duration) (int, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, r.namespace+"fail:"+key)
+		pipe.PExpire(ctx, r.namespace+"fail:"+key, idle)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+func (r *redisLockoutStore) block(ctx context.Context, key string, d time.Duration) error {
+	return r.client.Set(ctx, r.namespace+"block:"+key, 1, d).Err()
+}
+
+func (r *redisLockoutStore) blockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, r.namespace+"block:"+key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+func (r *redisLockoutStore) clear(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.namespace+"fail:"+key, r.namespace+"block:"+key).Err()
+}
+
+func (r *redisLockoutStore) clearPrefix(ctx context.Context, prefix string) error {
+	for _, kind := range []string{"fail:", "block:"} {
+		iter := r.client.Scan(ctx, 0, r.namespace+kind+escapeGlob(prefix)+"*", 200).Iterator()
+		for iter.Next(ctx) {
+			if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
+				return err
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeGlob quotes the characters SCAN's MATCH pattern treats specially, so
+// an email is matched literally.
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+
+	"task-management-platform/backend/internal/models"
+)
+
+func newTestLockout() (*LoginLockout, *time.Time) {
+	now := time.Now()
+	l := NewLoginLockout(
+		LockoutPolicy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: 8 * time.Second, LockAfter: 6, LockDuration: time.Hour, ResetAfter: 24 * time.Hour},
+		DefaultAccountLockoutPolicy,
+		LockoutPolicy{FreeAttempts: 100, BaseDelay: time.Second, MaxDelay: time.Second, LockAfter: 1000, LockDuration: time.Hour, ResetAfter: 24 * time.Hour},
+	)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLoginLockout_DelaysGrowExponentially(t *testing.T) {
+	ctx := context.Background()
+	l, now := newTestLockout()
+
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	for i, w := range want {
+		l.RecordFailure(ctx, "a@test.com", "10.0.0.1")
+
+		var got time.Duration
+		var locked *LoginLockedError
+		if err := l.Check(ctx, "a@test.com", "10.0.0.1"); errors.As(err, &locked) {
+			got = locked.RetryAfter
+		}
+		if got != w {
+			t.Fatalf("failure %d: retry after %v, want %v", i+1, got, w)
+		}
+
+		*now = now.Add(got)
+	}
+}
+
+func TestLoginLockout_LocksAfterThresholdAndUnlocks(t *testing.T) {
+	ctx := context.Background()
+	l, now := newTestLockout()
+
+	for i := 0; i < 6; i++ {
+		*now = now.Add(time.Minute)
+		l.RecordFailure(ctx, "a@test.com", "10.0.0.1")
+	}
+
+	err := l.Check(ctx, "a@test.com", "10.0.0.1")
+	if !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Check() err = %v, want ErrLoginLocked", err)
+	}
+
+	// the owner signing in from elsewhere is unaffected
+	if err := l.Check(ctx, "a@test.com", "10.0.0.2"); err != nil {
+		t.Fatalf("Check() from another IP err = %v, want nil", err)
+	}
+
+	// another account from the same IP is unaffected
+	if err := l.Check(ctx, "b@test.com", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() for other account err = %v, want nil", err)
+	}
+
+	l.Unlock(ctx, "a@test.com")
+	if err := l.Check(ctx, "a@test.com", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() after Unlock err = %v, want nil", err)
+	}
+}
+
+func TestLoginLockout_TracksFailuresPerIP(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := NewLoginLockout(
+		DefaultEmailLockoutPolicy,
+		DefaultAccountLockoutPolicy,
+		LockoutPolicy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute, LockAfter: 10, LockDuration: time.Hour, ResetAfter: time.Hour},
+	)
+	l.now = func() time.Time { return now }
+
+	// spraying different accounts from one IP
+	l.RecordFailure(ctx, "a@test.com", "10.0.0.1")
+	l.RecordFailure(ctx, "b@test.com", "10.0.0.1")
+
+	if err := l.Check(ctx, "c@test.com", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Check() err = %v, want ErrLoginLocked", err)
+	}
+
+	// success does not clear the IP history
+	l.RecordSuccess(ctx, "c@test.com", "10.0.0.1")
+	if err := l.Check(ctx, "d@test.com", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Check() after success err = %v, want ErrLoginLocked", err)
+	}
+}
+
+func TestLoginLockout_TracksEmailAcrossIPs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := NewLoginLockout(
+		DefaultEmailLockoutPolicy,
+		LockoutPolicy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Minute, ResetAfter: time.Hour},
+		DefaultIPLockoutPolicy,
+	)
+	l.now = func() time.Time { return now }
+
+	// two guesses from each address stay under the per-pair and per-IP limits
+	for i := 0; i < 6; i++ {
+		for range 2 {
+			l.RecordFailure(ctx, "a@test.com", fmt.Sprintf("10.0.0.%d", i))
+		}
+	}
+
+	var locked *LoginLockedError
+	if err := l.Check(ctx, "a@test.com", "10.0.1.1"); !errors.As(err, &locked) {
+		t.Fatalf("Check() from a fresh IP err = %v, want LoginLockedError", err)
+	}
+	if locked.RetryAfter > time.Minute {
+		t.Fatalf("retry after %v, want a delay of at most a minute, never a lock", locked.RetryAfter)
+	}
+	if err := l.Check(ctx, "b@test.com", "10.0.1.1"); err != nil {
+		t.Fatalf("Check() for another account err = %v, want nil", err)
+	}
+
+	l.Unlock(ctx, "a@test.com")
+	if err := l.Check(ctx, "a@test.com", "10.0.1.1"); err != nil {
+		t.Fatalf("Check() after Unlock err = %v, want nil", err)
+	}
+
+	for i := 0; i < 12; i++ {
+		l.RecordFailure(ctx, "a@test.com", fmt.Sprintf("10.0.2.%d", i))
+	}
+	l.RecordSuccess(ctx, "a@test.com", "10.0.3.1")
+	if err := l.Check(ctx, "a@test.com", "10.0.1.1"); err != nil {
+		t.Fatalf("Check() after success err = %v, want nil", err)
+	}
+}
+
+func TestAuthService_Login_LockedOutRejectsCorrectPassword(t *testing.T) {
+	repo := newFakeUserRepo()
+	lockout, _ := newTestLockout()
+	svc := NewAuthService(repo, nil, lockout, nil)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.MinCost)
+	_ = repo.Create(context.Background(), &models.User{
+		ID:           "u1",
+		Email:        "test@test.com",
+		PasswordHash: string(hash),
+		Role:         "user",
+		CreatedAt:    time.Now().UTC(),
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _ = svc.Login(context.Background(), "test@test.com", "WRONG", "10.0.0.1")
+	}
+
+	_, err := svc.Login(context.Background(), "test@test.com", "1234", "10.0.0.1")
+	if !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Login() err = %v, want ErrLoginLocked", err)
+	}
+
+	if err := svc.UnlockAccount(context.Background(), "u1"); err != nil {
+		t.Fatalf("UnlockAccount() error = %v", err)
+	}
+	if _, err := svc.Login(context.Background(), "test@test.com", "1234", "10.0.0.1"); err != nil {
+		t.Fatalf("Login() after unlock error = %v", err)
+	}
+}
+
+func TestLoginLockout_RedisSharesCountersAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	policy := LockoutPolicy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute, LockAfter: 10, LockDuration: time.Hour, ResetAfter: time.Hour}
+	// two guards against the same Redis behave like two API replicas
+	a := NewRedisLoginLockout(client, "lockout:test:", policy, DefaultAccountLockoutPolicy, DefaultIPLockoutPolicy)
+	b := NewRedisLoginLockout(client, "lockout:test:", policy, DefaultAccountLockoutPolicy, DefaultIPLockoutPolicy)
+	ctx := context.Background()
+
+	a.RecordFailure(ctx, "a*b@test.com", "10.0.0.1")
+	b.RecordFailure(ctx, "a*b@test.com", "10.0.0.1")
+
+	var locked *LoginLockedError
+	if err := a.Check(ctx, "a*b@test.com", "10.0.0.1"); !errors.As(err, &locked) || locked.RetryAfter <= 0 {
+		t.Fatalf("Check() err = %v, want a lock with a retry delay", err)
+	}
+	if err := a.Check(ctx, "a*b@test.com", "10.0.0.2"); err != nil {
+		t.Fatalf("Check() from another IP err = %v, want nil", err)
+	}
+
+	// the glob character in the email must not unlock other accounts
+	a.RecordFailure(ctx, "ab@test.com", "10.0.0.1")
+	a.RecordFailure(ctx, "ab@test.com", "10.0.0.1")
+	b.Unlock(ctx, "a*b@test.com")
+	if err := a.Check(ctx, "a*b@test.com", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() after Unlock err = %v, want nil", err)
+	}
+	if err := a.Check(ctx, "ab@test.com", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Check() for another account err = %v, want ErrLoginLocked", err)
+	}
+
+	mr.FastForward(2 * time.Hour)
+	if err := a.Check(ctx, "ab@test.com", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() after the block expired err = %v, want nil", err)
+	}
+}
+
+func TestAuthService_Login_UnknownEmailDoesNotLockAccounts(t *testing.T) {
+	repo := newFakeUserRepo()
+	lockout, _ := newTestLockout()
+	svc := NewAuthService(repo, nil, lockout, nil)
+
+	for i := 0; i < 5; i++ {
+		_, _ = svc.Login(context.Background(), "ghost@test.com", "WRONG", "10.0.0.1")
+	}
+
+	if err := lockout.Check(context.Background(), "ghost@test.com", "10.0.0.9"); err != nil {
+		t.Fatalf("Check() err = %v, want nil", err)
+	}
+	if n := len(lockout.store.(*memoryLockoutStore).entries); n != 1 {
+		t.Fatalf("tracked %d keys, want only the IP", n)
+	}
+}
@@ -0,0 +1,489 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+
+	"task-management-platform/backend/internal/cache"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// oidcStateTTL bounds how long a user can take at the identity provider.
+const oidcStateTTL = 10 * time.Minute
+
+// oidcLinkTicketTTL bounds the gap between asking to link an account and the
+// browser starting the flow.
+const oidcLinkTicketTTL = 2 * time.Minute
+
+// roleRank orders our roles so the most privileged mapped group wins.
+var roleRank = map[string]int{
+	"user":  1,
+	"admin": 2,
+}
+
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim names the ID-token claim listing the user's groups.
+	GroupsClaim string
+	// RoleMapping maps IdP group names to our roles.
+	RoleMapping map[string]string
+	// StateSecret signs the state cookie that carries the PKCE verifier and
+	// nonce between the login redirect and the callback.
+	StateSecret []byte
+	// HTTPClient makes the calls to the provider; nil uses
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type OIDCUserRepo interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByIdentity(ctx context.Context, issuer, subject string) (*models.User, error)
+	CreateWithIdentity(ctx context.Context, user *models.User, issuer, subject string) error
+	LinkIdentity(ctx context.Context, userID, issuer, subject string) error
+	Update(ctx context.Context, user *models.User) error
+}
+
+// OIDCLogin is a started authorization-code flow. URL is where the browser
+// goes next; Cookie must come back unchanged on the callback.
+type OIDCLogin struct {
+	URL    string
+	Cookie string
+}
+
+// OIDCResult is the outcome of a completed flow. MFA reports whether the
+// identity provider says the user authenticated with more than a password.
+// Linked is set when the flow linked an identity rather than signing in.
+type OIDCResult struct {
+	User   *models.User
+	MFA    bool
+	Linked bool
+}
+
+// OIDCService signs users in through an OpenID Connect provider using the
+// authorization-code flow with PKCE.
+type OIDCService struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	client   *http.Client
+
+	groupsClaim string
+	roles       map[string]string
+	stateSecret []byte
+
+	repo  OIDCUserRepo
+	cache *cache.Loader
+	now   func() time.Time
+}
+
+// NewOIDCService runs discovery against cfg.IssuerURL.
+func NewOIDCService(ctx context.Context, cfg OIDCConfig, repo OIDCUserRepo, loader *cache.Loader) (*OIDCService, error) {
+	if cfg.HTTPClient != nil {
+		// the provider keeps this context's client for fetching signing keys
+		ctx = oidc.ClientContext(ctx, cfg.HTTPClient)
+	}
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := cfg.Scopes
+	if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	return &OIDCService{
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier:    provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		client:      cfg.HTTPClient,
+		groupsClaim: cfg.GroupsClaim,
+		roles:       cfg.RoleMapping,
+		stateSecret: cfg.StateSecret,
+		repo:        repo,
+		cache:       loader,
+		now:         time.Now,
+	}, nil
+}
+
+type oidcState struct {
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	Expires  int64  `json:"e"`
+	// LinkUserID is set when the flow links an identity to this user.
+	LinkUserID string `json:"l,omitempty"`
+}
+
+// oidcLinkTicket authorizes one link flow for a signed-in user.
+type oidcLinkTicket struct {
+	Purpose string `json:"p"`
+	UserID  string `json:"u"`
+	Expires int64  `json:"e"`
+}
+
+const oidcLinkPurpose = "link"
+
+// LinkTicket lets a signed-in user start a flow that links an identity to
+// their account. The browser has to navigate to the provider without the
+// API's bearer token, so the ticket carries the user id in the link URL.
+func (s *OIDCService) LinkTicket(userID string) (string, error) {
+	return s.seal(oidcLinkTicket{
+		Purpose: oidcLinkPurpose,
+		UserID:  userID,
+		Expires: s.now().Add(oidcLinkTicketTTL).Unix(),
+	})
+}
+
+// BeginLink starts a flow for the user named by a LinkTicket.
+func (s *OIDCService) BeginLink(ticket string) (*OIDCLogin, error) {
+	var t oidcLinkTicket
+	if err := s.open(ticket, &t); err != nil || t.Purpose != oidcLinkPurpose || t.UserID == "" || s.now().Unix() > t.Expires {
+		return nil, ErrOIDCState
+	}
+	return s.begin(t.UserID)
+}
+
+// BeginLogin starts a flow with a fresh state, nonce and PKCE verifier.
+func (s *OIDCService) BeginLogin() (*OIDCLogin, error) {
+	return s.begin("")
+}
+
+func (s *OIDCService) begin(linkUserID string) (*OIDCLogin, error) {
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	st := oidcState{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: oauth2.GenerateVerifier(),
+		Expires:  s.now().Add(oidcStateTTL).Unix(),
+
+		LinkUserID: linkUserID,
+	}
+
+	cookie, err := s.seal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	url := s.oauth.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(st.Verifier),
+	)
+
+	return &OIDCLogin{URL: url, Cookie: cookie}, nil
+}
+
+// CompleteLogin checks the callback against the state cookie, redeems the
+// code and validates the ID token, then finds or provisions the user, or
+// links the identity when the flow was started by BeginLink.
+func (s *OIDCService) CompleteLogin(ctx context.Context, cookie, state, code string) (_ *OIDCResult, err error) {
+	ctx, span := startSpan(ctx, "OIDCService.CompleteLogin")
+	defer func() { endSpan(span, err) }()
+
+	st, err := s.openState(cookie)
+	if err != nil || state == "" || !hmac.Equal([]byte(st.State), []byte(state)) {
+		return nil, ErrOIDCState
+	}
+
+	if s.client != nil {
+		ctx = oidc.ClientContext(ctx, s.client)
+	}
+	token, err := s.oauth.Exchange(ctx, code, oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		return nil, errors.Join(ErrOIDCExchange, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrOIDCExchange
+	}
+
+	idToken, err := s.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, errors.Join(ErrOIDCExchange, err)
+	}
+	if idToken.Nonce != st.Nonce {
+		return nil, ErrOIDCExchange
+	}
+
+	var claims struct {
+		Email         string   `json:"email"`
+		EmailVerified bool     `json:"email_verified"`
+		AMR           []string `json:"amr"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, errors.Join(ErrOIDCExchange, err)
+	}
+
+	var raw map[string]any
+	if err := idToken.Claims(&raw); err != nil {
+		return nil, errors.Join(ErrOIDCExchange, err)
+	}
+
+	email := normalizeEmail(claims.Email)
+
+	if st.LinkUserID != "" {
+		user, err := s.link(ctx, st.LinkUserID, idToken.Issuer, idToken.Subject, email, claims.EmailVerified)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCResult{User: user, Linked: true}, nil
+	}
+
+	role := s.mapRole(stringList(raw[s.groupsClaim]))
+
+	user, err := s.resolveUser(ctx, idToken.Issuer, idToken.Subject, email, claims.EmailVerified, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCResult{User: user, MFA: hasMFA(claims.AMR)}, nil
+}
+
+// resolveUser prefers an existing link and otherwise provisions a new
+// account. A local account with the same email is not taken over: whoever
+// controls the IdP identity has not proven they own it, so its owner links it
+// from a signed-in session instead. A mapped role is applied on every login
+// so the IdP stays authoritative; without a mapped group an existing user
+// keeps their role.
+func (s *OIDCService) resolveUser(ctx context.Context, issuer, subject, email string, emailVerified bool, role string) (*models.User, error) {
+	user, err := s.repo.GetByIdentity(ctx, issuer, subject)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if user == nil {
+		if email == "" || !emailVerified {
+			return nil, ErrOIDCEmailNotVerified
+		}
+
+		_, err = s.repo.GetByEmail(ctx, email)
+		switch {
+		case err == nil:
+			return nil, ErrOIDCAccountExists
+		case errors.Is(err, repository.ErrNotFound):
+			return s.provision(ctx, issuer, subject, email, role)
+		default:
+			return nil, err
+		}
+	}
+
+	if user.Kind == models.UserKindService {
+		return nil, ErrForbidden
+	}
+
+	if role != "" && role != user.Role {
+		user.Role = role
+		if err := s.repo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		s.cache.Invalidate(ctx, repository.CacheKeyMinimalUsers)
+	}
+
+	return user, nil
+}
+
+// link attaches the identity to the user who started the flow. The verified
+// email has to match the account's, so a link URL planted on someone else
+// cannot attach their identity to the planter's account.
+func (s *OIDCService) link(ctx context.Context, userID, issuer, subject, email string, emailVerified bool) (*models.User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOIDCState
+		}
+		return nil, err
+	}
+	if user.Kind == models.UserKindService {
+		return nil, ErrForbidden
+	}
+	if email == "" || !emailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	if email != normalizeEmail(user.Email) {
+		return nil, ErrOIDCEmailMismatch
+	}
+
+	linked, err := s.repo.GetByIdentity(ctx, issuer, subject)
+	switch {
+	case err == nil && linked.ID == user.ID:
+		return user, nil
+	case err == nil:
+		return nil, ErrOIDCIdentityLinked
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	if err := s.repo.LinkIdentity(ctx, user.ID, issuer, subject); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provision creates an SSO-only account. Its password is random and never
+// shown, so the account can only sign in through the identity provider.
+func (s *OIDCService) provision(ctx context.Context, issuer, subject, email, role string) (*models.User, error) {
+	password, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		role = "user"
+	}
+
+	user := &models.User{
+		ID:           uuid.NewString(),
+		Email:        email,
+		PasswordHash: string(hash),
+		Role:         role,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	if err := s.repo.CreateWithIdentity(ctx, user, issuer, subject); err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return nil, ErrOIDCAccountExists
+		}
+		return nil, err
+	}
+
+	s.cache.Invalidate(ctx, repository.CacheKeyMinimalUsers)
+
+	return user, nil
+}
+
+// mapRole returns the highest-ranked role any group maps to, or "" when no
+// group is mapped.
+func (s *OIDCService) mapRole(groups []string) string {
+	best := ""
+	for _, g := range groups {
+		role, ok := s.roles[g]
+		if ok && roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+	return best
+}
+
+// seal signs v for a round trip through the browser.
+func (s *OIDCService) seal(v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.sign(body)), nil
+}
+
+// open checks the signature on a sealed value and decodes it into v.
+func (s *OIDCService) open(sealed string, v any) error {
+	body, sig, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return ErrOIDCState
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.sign(body)) {
+		return ErrOIDCState
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return ErrOIDCState
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrOIDCState
+	}
+	return nil
+}
+
+func (s *OIDCService) openState(cookie string) (*oidcState, error) {
+	var st oidcState
+	if err := s.open(cookie, &st); err != nil {
+		return nil, err
+	}
+	// a link ticket has no state and must not pass for a cookie
+	if st.State == "" || s.now().Unix() > st.Expires {
+		return nil, ErrOIDCState
+	}
+	return &st, nil
+}
+
+func (s *OIDCService) sign(body string) []byte {
+	mac := hmac.New(sha256.New, s.stateSecret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// stringList accepts a claim given as an array or as a single string.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// hasMFA reads the RFC 8176 authentication method references.
+func hasMFA(amr []string) bool {
+	for _, m := range amr {
+		switch m {
+		case "mfa", "otp", "hwk":
+			return true
+		}
+	}
+	return false
+}
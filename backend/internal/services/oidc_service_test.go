@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// mockIdP is a minimal OpenID provider: discovery, JWKS and a token endpoint
+// that enforces PKCE. Tests play the browser by calling authorize directly.
+type mockIdP struct {
+	t   *testing.T
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+type mockGrant struct {
+	challenge string
+	claims    jwtlib.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey error = %v", err)
+	}
+
+	idp := &mockIdP{t: t, key: key, codes: map[string]mockGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                idp.srv.URL,
+			"authorization_endpoint":                idp.srv.URL + "/authorize",
+			"token_endpoint":                        idp.srv.URL + "/token",
+			"jwks_uri":                              idp.srv.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+// authorize stands in for the user signing in at the IdP: it reads the
+// authorization request and returns the callback's code.
+func (idp *mockIdP) authorize(authURL string, claims jwtlib.MapClaims) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatalf("parse auth URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		idp.t.Fatalf("code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+
+	full := jwtlib.MapClaims{
+		"iss":   idp.srv.URL,
+		"aud":   q.Get("client_id"),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": q.Get("nonce"),
+	}
+	for k, v := range claims {
+		full[k] = v
+	}
+
+	code := "code-" + q.Get("state")
+	idp.mu.Lock()
+	idp.codes[code] = mockGrant{challenge: q.Get("code_challenge"), claims: full}
+	idp.mu.Unlock()
+	return code
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	grant, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	tok := jwtlib.NewWithClaims(jwtlib.SigningMethodRS256, grant.claims)
+	tok.Header["kid"] = "test"
+	idToken, err := tok.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "opaque",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type fakeOIDCRepo struct {
+	*fakeUserRepo
+	identities map[string]string // issuer|subject -> user id
+}
+
+// GetByEmail reports misses the way UserRepository does.
+func (r *fakeOIDCRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	u, ok := r.byEmail[email]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *fakeOIDCRepo) GetByIdentity(ctx context.Context, issuer, subject string) (*models.User, error) {
+	id, ok := r.identities[issuer+"|"+subject]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeOIDCRepo) CreateWithIdentity(ctx context.Context, user *models.User, issuer, subject string) error {
+	_ = r.Create(ctx, user)
+	return r.LinkIdentity(ctx, user.ID, issuer, subject)
+}
+
+func (r *fakeOIDCRepo) LinkIdentity(ctx context.Context, userID, issuer, subject string) error {
+	r.identities[issuer+"|"+subject] = userID
+	return nil
+}
+
+func (r *fakeOIDCRepo) Update(ctx context.Context, user *models.User) error {
+	r.byID[user.ID] = user
+	r.byEmail[user.Email] = user
+	return nil
+}
+
+func newOIDCFixture(t *testing.T) (*mockIdP, *fakeOIDCRepo, *OIDCService) {
+	t.Helper()
+
+	idp := newMockIdP(t)
+	repo := &fakeOIDCRepo{fakeUserRepo: newFakeUserRepo(), identities: map[string]string{}}
+
+	svc, err := NewOIDCService(context.Background(), OIDCConfig{
+		IssuerURL:   idp.srv.URL,
+		ClientID:    "task-manager",
+		RedirectURL: "http://localhost:8080/auth/oidc/callback",
+		Scopes:      []string{"email"},
+		GroupsClaim: "groups",
+		RoleMapping: map[string]string{"staff": "user", "platform-admins": "admin"},
+		StateSecret: []byte("state-secret"),
+	}, repo, nil)
+	if err != nil {
+		t.Fatalf("NewOIDCService() error = %v", err)
+	}
+	return idp, repo, svc
+}
+
+// signIn runs one full flow against the mock IdP.
+func signIn(t *testing.T, idp *mockIdP, svc *OIDCService, claims jwtlib.MapClaims) (*OIDCResult, error) {
+	t.Helper()
+
+	login, err := svc.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	u, _ := url.Parse(login.URL)
+	code := idp.authorize(login.URL, claims)
+
+	return svc.CompleteLogin(context.Background(), login.Cookie, u.Query().Get("state"), code)
+}
+
+func TestOIDCService_ProvisionsUserWithMappedRole(t *testing.T) {
+	idp, repo, svc := newOIDCFixture(t)
+
+	result, err := signIn(t, idp, svc, jwtlib.MapClaims{
+		"sub":            "idp-1",
+		"email":          "New@Example.com",
+		"email_verified": true,
+		"groups":         []string{"staff", "platform-admins"},
+		"amr":            []string{"pwd", "otp"},
+	})
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+
+	if result.User.Email != "new@example.com" || result.User.Role != "admin" {
+		t.Fatalf("user = %s/%s, want new@example.com/admin", result.User.Email, result.User.Role)
+	}
+	if !result.MFA {
+		t.Fatalf("MFA = false, want true for amr otp")
+	}
+	if _, err := repo.GetByIdentity(context.Background(), idp.srv.URL, "idp-1"); err != nil {
+		t.Fatalf("identity not linked: %v", err)
+	}
+}
+
+func TestOIDCService_RefusesToTakeOverLocalAccount(t *testing.T) {
+	idp, repo, svc := newOIDCFixture(t)
+	_ = repo.Create(context.Background(), &models.User{ID: "u1", Email: "test@test.com", Role: "admin"})
+
+	_, err := signIn(t, idp, svc, jwtlib.MapClaims{"sub": "idp-1", "email": "test@test.com", "email_verified": true})
+	if !errors.Is(err, ErrOIDCAccountExists) {
+		t.Fatalf("CompleteLogin() err = %v, want ErrOIDCAccountExists", err)
+	}
+	if _, err := repo.GetByIdentity(context.Background(), idp.srv.URL, "idp-1"); err == nil {
+		t.Fatalf("identity linked without the account owner asking")
+	}
+}
+
+// linkFlow runs one link flow for userID against the mock IdP.
+func linkFlow(t *testing.T, idp *mockIdP, svc *OIDCService, userID string, claims jwtlib.MapClaims) (*OIDCResult, error) {
+	t.Helper()
+
+	ticket, err := svc.LinkTicket(userID)
+	if err != nil {
+		t.Fatalf("LinkTicket() error = %v", err)
+	}
+	login, err := svc.BeginLink(ticket)
+	if err != nil {
+		t.Fatalf("BeginLink() error = %v", err)
+	}
+	u, _ := url.Parse(login.URL)
+	code := idp.authorize(login.URL, claims)
+
+	return svc.CompleteLogin(context.Background(), login.Cookie, u.Query().Get("state"), code)
+}
+
+func TestOIDCService_LinksFromSignedInSession(t *testing.T) {
+	idp, repo, svc := newOIDCFixture(t)
+	_ = repo.Create(context.Background(), &models.User{ID: "u1", Email: "test@test.com", Role: "admin"})
+
+	claims := jwtlib.MapClaims{"sub": "idp-1", "email": "test@test.com", "email_verified": true}
+
+	result, err := linkFlow(t, idp, svc, "u1", claims)
+	if err != nil {
+		t.Fatalf("link CompleteLogin() error = %v", err)
+	}
+	if !result.Linked || result.User.ID != "u1" {
+		t.Fatalf("result = %+v, want u1 linked", result)
+	}
+
+	// once linked, the subject is enough even if the IdP email changes
+	claims["email"] = "renamed@test.com"
+	result, err = signIn(t, idp, svc, claims)
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if result.Linked || result.User.ID != "u1" || result.User.Role != "admin" {
+		t.Fatalf("user = %s/%s, want u1/admin kept without a mapped group", result.User.ID, result.User.Role)
+	}
+}
+
+func TestOIDCService_LinkChecksEmailAndExistingLinks(t *testing.T) {
+	idp, repo, svc := newOIDCFixture(t)
+	_ = repo.Create(context.Background(), &models.User{ID: "u1", Email: "test@test.com", Role: "user"})
+	_ = repo.Create(context.Background(), &models.User{ID: "u2", Email: "other@test.com", Role: "user"})
+
+	// a ticket planted on someone else cannot link their identity
+	_, err := linkFlow(t, idp, svc, "u1", jwtlib.MapClaims{"sub": "idp-2", "email": "other@test.com", "email_verified": true})
+	if !errors.Is(err, ErrOIDCEmailMismatch) {
+		t.Fatalf("link err = %v, want ErrOIDCEmailMismatch", err)
+	}
+
+	_ = repo.LinkIdentity(context.Background(), "u2", idp.srv.URL, "idp-1")
+	_, err = linkFlow(t, idp, svc, "u1", jwtlib.MapClaims{"sub": "idp-1", "email": "test@test.com", "email_verified": true})
+	if !errors.Is(err, ErrOIDCIdentityLinked) {
+		t.Fatalf("link err = %v, want ErrOIDCIdentityLinked", err)
+	}
+}
+
+func TestOIDCService_LinkTicketIsNotAState(t *testing.T) {
+	_, _, svc := newOIDCFixture(t)
+
+	ticket, _ := svc.LinkTicket("u1")
+	if _, err := svc.CompleteLogin(context.Background(), ticket, "x", "code"); !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("CompleteLogin() with a ticket err = %v, want ErrOIDCState", err)
+	}
+
+	login, _ := svc.BeginLogin()
+	if _, err := svc.BeginLink(login.Cookie); !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("BeginLink() with a state cookie err = %v, want ErrOIDCState", err)
+	}
+}
+
+func TestOIDCService_RejectsUnverifiedEmail(t *testing.T) {
+	idp, repo, svc := newOIDCFixture(t)
+	_ = repo.Create(context.Background(), &models.User{ID: "u1", Email: "test@test.com", Role: "admin"})
+
+	_, err := signIn(t, idp, svc, jwtlib.MapClaims{"sub": "idp-1", "email": "test@test.com", "email_verified": false})
+	if !errors.Is(err, ErrOIDCEmailNotVerified) {
+		t.Fatalf("CompleteLogin() err = %v, want ErrOIDCEmailNotVerified", err)
+	}
+}
+
+func TestOIDCService_RejectsForeignState(t *testing.T) {
+	idp, _, svc := newOIDCFixture(t)
+
+	victim, _ := svc.BeginLogin()
+	attacker, _ := svc.BeginLogin()
+	u, _ := url.Parse(attacker.URL)
+	code := idp.authorize(attacker.URL, jwtlib.MapClaims{"sub": "idp-1", "email": "a@test.com", "email_verified": true})
+
+	_, err := svc.CompleteLogin(context.Background(), victim.Cookie, u.Query().Get("state"), code)
+	if !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("CompleteLogin() err = %v, want ErrOIDCState", err)
+	}
+
+	_, err = svc.CompleteLogin(context.Background(), attacker.Cookie+"x", u.Query().Get("state"), code)
+	if !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("CompleteLogin() tampered cookie err = %v, want ErrOIDCState", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"task-management-platform/backend/internal/models"
 )
@@ -15,7 +16,8 @@ type ProjectRepository interface {
 	ListByOwner(context.Context, string) ([]models.Project, error)
 	UpdateName(context.Context, string, string) error
 	Delete(context.Context, string) error
-	List(context.Context) ([]models.Project, error)
+	List(context.Context, bool) ([]models.Project, error)
+	SetArchived(context.Context, string, bool) error
 }
 
 type ProjectService struct {
@@ -26,7 +28,10 @@ func NewProjectService(repo ProjectRepository) *ProjectService {
 	return &ProjectService{repo: repo}
 }
 
-func (s *ProjectService) Create(ctx context.Context, ownerID string, name string) (*models.Project, error) {
+func (s *ProjectService) Create(ctx context.Context, ownerID string, name string) (_ *models.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectService.Create")
+	defer func() { endSpan(span, err) }()
+
 	p := &models.Project{
 		ID:        uuid.NewString(),
 		Name:      name,
@@ -40,24 +45,40 @@ func (s *ProjectService) Create(ctx context.Context, ownerID string, name string
 	return p, nil
 }
 
-func (s *ProjectService) GetByID(ctx context.Context, requesterID string, requesterRole string, projectID string) (*models.Project, error) {
+func (s *ProjectService) GetByID(ctx context.Context, requesterID string, requesterRole string, projectID string) (_ *models.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectService.GetByID", attribute.String("project.id", projectID))
+	defer func() { endSpan(span, err) }()
+
 	p, err := s.repo.GetByID(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !isAdmin(requesterRole) && p.OwnerID != requesterID {
+	if !canSeeProject(models.User{ID: requesterID, Role: requesterRole}, p) {
 		return nil, ErrForbidden
 	}
 
 	return p, nil
 }
 
-func (s *ProjectService) List(ctx context.Context, requesterID string, requesterRole string) ([]models.Project, error) {
-	return s.repo.List(ctx)
+// canSeeProject is the project visibility rule: admins see every project,
+// other users the ones they own.
+func canSeeProject(actor models.User, project *models.Project) bool {
+	return isAdmin(actor.Role) || project.OwnerID == actor.ID
+}
+
+// List returns the live projects, or with archived set the archived ones.
+func (s *ProjectService) List(ctx context.Context, requesterID string, requesterRole string, archived bool) (_ []models.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectService.List", attribute.Bool("project.archived", archived))
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.List(ctx, archived)
 }
 
-func (s *ProjectService) UpdateName(ctx context.Context, requesterID string, requesterRole string, projectID string, name string) error {
+func (s *ProjectService) UpdateName(ctx context.Context, requesterID string, requesterRole string, projectID string, name string) (err error) {
+	ctx, span := startSpan(ctx, "ProjectService.UpdateName", attribute.String("project.id", projectID))
+	defer func() { endSpan(span, err) }()
+
 	p, err := s.repo.GetByID(ctx, projectID)
 	if err != nil {
 		return err
@@ -66,11 +87,17 @@ func (s *ProjectService) UpdateName(ctx context.Context, requesterID string, req
 	if !isAdmin(requesterRole) && p.OwnerID != requesterID {
 		return ErrForbidden
 	}
+	if p.ArchivedAt != nil {
+		return ErrProjectArchived
+	}
 
 	return s.repo.UpdateName(ctx, projectID, name)
 }
 
-func (s *ProjectService) Delete(ctx context.Context, requesterID string, requesterRole string, projectID string) error {
+func (s *ProjectService) Delete(ctx context.Context, requesterID string, requesterRole string, projectID string) (err error) {
+	ctx, span := startSpan(ctx, "ProjectService.Delete", attribute.String("project.id", projectID))
+	defer func() { endSpan(span, err) }()
+
 	p, err := s.repo.GetByID(ctx, projectID)
 	if err != nil {
 		return err
@@ -83,6 +110,29 @@ func (s *ProjectService) Delete(ctx context.Context, requesterID string, request
 	return s.repo.Delete(ctx, projectID)
 }
 
+// SetArchived archives or unarchives a project. An archived project is
+// read-only: it and its tasks cannot change until it is unarchived, though
+// it can still be deleted. The owner and admins may do either.
+func (s *ProjectService) SetArchived(ctx context.Context, requesterID string, requesterRole string, projectID string, archived bool) (_ *models.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectService.SetArchived",
+		attribute.String("project.id", projectID), attribute.Bool("project.archived", archived))
+	defer func() { endSpan(span, err) }()
+
+	p, err := s.repo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin(requesterRole) && p.OwnerID != requesterID {
+		return nil, ErrForbidden
+	}
+
+	if err := s.repo.SetArchived(ctx, projectID, archived); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, projectID)
+}
+
 func isAdmin(role string) bool {
 	return role == "admin"
 }
@@ -60,14 +60,32 @@ func (f *fakeProjectRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (f *fakeProjectRepo) List(ctx context.Context) ([]models.Project, error) {
+func (f *fakeProjectRepo) List(ctx context.Context, archived bool) ([]models.Project, error) {
 	out := make([]models.Project, 0, len(f.projects))
 	for _, p := range f.projects {
-		out = append(out, p)
+		if (p.ArchivedAt != nil) == archived {
+			out = append(out, p)
+		}
 	}
 	return out, nil
 }
 
+func (f *fakeProjectRepo) SetArchived(ctx context.Context, id string, archived bool) error {
+	p, ok := f.projects[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	switch {
+	case !archived:
+		p.ArchivedAt = nil
+	case p.ArchivedAt == nil:
+		now := time.Now()
+		p.ArchivedAt = &now
+	}
+	f.projects[id] = p
+	return nil
+}
+
 func TestProjectOwnerCanAccess(t *testing.T) {
 	repo := newFakeProjectRepo()
 	svc := NewProjectService(repo)
@@ -177,3 +195,40 @@ func TestGetByIDNotFound(t *testing.T) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestArchivedProjectIsListedApartAndReadOnly(t *testing.T) {
+	repo := newFakeProjectRepo()
+	svc := NewProjectService(repo)
+	ctx := context.Background()
+
+	_ = repo.Create(ctx, &models.Project{ID: "p1", Name: "done", OwnerID: "user1", CreatedAt: time.Now()})
+	_ = repo.Create(ctx, &models.Project{ID: "p2", Name: "active", OwnerID: "user1", CreatedAt: time.Now()})
+
+	if _, err := svc.SetArchived(ctx, "user2", "user", "p1", true); err != ErrForbidden {
+		t.Fatalf("expected ErrForbidden for a non-owner, got %v", err)
+	}
+	p, err := svc.SetArchived(ctx, "user1", "user", "p1", true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p.ArchivedAt == nil {
+		t.Fatalf("expected archivedAt to be set")
+	}
+
+	active, _ := svc.List(ctx, "user1", "user", false)
+	archived, _ := svc.List(ctx, "user1", "user", true)
+	if len(active) != 1 || active[0].ID != "p2" || len(archived) != 1 || archived[0].ID != "p1" {
+		t.Fatalf("expected p2 active and p1 archived, got %+v and %+v", active, archived)
+	}
+
+	if err := svc.UpdateName(ctx, "user1", "user", "p1", "renamed"); err != ErrProjectArchived {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+
+	if _, err := svc.SetArchived(ctx, "admin1", "admin", "p1", false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := svc.UpdateName(ctx, "user1", "user", "p1", "renamed"); err != nil {
+		t.Fatalf("expected no error after unarchiving, got %v", err)
+	}
+}
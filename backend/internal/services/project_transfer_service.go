@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"task-management-platform/backend/internal/cache"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// ProjectTransferService hands projects to new owners. The owner or an admin
+// offers a project to a user, and it changes hands only once that user
+// accepts.
+type ProjectTransferService struct {
+	transfers repository.ProjectTransferRepository
+	projects  repository.ProjectRepository
+	users     AccessTokenUserRepo
+	cache     *cache.Loader
+	now       func() time.Time
+}
+
+func NewProjectTransferService(transfers repository.ProjectTransferRepository, projects repository.ProjectRepository, users AccessTokenUserRepo, loader *cache.Loader) *ProjectTransferService {
+	return &ProjectTransferService{transfers: transfers, projects: projects, users: users, cache: loader, now: time.Now}
+}
+
+// Offer proposes the project to toUserID, replacing a pending offer.
+func (s *ProjectTransferService) Offer(ctx context.Context, actor models.User, projectID, toUserID string) (_ *models.ProjectTransfer, err error) {
+	ctx, span := startSpan(ctx, "ProjectTransferService.Offer", attribute.String("project.id", projectID))
+	defer func() { endSpan(span, err) }()
+
+	p, err := s.projects.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeProject(actor, p) {
+		return nil, ErrForbidden
+	}
+
+	var v ValidationError
+	if _, err := uuid.Parse(toUserID); err != nil {
+		v.add("userId", "uuid", "must be a UUID")
+	} else if toUserID == p.OwnerID {
+		v.add("userId", "different", "already owns the project")
+	} else if _, err := s.users.GetByID(ctx, toUserID); errors.Is(err, repository.ErrNotFound) {
+		v.add("userId", "exists", "user not found")
+	} else if err != nil {
+		return nil, err
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	t := &models.ProjectTransfer{
+		ProjectID:   p.ID,
+		ProjectName: p.Name,
+		FromUserID:  p.OwnerID,
+		ToUserID:    toUserID,
+		RequestedBy: &actor.ID,
+		CreatedAt:   s.now().UTC(),
+	}
+	if err := s.transfers.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// List returns the pending transfers offered to the actor or of projects the
+// actor owns.
+func (s *ProjectTransferService) List(ctx context.Context, actor models.User) (_ []models.ProjectTransfer, err error) {
+	ctx, span := startSpan(ctx, "ProjectTransferService.List")
+	defer func() { endSpan(span, err) }()
+
+	return s.transfers.ListForUser(ctx, actor.ID)
+}
+
+// Accept makes the actor the owner of a project offered to them.
+func (s *ProjectTransferService) Accept(ctx context.Context, actor models.User, projectID string) (_ *models.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectTransferService.Accept", attribute.String("project.id", projectID))
+	defer func() { endSpan(span, err) }()
+
+	t, err := s.transfers.GetByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if t.ToUserID != actor.ID {
+		return nil, ErrForbidden
+	}
+
+	if err := s.transfers.Accept(ctx, projectID, actor.ID); err != nil {
+		return nil, err
+	}
+	if s.cache != nil {
+		s.cache.InvalidatePrefix(ctx, repository.CachePrefixProjects)
+	}
+	return s.projects.GetByID(ctx, projectID)
+}
+
+// Cancel withdraws a pending transfer. The recipient declines it the same
+// way.
+func (s *ProjectTransferService) Cancel(ctx context.Context, actor models.User, projectID string) (err error) {
+	ctx, span := startSpan(ctx, "ProjectTransferService.Cancel", attribute.String("project.id", projectID))
+	defer func() { endSpan(span, err) }()
+
+	t, err := s.transfers.GetByProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if t.ToUserID != actor.ID {
+		p, err := s.projects.GetByID(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if !canSeeProject(actor, p) {
+			return ErrForbidden
+		}
+	}
+
+	return s.transfers.Delete(ctx, projectID)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+type fakeProjectTransferRepo struct {
+	projects  *fakeProjectRepo
+	transfers map[string]models.ProjectTransfer
+}
+
+func (f *fakeProjectTransferRepo) Create(ctx context.Context, t *models.ProjectTransfer) error {
+	f.transfers[t.ProjectID] = *t
+	return nil
+}
+
+func (f *fakeProjectTransferRepo) GetByProject(ctx context.Context, projectID string) (*models.ProjectTransfer, error) {
+	t, ok := f.transfers[projectID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (f *fakeProjectTransferRepo) ListForUser(ctx context.Context, userID string) ([]models.ProjectTransfer, error) {
+	out := make([]models.ProjectTransfer, 0)
+	for _, t := range f.transfers {
+		if t.ToUserID == userID || f.projects.projects[t.ProjectID].OwnerID == userID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeProjectTransferRepo) Delete(ctx context.Context, projectID string) error {
+	if _, ok := f.transfers[projectID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.transfers, projectID)
+	return nil
+}
+
+func (f *fakeProjectTransferRepo) Accept(ctx context.Context, projectID string, userID string) error {
+	t, ok := f.transfers[projectID]
+	if !ok || t.ToUserID != userID {
+		return repository.ErrNotFound
+	}
+	p := f.projects.projects[projectID]
+	if p.OwnerID != t.FromUserID {
+		return repository.ErrNotFound
+	}
+	delete(f.transfers, projectID)
+	p.OwnerID = userID
+	f.projects.projects[projectID] = p
+	return nil
+}
+
+func newTransferFixture(t *testing.T) (*ProjectTransferService, *fakeProjectTransferRepo, models.User, models.User) {
+	t.Helper()
+
+	owner := models.User{ID: uuid.NewString(), Role: "user"}
+	recipient := models.User{ID: uuid.NewString(), Role: "user"}
+
+	users := newFakeUserRepo()
+	_ = users.Create(context.Background(), &models.User{ID: owner.ID, Email: "owner@test.com", Role: "user"})
+	_ = users.Create(context.Background(), &models.User{ID: recipient.ID, Email: "recipient@test.com", Role: "user"})
+
+	projects := newFakeProjectRepo()
+	_ = projects.Create(context.Background(), &models.Project{ID: "p1", Name: "Launch", OwnerID: owner.ID})
+
+	transfers := &fakeProjectTransferRepo{projects: projects, transfers: map[string]models.ProjectTransfer{}}
+	return NewProjectTransferService(transfers, projects, users, nil), transfers, owner, recipient
+}
+
+func TestProjectTransfer_OfferAndAccept(t *testing.T) {
+	svc, transfers, owner, recipient := newTransferFixture(t)
+	ctx := context.Background()
+
+	if _, err := svc.Offer(ctx, recipient, "p1", recipient.ID); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden when a non-owner offers, got %v", err)
+	}
+
+	transfer, err := svc.Offer(ctx, owner, "p1", recipient.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if transfer.FromUserID != owner.ID || transfer.ToUserID != recipient.ID {
+		t.Fatalf("unexpected transfer: %+v", transfer)
+	}
+
+	if p, _ := transfers.projects.GetByID(ctx, "p1"); p.OwnerID != owner.ID {
+		t.Fatalf("expected the owner to keep the project until acceptance, got %s", p.OwnerID)
+	}
+	if _, err := svc.Accept(ctx, owner, "p1"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden when the owner accepts, got %v", err)
+	}
+
+	p, err := svc.Accept(ctx, recipient, "p1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p.OwnerID != recipient.ID {
+		t.Fatalf("expected the recipient to own the project, got %s", p.OwnerID)
+	}
+	if _, ok := transfers.transfers["p1"]; ok {
+		t.Fatalf("expected the accepted transfer to be removed")
+	}
+}
+
+func TestProjectTransfer_OfferValidatesRecipient(t *testing.T) {
+	svc, _, owner, _ := newTransferFixture(t)
+	ctx := context.Background()
+
+	for name, to := range map[string]string{
+		"not a uuid": "someone",
+		"owner":      owner.ID,
+		"unknown":    uuid.NewString(),
+	} {
+		var invalid *ValidationError
+		if _, err := svc.Offer(ctx, owner, "p1", to); !errors.As(err, &invalid) {
+			t.Fatalf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestProjectTransfer_RecipientDeclines(t *testing.T) {
+	svc, transfers, owner, recipient := newTransferFixture(t)
+	ctx := context.Background()
+
+	if _, err := svc.Offer(ctx, owner, "p1", recipient.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stranger := models.User{ID: uuid.NewString(), Role: "user"}
+	if err := svc.Cancel(ctx, stranger, "p1"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a stranger, got %v", err)
+	}
+	if err := svc.Cancel(ctx, recipient, "p1"); err != nil {
+		t.Fatalf("expected the recipient to decline, got %v", err)
+	}
+	if len(transfers.transfers) != 0 {
+		t.Fatalf("expected no pending transfer, got %+v", transfers.transfers)
+	}
+	if _, err := svc.Accept(ctx, recipient, "p1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after declining, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// statusColors are the chart colors per status, as RGB.
+var statusColors = map[string][3]int{
+	"todo":        {189, 195, 199},
+	"in_progress": {52, 152, 219},
+	"done":        {39, 174, 96},
+}
+
+var statusLabels = map[string]string{
+	"todo":        "To do",
+	"in_progress": "In progress",
+	"done":        "Done",
+}
+
+// WriteProjectReportPDF renders a report as an A4 PDF using the built-in
+// Helvetica font, so no font files are needed at runtime.
+func WriteProjectReportPDF(w io.Writer, r *ProjectReport) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Status report: "+r.Project.Name, true)
+	pdf.SetCreator("Task Management Platform", false)
+	pdf.SetCreationDate(r.GeneratedAt)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+
+	// core fonts are cp1252; translate so accented names survive
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(127, 140, 141)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Generated %s  -  page %d/{nb}",
+			r.GeneratedAt.Format("2006-01-02 15:04 MST"), pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	width, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	content := width - left - right
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(44, 62, 80)
+	pdf.CellFormat(0, 10, tr(r.Project.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTextColor(127, 140, 141)
+	pdf.CellFormat(0, 6, "Project status report", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	heading := func(title string) {
+		pdf.Ln(3)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.SetTextColor(44, 62, 80)
+		pdf.CellFormat(0, 8, title, "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "", 10)
+	}
+
+	heading("Project")
+	owner := r.OwnerEmail
+	if owner == "" {
+		owner = r.Project.OwnerID
+	}
+	for _, row := range [][2]string{
+		{"Project ID", r.Project.ID},
+		{"Owner", owner},
+		{"Created", r.Project.CreatedAt.UTC().Format(time.DateOnly)},
+		{"Report date", r.GeneratedAt.Format(time.DateOnly)},
+	} {
+		pdf.SetTextColor(127, 140, 141)
+		pdf.CellFormat(35, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.SetTextColor(44, 62, 80)
+		pdf.CellFormat(0, 6, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+
+	heading("Progress")
+	pdf.SetTextColor(44, 62, 80)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%d tasks, %.0f%% complete, %d overdue",
+		r.Total, r.Completion()*100, len(r.Overdue)), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	drawCompletionBar(pdf, r, left, content)
+
+	heading("Overdue tasks")
+	if len(r.Overdue) == 0 {
+		pdf.SetTextColor(127, 140, 141)
+		pdf.CellFormat(0, 6, "No overdue tasks.", "", 1, "L", false, 0, "")
+	} else {
+		cols := []float64{content - 105, 45, 25, 15, 20}
+		tableHeader(pdf, cols, []string{"Task", "Assignee", "Due", "Days", "Status"})
+		for _, t := range r.Overdue {
+			tableRow(pdf, cols, []string{
+				fitText(pdf, tr(t.Title), cols[0]),
+				fitText(pdf, tr(t.Assignee), cols[1]),
+				t.DueDate.Format(time.DateOnly),
+				strconv.Itoa(t.DaysOverdue),
+				statusLabels[t.Status],
+			})
+		}
+	}
+
+	heading("By assignee")
+	if len(r.Assignees) == 0 {
+		pdf.SetTextColor(127, 140, 141)
+		pdf.CellFormat(0, 6, "No tasks yet.", "", 1, "L", false, 0, "")
+	} else {
+		cols := []float64{content - 110, 22, 22, 22, 22}
+		tableHeader(pdf, cols, []string{"Assignee", "To do", "In progress", "Done", "Overdue"})
+		for _, a := range r.Assignees {
+			tableRow(pdf, cols, []string{
+				fitText(pdf, tr(a.Assignee), cols[0]),
+				strconv.Itoa(a.ByStatus["todo"]),
+				strconv.Itoa(a.ByStatus["in_progress"]),
+				strconv.Itoa(a.ByStatus["done"]),
+				strconv.Itoa(a.Overdue),
+			})
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// drawCompletionBar draws one bar split by status with a legend below.
+func drawCompletionBar(pdf *fpdf.Fpdf, r *ProjectReport, x, width float64) {
+	const height = 9
+	y := pdf.GetY()
+
+	pdf.SetDrawColor(189, 195, 199)
+	if r.Total == 0 {
+		pdf.Rect(x, y, width, height, "D")
+	} else {
+		offset := x
+		for _, status := range reportStatuses {
+			n := r.ByStatus[status]
+			if n == 0 {
+				continue
+			}
+			w := width * float64(n) / float64(r.Total)
+			c := statusColors[status]
+			pdf.SetFillColor(c[0], c[1], c[2])
+			pdf.Rect(offset, y, w, height, "F")
+			offset += w
+		}
+		pdf.Rect(x, y, width, height, "D")
+	}
+	pdf.SetY(y + height + 3)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, status := range reportStatuses {
+		c := statusColors[status]
+		pdf.SetFillColor(c[0], c[1], c[2])
+		pdf.Rect(pdf.GetX(), pdf.GetY()+1, 3, 3, "F")
+		pdf.SetX(pdf.GetX() + 4)
+		pdf.SetTextColor(44, 62, 80)
+		pdf.CellFormat(40, 5, fmt.Sprintf("%s: %d", statusLabels[status], r.ByStatus[status]), "", 0, "L", false, 0, "")
+	}
+	pdf.Ln(7)
+	pdf.SetFont("Helvetica", "", 10)
+}
+
+func tableHeader(pdf *fpdf.Fpdf, cols []float64, titles []string) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(236, 240, 241)
+	pdf.SetTextColor(44, 62, 80)
+	for i, title := range titles {
+		pdf.CellFormat(cols[i], 7, title, "", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 9)
+}
+
+func tableRow(pdf *fpdf.Fpdf, cols []float64, values []string) {
+	pdf.SetDrawColor(236, 240, 241)
+	for i, v := range values {
+		pdf.CellFormat(cols[i], 6, v, "B", 0, "L", false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// fitText shortens s with an ellipsis until it fits a cell of width w.
+func fitText(pdf *fpdf.Fpdf, s string, w float64) string {
+	const padding = 2
+	if pdf.GetStringWidth(s) <= w-padding {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > w-padding {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
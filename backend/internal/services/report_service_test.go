@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+)
+
+type fakeMinimalUsers []models.MinimalUser
+
+func (f fakeMinimalUsers) ListMinimal(ctx context.Context) ([]models.MinimalUser, error) {
+	return f, nil
+}
+
+func newReportFixture(t *testing.T) (*ReportService, string) {
+	t.Helper()
+
+	projects := newFakeProjectRepo()
+	projectID := uuid.New()
+	projects.projects[projectID.String()] = models.Project{
+		ID:        projectID.String(),
+		Name:      "Launch",
+		OwnerID:   "owner-1",
+		CreatedAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+	}
+
+	assignee := uuid.New()
+	past := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	tasks := newFakeTaskRepo()
+	for _, task := range []models.Task{
+		{Title: "Write copy", Status: "todo", AssigneeID: &assignee, DueDate: &past},
+		{Title: "Ship build", Status: "done", AssigneeID: &assignee, DueDate: &past},
+		{Title: "Plan party", Status: "in_progress", DueDate: &future},
+		{Title: "Book venue", Status: "todo"},
+	} {
+		task.ID = uuid.New()
+		task.ProjectID = projectID
+		tasks.tasks[task.ID] = task
+	}
+	// another project's task must not leak into the report
+	other := uuid.New()
+	tasks.tasks[other] = models.Task{ID: other, ProjectID: uuid.New(), Title: "Elsewhere", Status: "todo"}
+
+	users := fakeMinimalUsers{
+		{ID: "owner-1", Email: "owner@example.com"},
+		{ID: assignee.String(), Email: "dev@example.com"},
+	}
+
+	svc := NewReportService(NewProjectService(projects), tasks, users)
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC) }
+	return svc, projectID.String()
+}
+
+func TestReportService_ProjectReport(t *testing.T) {
+	svc, projectID := newReportFixture(t)
+
+	report, err := svc.ProjectReport(context.Background(), "owner-1", "user", projectID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if report.OwnerEmail != "owner@example.com" {
+		t.Fatalf("expected owner email, got %q", report.OwnerEmail)
+	}
+	if report.Total != 4 {
+		t.Fatalf("expected 4 tasks, got %d", report.Total)
+	}
+	if report.ByStatus["todo"] != 2 || report.ByStatus["in_progress"] != 1 || report.ByStatus["done"] != 1 {
+		t.Fatalf("unexpected status counts: %v", report.ByStatus)
+	}
+	if got := report.Completion(); got != 0.25 {
+		t.Fatalf("expected completion 0.25, got %v", got)
+	}
+
+	if len(report.Overdue) != 1 {
+		t.Fatalf("expected 1 overdue task, got %d", len(report.Overdue))
+	}
+	if o := report.Overdue[0]; o.Title != "Write copy" || o.DaysOverdue != 9 || o.Assignee != "dev@example.com" {
+		t.Fatalf("unexpected overdue task: %+v", o)
+	}
+
+	if len(report.Assignees) != 2 {
+		t.Fatalf("expected 2 assignee rows, got %d", len(report.Assignees))
+	}
+	for _, a := range report.Assignees {
+		switch a.Assignee {
+		case "dev@example.com":
+			if a.Total != 2 || a.Overdue != 1 || a.ByStatus["done"] != 1 {
+				t.Fatalf("unexpected assignee summary: %+v", a)
+			}
+		case unassignedLabel:
+			if a.Total != 2 || a.Overdue != 0 {
+				t.Fatalf("unexpected unassigned summary: %+v", a)
+			}
+		default:
+			t.Fatalf("unexpected assignee %q", a.Assignee)
+		}
+	}
+}
+
+func TestReportService_ProjectReport_Forbidden(t *testing.T) {
+	svc, projectID := newReportFixture(t)
+
+	if _, err := svc.ProjectReport(context.Background(), "someone-else", "user", projectID); err != ErrForbidden {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := svc.ProjectReport(context.Background(), "admin-1", "admin", projectID); err != nil {
+		t.Fatalf("expected admin to read any project, got %v", err)
+	}
+}
+
+func TestWriteProjectReportPDF(t *testing.T) {
+	svc, projectID := newReportFixture(t)
+
+	report, err := svc.ProjectReport(context.Background(), "owner-1", "user", projectID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProjectReportPDF(&buf, report); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("expected PDF output, got %q", buf.Bytes()[:min(16, buf.Len())])
+	}
+}
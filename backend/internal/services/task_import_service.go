@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// ErrInvalidTaskImport means at least one row failed validation; the report
+// says which, and nothing was written.
+var ErrInvalidTaskImport = errors.New("invalid task import")
+
+// MaxTaskImportRows bounds a single upload so one request cannot hold a
+// transaction open for long.
+const MaxTaskImportRows = 5000
+
+type TaskImportFormat string
+
+const (
+	TaskImportCSV  TaskImportFormat = "csv"
+	TaskImportJSON TaskImportFormat = "json"
+)
+
+// TaskImportRow is one task as read from an upload, before validation. Line
+// is the CSV line or the 1-based JSON array index.
+type TaskImportRow struct {
+	Line          int
+	Title         string
+	Description   string
+	Status        string
+	AssigneeID    string
+	AssigneeEmail string
+	DueDate       string
+}
+
+type TaskImportReport struct {
+	DryRun  bool                  `json:"dryRun"`
+	Total   int                   `json:"total"`
+	Valid   int                   `json:"valid"`
+	Created int                   `json:"created"`
+	Rows    []TaskImportRowResult `json:"rows"`
+}
+
+type TaskImportRowResult struct {
+	Line   int      `json:"line"`
+	Title  string   `json:"title"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+	// TaskID is set once the task has been created.
+	TaskID *uuid.UUID `json:"taskId,omitempty"`
+}
+
+type TaskImportRepo interface {
+	CreateMany(ctx context.Context, tasks []models.Task) error
+}
+
+type TaskImportService struct {
+	tasks    TaskImportRepo
+	projects repository.ProjectRepository
+	users    ReportUserRepo
+}
+
+func NewTaskImportService(tasks TaskImportRepo, projects repository.ProjectRepository, users ReportUserRepo) *TaskImportService {
+	return &TaskImportService{tasks: tasks, projects: projects, users: users}
+}
+
+// Import validates every row with the same rules as a single create and
+// stores them in one transaction. A single bad row blocks the whole upload so
+// a corrected file can be sent again without creating duplicates.
+func (s *TaskImportService) Import(ctx context.Context, actor models.User, projectID uuid.UUID, rows []TaskImportRow, dryRun bool) (_ *TaskImportReport, err error) {
+	ctx, span := startSpan(ctx, "TaskImportService.Import",
+		attribute.String("project.id", projectID.String()),
+		attribute.Int("import.rows", len(rows)),
+		attribute.Bool("import.dry_run", dryRun),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if actor.Role != roleAdmin && actor.Role != roleMember {
+		return nil, ErrForbidden
+	}
+	if len(rows) == 0 || len(rows) > MaxTaskImportRows {
+		return nil, ErrBadRequest
+	}
+	project, err := s.projects.GetByID(ctx, projectID.String())
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if project.ArchivedAt != nil {
+		return nil, ErrProjectArchived
+	}
+
+	users, err := s.users.ListMinimal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]uuid.UUID, len(users))
+	known := make(map[uuid.UUID]bool, len(users))
+	for _, u := range users {
+		id, err := uuid.Parse(u.ID)
+		if err != nil {
+			continue
+		}
+		byEmail[strings.ToLower(u.Email)] = id
+		known[id] = true
+	}
+
+	report := &TaskImportReport{DryRun: dryRun, Total: len(rows), Rows: make([]TaskImportRowResult, 0, len(rows))}
+	tasks := make([]models.Task, 0, len(rows))
+
+	for _, row := range rows {
+		task, problems := buildImportedTask(row, projectID, byEmail, known)
+		if len(problems) == 0 {
+			if err := validateTaskCreate(&task); err != nil {
+				problems = append(problems, validationMessages(err)...)
+			}
+		}
+		if len(problems) == 0 && authorizeTaskCreate(actor, &task) != nil {
+			problems = append(problems, "members can only assign tasks to themselves")
+		}
+
+		result := TaskImportRowResult{Line: row.Line, Title: strings.TrimSpace(row.Title), Valid: len(problems) == 0, Errors: problems}
+		if result.Valid {
+			report.Valid++
+			task.ID = uuid.New()
+			tasks = append(tasks, task)
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if report.Valid != report.Total {
+		return report, ErrInvalidTaskImport
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	if err := s.tasks.CreateMany(ctx, tasks); err != nil {
+		return nil, err
+	}
+	report.Created = len(tasks)
+	for i := range report.Rows {
+		report.Rows[i].TaskID = &tasks[i].ID
+	}
+	return report, nil
+}
+
+// buildImportedTask resolves the row's references. Assignees are matched by
+// email; an assignee_id is only accepted when it names an existing user.
+func buildImportedTask(row TaskImportRow, projectID uuid.UUID, byEmail map[string]uuid.UUID, known map[uuid.UUID]bool) (models.Task, []string) {
+	task := models.Task{
+		ProjectID:   projectID,
+		Title:       row.Title,
+		Description: row.Description,
+		Status:      row.Status,
+	}
+	var problems []string
+
+	email := strings.ToLower(strings.TrimSpace(row.AssigneeEmail))
+	rawID := strings.TrimSpace(row.AssigneeID)
+	switch {
+	case email != "":
+		id, ok := byEmail[email]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown assignee email %q", row.AssigneeEmail))
+			break
+		}
+		if rawID != "" && rawID != id.String() {
+			problems = append(problems, "assignee_id and assignee_email name different users")
+			break
+		}
+		task.AssigneeID = &id
+	case rawID != "":
+		id, err := uuid.Parse(rawID)
+		if err != nil || !known[id] {
+			problems = append(problems, fmt.Sprintf("unknown assignee_id %q", rawID))
+			break
+		}
+		task.AssigneeID = &id
+	}
+
+	if due := strings.TrimSpace(row.DueDate); due != "" {
+		d, err := parseImportDueDate(due)
+		if err != nil {
+			problems = append(problems, "due_date must be YYYY-MM-DD")
+		} else {
+			task.DueDate = &d
+		}
+	}
+
+	return task, problems
+}
+
+// parseImportDueDate accepts a plain day or the RFC 3339 timestamp the JSON
+// export writes for one.
+func parseImportDueDate(v string) (time.Time, error) {
+	if d, err := time.Parse(time.DateOnly, v); err == nil {
+		return d, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// validationMessages lists what validateTaskCreate rejected.
+func validationMessages(err error) []string {
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		return []string{"invalid task"}
+	}
+	msgs := make([]string, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return msgs
+}
+
+// ParseTaskImport reads an upload in either format. CSV files use the
+// columns of the export's tasks.csv plus an optional assignee_email; id,
+// project_id and timestamp columns are ignored because imported tasks are
+// always new. JSON is an array of task objects as in the export's tasks.json,
+// again with an optional assigneeEmail.
+func ParseTaskImport(format TaskImportFormat, r io.Reader) ([]TaskImportRow, error) {
+	switch format {
+	case TaskImportCSV:
+		return parseTaskImportCSV(r)
+	case TaskImportJSON:
+		return parseTaskImportJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseTaskImportCSV(r io.Reader) ([]TaskImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil, errors.New("csv header must include a title column")
+	}
+
+	field := func(rec []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+
+	var rows []TaskImportRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if len(rows) == MaxTaskImportRows {
+			return nil, fmt.Errorf("too many rows, the limit is %d", MaxTaskImportRows)
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, TaskImportRow{
+			Line:          line,
+			Title:         field(rec, "title"),
+			Description:   field(rec, "description"),
+			Status:        field(rec, "status"),
+			AssigneeID:    field(rec, "assignee_id"),
+			AssigneeEmail: field(rec, "assignee_email"),
+			DueDate:       field(rec, "due_date"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file has no rows")
+	}
+	return rows, nil
+}
+
+type taskImportObject struct {
+	Title         string  `json:"title"`
+	Description   string  `json:"description"`
+	Status        string  `json:"status"`
+	AssigneeID    *string `json:"assigneeId"`
+	AssigneeEmail *string `json:"assigneeEmail"`
+	DueDate       *string `json:"dueDate"`
+}
+
+func parseTaskImportJSON(r io.Reader) ([]TaskImportRow, error) {
+	var objects []taskImportObject
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("file has no rows")
+	}
+	if len(objects) > MaxTaskImportRows {
+		return nil, fmt.Errorf("too many rows, the limit is %d", MaxTaskImportRows)
+	}
+
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	rows := make([]TaskImportRow, 0, len(objects))
+	for i, o := range objects {
+		rows = append(rows, TaskImportRow{
+			Line:          i + 1,
+			Title:         o.Title,
+			Description:   o.Description,
+			Status:        o.Status,
+			AssigneeID:    deref(o.AssigneeID),
+			AssigneeEmail: deref(o.AssigneeEmail),
+			DueDate:       deref(o.DueDate),
+		})
+	}
+	return rows, nil
+}
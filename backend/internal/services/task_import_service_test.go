@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+)
+
+func newTaskImportFixture(t *testing.T) (*TaskImportService, *fakeTaskRepo, uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	projects := newFakeProjectRepo()
+	projectID := uuid.New()
+	projects.projects[projectID.String()] = models.Project{ID: projectID.String(), Name: "Migration", OwnerID: "owner-1"}
+
+	member := uuid.New()
+	users := fakeMinimalUsers{{ID: member.String(), Email: "Dev@Example.com"}}
+
+	tasks := newFakeTaskRepo()
+	return NewTaskImportService(tasks, projects, users), tasks, projectID, member
+}
+
+func TestParseTaskImport_CSV(t *testing.T) {
+	in := "id,project_id,title,description,status,assignee_id,due_date,created_at,updated_at,assignee_email\n" +
+		"x,y,Write docs,\"multi\nline\",todo,,2026-04-01,,,dev@example.com\n" +
+		",,Review,,done,,,,,\n"
+
+	rows, err := ParseTaskImport(TaskImportCSV, strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Title != "Write docs" || rows[0].DueDate != "2026-04-01" || rows[0].AssigneeEmail != "dev@example.com" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Line != 4 {
+		t.Fatalf("expected second row on line 4, got %d", rows[1].Line)
+	}
+
+	if _, err := ParseTaskImport(TaskImportCSV, strings.NewReader("name,status\nx,todo\n")); err == nil {
+		t.Fatalf("expected error for csv without title column")
+	}
+}
+
+func TestParseTaskImport_JSON(t *testing.T) {
+	in := `[{"title":"A","status":"todo","assigneeId":null,"dueDate":"2026-04-01T00:00:00Z"},{"title":"B","assigneeEmail":"dev@example.com"}]`
+
+	rows, err := ParseTaskImport(TaskImportJSON, strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(rows) != 2 || rows[1].Line != 2 || rows[1].AssigneeEmail != "dev@example.com" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	if _, err := ParseTaskImport(TaskImportJSON, strings.NewReader(`{"title":"A"}`)); err == nil {
+		t.Fatalf("expected error for a non-array body")
+	}
+}
+
+func TestTaskImportService_Import(t *testing.T) {
+	svc, repo, projectID, member := newTaskImportFixture(t)
+	admin := models.User{ID: uuid.NewString(), Role: "admin"}
+
+	rows := []TaskImportRow{
+		{Line: 2, Title: " Write docs ", AssigneeEmail: "dev@example.com", DueDate: "2026-04-01"},
+		{Line: 3, Title: "Review", Status: "in_progress", AssigneeID: member.String()},
+	}
+
+	report, err := svc.Import(context.Background(), admin, projectID, rows, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Created != 2 || len(repo.tasks) != 2 {
+		t.Fatalf("expected 2 created tasks, got report %d and repo %d", report.Created, len(repo.tasks))
+	}
+
+	created := repo.tasks[*report.Rows[0].TaskID]
+	if created.Title != "Write docs" || created.Status != "todo" || created.ProjectID != projectID {
+		t.Fatalf("unexpected task: %+v", created)
+	}
+	if created.AssigneeID == nil || *created.AssigneeID != member {
+		t.Fatalf("expected assignee mapped by email, got %v", created.AssigneeID)
+	}
+	if created.DueDate == nil || created.DueDate.Format("2006-01-02") != "2026-04-01" {
+		t.Fatalf("unexpected due date: %v", created.DueDate)
+	}
+}
+
+func TestTaskImportService_Import_InvalidRowsBlockWrite(t *testing.T) {
+	svc, repo, projectID, _ := newTaskImportFixture(t)
+	admin := models.User{ID: uuid.NewString(), Role: "admin"}
+
+	rows := []TaskImportRow{
+		{Line: 2, Title: "Fine"},
+		{Line: 3, Title: "", Status: "blocked"},
+		{Line: 4, Title: "Who", AssigneeEmail: "nobody@example.com"},
+		{Line: 5, Title: "When", DueDate: "next week"},
+	}
+
+	report, err := svc.Import(context.Background(), admin, projectID, rows, false)
+	if !errors.Is(err, ErrInvalidTaskImport) {
+		t.Fatalf("expected ErrInvalidTaskImport, got %v", err)
+	}
+	if len(repo.tasks) != 0 {
+		t.Fatalf("expected nothing written, got %d tasks", len(repo.tasks))
+	}
+	if report.Valid != 1 || report.Total != 4 {
+		t.Fatalf("expected 1 of 4 valid, got %d of %d", report.Valid, report.Total)
+	}
+	if errs := report.Rows[1].Errors; len(errs) != 2 {
+		t.Fatalf("expected title and status errors, got %v", errs)
+	}
+	if report.Rows[2].Valid || report.Rows[3].Valid {
+		t.Fatalf("expected unknown assignee and bad due date to be rejected: %+v", report.Rows)
+	}
+}
+
+func TestTaskImportService_Import_DryRun(t *testing.T) {
+	svc, repo, projectID, _ := newTaskImportFixture(t)
+	admin := models.User{ID: uuid.NewString(), Role: "admin"}
+
+	report, err := svc.Import(context.Background(), admin, projectID, []TaskImportRow{{Line: 2, Title: "Only checked"}}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.DryRun || report.Valid != 1 || report.Created != 0 || len(repo.tasks) != 0 {
+		t.Fatalf("expected a validated dry run with no writes, got %+v and %d tasks", report, len(repo.tasks))
+	}
+}
+
+func TestTaskImportService_Import_MemberAssignmentRules(t *testing.T) {
+	svc, _, projectID, member := newTaskImportFixture(t)
+	other := models.User{ID: uuid.NewString(), Role: "user"}
+
+	rows := []TaskImportRow{
+		{Line: 2, Title: "Mine"},
+		{Line: 3, Title: "Theirs", AssigneeID: member.String()},
+	}
+	report, err := svc.Import(context.Background(), other, projectID, rows, true)
+	if !errors.Is(err, ErrInvalidTaskImport) {
+		t.Fatalf("expected ErrInvalidTaskImport, got %v", err)
+	}
+	if !report.Rows[0].Valid || report.Rows[1].Valid {
+		t.Fatalf("expected only the unassigned row to pass: %+v", report.Rows)
+	}
+
+	if _, err := svc.Import(context.Background(), other, uuid.New(), rows[:1], true); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for unknown project, got %v", err)
+	}
+}
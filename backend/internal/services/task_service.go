@@ -2,9 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"task-management-platform/backend/internal/models"
 	"task-management-platform/backend/internal/repository"
@@ -15,50 +19,89 @@ const (
 	roleMember = "user"
 )
 
+// maxTaskDepth is how many levels tasks nest, a top-level task being the
+// first: a task, its subtasks and theirs.
+const maxTaskDepth = 3
+
 type TaskService interface {
 	Create(ctx context.Context, actor models.User, task *models.Task) error
 	GetByID(ctx context.Context, actor models.User, id uuid.UUID) (*models.Task, error)
 	List(ctx context.Context, actor models.User, filters repository.TaskFilters) ([]models.Task, error)
-	Update(ctx context.Context, actor models.User, task *models.Task) error
+	// Update replaces the task's fields. With keepDueDate the stored due date
+	// stays and task.DueDate is ignored.
+	Update(ctx context.Context, actor models.User, task *models.Task, keepDueDate bool) error
 	Delete(ctx context.Context, actor models.User, id uuid.UUID) error
+	// GetDetail returns the task with its subtasks and checklist.
+	GetDetail(ctx context.Context, actor models.User, id uuid.UUID) (*models.TaskDetail, error)
+	// Move makes the task a subtask of parentID, or a top-level task when
+	// parentID is nil.
+	Move(ctx context.Context, actor models.User, id uuid.UUID, parentID *uuid.UUID) (*models.Task, error)
+	AddChecklistItem(ctx context.Context, actor models.User, taskID uuid.UUID, title string) (*models.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, actor models.User, item *models.ChecklistItem) error
+	DeleteChecklistItem(ctx context.Context, actor models.User, taskID, itemID uuid.UUID) error
 }
 
 type taskService struct {
-	tasks    repository.TaskRepository
-	projects repository.ProjectRepository
+	tasks     repository.TaskRepository
+	projects  repository.ProjectRepository
+	checklist repository.ChecklistRepository
 }
 
-func NewTaskService(tasks repository.TaskRepository, projects repository.ProjectRepository) TaskService {
+func NewTaskService(tasks repository.TaskRepository, projects repository.ProjectRepository, checklist repository.ChecklistRepository) TaskService {
 	return &taskService{
-		tasks:    tasks,
-		projects: projects,
+		tasks:     tasks,
+		projects:  projects,
+		checklist: checklist,
 	}
 }
 
-func (s *taskService) Create(ctx context.Context, actor models.User, task *models.Task) error {
+func (s *taskService) Create(ctx context.Context, actor models.User, task *models.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskService.Create", attribute.String("project.id", task.ProjectID.String()))
+	defer func() { endSpan(span, err) }()
+
 	if err := validateTaskCreate(task); err != nil {
 		return err
 	}
 
-	if _, err := s.projects.GetByID(ctx, task.ProjectID.String()); err != nil {
-		return ErrNotFound
+	if err := s.projectWritable(ctx, task.ProjectID); err != nil {
+		return err
 	}
 
-	if actor.Role == roleAdmin {
+	if err := authorizeTaskCreate(actor, task); err != nil {
+		return err
+	}
+	if task.ParentID == nil {
 		return s.tasks.Create(ctx, task)
 	}
+	return s.tasks.WithTree(ctx, task.ProjectID, func(tree repository.TaskTree) error {
+		if err := validateParent(ctx, tree, task, *task.ParentID); err != nil {
+			return err
+		}
+		return tree.Create(ctx, task)
+	})
+}
+
+// authorizeTaskCreate lets admins create any task and members create tasks
+// that are unassigned or assigned to themselves.
+func authorizeTaskCreate(actor models.User, task *models.Task) error {
+	if actor.Role == roleAdmin {
+		return nil
+	}
 
 	if actor.Role == roleMember {
 		if task.AssigneeID != nil && task.AssigneeID.String() != actor.ID {
 			return ErrForbidden
 		}
-		return s.tasks.Create(ctx, task)
+		return nil
 	}
 
 	return ErrForbidden
 }
 
-func (s *taskService) GetByID(ctx context.Context, actor models.User, id uuid.UUID) (*models.Task, error) {
+func (s *taskService) GetByID(ctx context.Context, actor models.User, id uuid.UUID) (_ *models.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskService.GetByID", attribute.String("task.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
 	task, err := s.tasks.GetByID(ctx, id)
 	if err != nil {
 		return nil, ErrNotFound
@@ -75,7 +118,10 @@ func (s *taskService) GetByID(ctx context.Context, actor models.User, id uuid.UU
 	return nil, ErrForbidden
 }
 
-func (s *taskService) List(ctx context.Context, actor models.User, filters repository.TaskFilters) ([]models.Task, error) {
+func (s *taskService) List(ctx context.Context, actor models.User, filters repository.TaskFilters) (_ []models.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskService.List")
+	defer func() { endSpan(span, err) }()
+
 	normalizeFilters(&filters)
 
 	if actor.Role == roleAdmin {
@@ -89,7 +135,10 @@ func (s *taskService) List(ctx context.Context, actor models.User, filters repos
 	return nil, ErrForbidden
 }
 
-func (s *taskService) Update(ctx context.Context, actor models.User, task *models.Task) error {
+func (s *taskService) Update(ctx context.Context, actor models.User, task *models.Task, keepDueDate bool) (err error) {
+	ctx, span := startSpan(ctx, "TaskService.Update", attribute.String("task.id", task.ID.String()))
+	defer func() { endSpan(span, err) }()
+
 	if err := validateTaskUpdate(task); err != nil {
 		return err
 	}
@@ -98,6 +147,14 @@ func (s *taskService) Update(ctx context.Context, actor models.User, task *model
 	if err != nil {
 		return ErrNotFound
 	}
+	if err := s.projectWritable(ctx, existing.ProjectID); err != nil {
+		return err
+	}
+
+	task.ParentID = existing.ParentID
+	if keepDueDate {
+		task.DueDate = existing.DueDate
+	}
 
 	if actor.Role == roleAdmin {
 		task.ProjectID = existing.ProjectID
@@ -119,24 +176,201 @@ func (s *taskService) Update(ctx context.Context, actor models.User, task *model
 	return ErrForbidden
 }
 
-func (s *taskService) Delete(ctx context.Context, actor models.User, id uuid.UUID) error {
-	existing, err := s.tasks.GetByID(ctx, id)
+func (s *taskService) Delete(ctx context.Context, actor models.User, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "TaskService.Delete", attribute.String("task.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	existing, err := s.writableTask(ctx, actor, id)
 	if err != nil {
-		return ErrNotFound
+		return err
 	}
+	return s.tasks.Delete(ctx, existing.ID)
+}
 
-	if actor.Role == roleAdmin {
-		return s.tasks.Delete(ctx, existing.ID)
+func (s *taskService) GetDetail(ctx context.Context, actor models.User, id uuid.UUID) (_ *models.TaskDetail, err error) {
+	ctx, span := startSpan(ctx, "TaskService.GetDetail", attribute.String("task.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	task, err := s.GetByID(ctx, actor, id)
+	if err != nil {
+		return nil, err
 	}
 
-	if actor.Role == roleMember {
-		if existing.AssigneeID == nil || existing.AssigneeID.String() != actor.ID {
-			return ErrForbidden
+	subtasks, err := s.tasks.ListSubtasks(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	checklist, err := s.checklist.List(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &models.TaskDetail{Task: *task, Subtasks: subtasks, Checklist: checklist}
+	detail.SubtaskProgress.Total = len(subtasks)
+	for _, st := range subtasks {
+		if st.Status == "done" {
+			detail.SubtaskProgress.Done++
+		}
+	}
+	detail.ChecklistProgress.Total = len(checklist)
+	for _, item := range checklist {
+		if item.Done {
+			detail.ChecklistProgress.Done++
 		}
-		return s.tasks.Delete(ctx, existing.ID)
 	}
+	return detail, nil
+}
 
-	return ErrForbidden
+func (s *taskService) Move(ctx context.Context, actor models.User, id uuid.UUID, parentID *uuid.UUID) (_ *models.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskService.Move", attribute.String("task.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	task, err := s.writableTask(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tasks.WithTree(ctx, task.ProjectID, func(tree repository.TaskTree) error {
+		if parentID != nil {
+			if err := validateParent(ctx, tree, task, *parentID); err != nil {
+				return err
+			}
+		}
+		return tree.SetParent(ctx, task.ID, parentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	task.ParentID = parentID
+	return task, nil
+}
+
+func (s *taskService) AddChecklistItem(ctx context.Context, actor models.User, taskID uuid.UUID, title string) (_ *models.ChecklistItem, err error) {
+	ctx, span := startSpan(ctx, "TaskService.AddChecklistItem", attribute.String("task.id", taskID.String()))
+	defer func() { endSpan(span, err) }()
+
+	item := &models.ChecklistItem{TaskID: taskID, Title: strings.TrimSpace(title)}
+	if err := validateChecklistItem(item); err != nil {
+		return nil, err
+	}
+	if _, err := s.writableTask(ctx, actor, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checklist.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *taskService) UpdateChecklistItem(ctx context.Context, actor models.User, item *models.ChecklistItem) (err error) {
+	ctx, span := startSpan(ctx, "TaskService.UpdateChecklistItem", attribute.String("task.id", item.TaskID.String()))
+	defer func() { endSpan(span, err) }()
+
+	item.Title = strings.TrimSpace(item.Title)
+	if err := validateChecklistItem(item); err != nil {
+		return err
+	}
+	if _, err := s.writableTask(ctx, actor, item.TaskID); err != nil {
+		return err
+	}
+
+	if err := s.checklist.Update(ctx, item); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *taskService) DeleteChecklistItem(ctx context.Context, actor models.User, taskID, itemID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "TaskService.DeleteChecklistItem", attribute.String("task.id", taskID.String()))
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.writableTask(ctx, actor, taskID); err != nil {
+		return err
+	}
+
+	if err := s.checklist.Delete(ctx, taskID, itemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// writableTask returns a task the actor may change: admins change any task
+// and members the tasks assigned to them, unless the project is archived.
+func (s *taskService) writableTask(ctx context.Context, actor models.User, id uuid.UUID) (*models.Task, error) {
+	task, err := s.tasks.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if err := s.projectWritable(ctx, task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	switch actor.Role {
+	case roleAdmin:
+		return task, nil
+	case roleMember:
+		if task.AssigneeID == nil || task.AssigneeID.String() != actor.ID {
+			return nil, ErrForbidden
+		}
+		return task, nil
+	}
+	return nil, ErrForbidden
+}
+
+// validateParent checks that task may become a subtask of parentID: the
+// parent is a live task of the same project, not the task or one of its
+// subtasks, and the task with its own subtasks stays within maxTaskDepth.
+func validateParent(ctx context.Context, tree repository.TaskTree, task *models.Task, parentID uuid.UUID) error {
+	var v ValidationError
+
+	parent, err := tree.GetByID(ctx, parentID)
+	if err != nil {
+		v.add("parentId", "exists", "parent task not found")
+		return v.err()
+	}
+	if parent.ProjectID != task.ProjectID {
+		v.add("parentId", "project", "parent task must be in the same project")
+		return v.err()
+	}
+
+	ancestors, err := tree.Ancestors(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if task.ID != uuid.Nil && (parentID == task.ID || slices.Contains(ancestors, task.ID)) {
+		v.add("parentId", "cycle", "a task cannot be nested under itself or its subtasks")
+		return v.err()
+	}
+
+	height := 0
+	if task.ID != uuid.Nil {
+		if height, err = tree.SubtreeHeight(ctx, task.ID); err != nil {
+			return err
+		}
+	}
+	// the parent sits at level len(ancestors)+1, the task one below it
+	if len(ancestors)+2+height > maxTaskDepth {
+		v.add("parentId", "depth", fmt.Sprintf("tasks nest at most %d levels deep", maxTaskDepth))
+	}
+	return v.err()
+}
+
+// projectWritable rejects task writes in a missing or archived project.
+func (s *taskService) projectWritable(ctx context.Context, projectID uuid.UUID) error {
+	p, err := s.projects.GetByID(ctx, projectID.String())
+	if err != nil {
+		return ErrNotFound
+	}
+	if p.ArchivedAt != nil {
+		return ErrProjectArchived
+	}
+	return nil
 }
 
 func validateTaskCreate(task *models.Task) error {
@@ -146,40 +380,54 @@ func validateTaskCreate(task *models.Task) error {
 	task.Title = strings.TrimSpace(task.Title)
 	task.Status = strings.TrimSpace(task.Status)
 
+	var v ValidationError
 	if task.ProjectID == uuid.Nil {
-		return ErrBadRequest
+		v.add("projectId", "required", "project is required")
 	}
 	if task.Title == "" {
-		return ErrBadRequest
+		v.add("title", "required", "title is required")
 	}
 	if task.Status == "" {
 		task.Status = "todo"
 	}
 	if !isValidStatus(task.Status) {
-		return ErrBadRequest
+		v.add("status", "oneof", statusMessage)
 	}
-	return nil
+	return v.err()
 }
 
 func validateTaskUpdate(task *models.Task) error {
 	if task == nil {
 		return ErrBadRequest
 	}
-	if task.ID == uuid.Nil {
-		return ErrBadRequest
-	}
 	task.Title = strings.TrimSpace(task.Title)
 	task.Status = strings.TrimSpace(task.Status)
 
+	var v ValidationError
+	if task.ID == uuid.Nil {
+		v.add("id", "required", "task id is required")
+	}
 	if task.Title == "" {
-		return ErrBadRequest
+		v.add("title", "required", "title is required")
 	}
-	if task.Status == "" || !isValidStatus(task.Status) {
-		return ErrBadRequest
+	if task.Status == "" {
+		v.add("status", "required", "status is required")
+	} else if !isValidStatus(task.Status) {
+		v.add("status", "oneof", statusMessage)
 	}
-	return nil
+	return v.err()
 }
 
+func validateChecklistItem(item *models.ChecklistItem) error {
+	var v ValidationError
+	if item.Title == "" {
+		v.add("title", "required", "title is required")
+	}
+	return v.err()
+}
+
+const statusMessage = "status must be todo, in_progress or done"
+
 func isValidStatus(s string) bool {
 	switch s {
 	case "todo", "in_progress", "done":
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +33,18 @@ func (f *fakeTaskRepo) Create(ctx context.Context, task *models.Task) error {
 	return nil
 }
 
+func (f *fakeTaskRepo) CreateMany(ctx context.Context, tasks []models.Task) error {
+	for i := range tasks {
+		if tasks[i].ID == uuid.Nil {
+			tasks[i].ID = uuid.New()
+		}
+		if err := f.Create(ctx, &tasks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f *fakeTaskRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
 	t, ok := f.tasks[id]
 	if !ok {
@@ -39,10 +53,12 @@ func (f *fakeTaskRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Task,
 	return &t, nil
 }
 
-func (f *fakeTaskRepo) ListAll(ctx context.Context) ([]models.Task, error) {
-	out := make([]models.Task, 0, len(f.tasks))
+func (f *fakeTaskRepo) ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.Task, error) {
+	out := make([]models.Task, 0)
 	for _, t := range f.tasks {
-		out = append(out, t)
+		if t.ProjectID == projectID {
+			out = append(out, t)
+		}
 	}
 	return out, nil
 }
@@ -111,13 +127,101 @@ func (f *fakeTaskRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-type fakeProjectRepoForTasks struct{}
+func (f *fakeTaskRepo) ListSubtasks(ctx context.Context, parentID uuid.UUID) ([]models.Task, error) {
+	out := make([]models.Task, 0)
+	for _, t := range f.tasks {
+		if t.ParentID != nil && *t.ParentID == parentID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTaskRepo) Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+	for t, ok := f.tasks[id]; ok && t.ParentID != nil; t, ok = f.tasks[*t.ParentID] {
+		ids = append(ids, *t.ParentID)
+	}
+	return ids, nil
+}
+
+func (f *fakeTaskRepo) SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error) {
+	height := 0
+	subtasks, _ := f.ListSubtasks(ctx, id)
+	for _, st := range subtasks {
+		h, _ := f.SubtreeHeight(ctx, st.ID)
+		height = max(height, h+1)
+	}
+	return height, nil
+}
+
+func (f *fakeTaskRepo) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	t := f.tasks[id]
+	t.ParentID = parentID
+	f.tasks[id] = t
+	return nil
+}
+
+// WithTree has no concurrency to guard against, so fn works on the repo itself.
+func (f *fakeTaskRepo) WithTree(ctx context.Context, projectID uuid.UUID, fn func(tree repository.TaskTree) error) error {
+	return fn(f)
+}
+
+type fakeChecklistRepo struct {
+	items map[uuid.UUID]models.ChecklistItem
+}
+
+func newFakeChecklistRepo() *fakeChecklistRepo {
+	return &fakeChecklistRepo{items: make(map[uuid.UUID]models.ChecklistItem)}
+}
+
+func (f *fakeChecklistRepo) List(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error) {
+	out := make([]models.ChecklistItem, 0)
+	for _, item := range f.items {
+		if item.TaskID == taskID {
+			out = append(out, item)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Position < out[j].Position })
+	return out, nil
+}
+
+func (f *fakeChecklistRepo) Create(ctx context.Context, item *models.ChecklistItem) error {
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	item.Position = len(f.items) + 1
+	f.items[item.ID] = *item
+	return nil
+}
+
+func (f *fakeChecklistRepo) Update(ctx context.Context, item *models.ChecklistItem) error {
+	existing, ok := f.items[item.ID]
+	if !ok || existing.TaskID != item.TaskID {
+		return repository.ErrNotFound
+	}
+	item.Position = existing.Position
+	f.items[item.ID] = *item
+	return nil
+}
+
+func (f *fakeChecklistRepo) Delete(ctx context.Context, taskID, id uuid.UUID) error {
+	if existing, ok := f.items[id]; !ok || existing.TaskID != taskID {
+		return repository.ErrNotFound
+	}
+	delete(f.items, id)
+	return nil
+}
+
+type fakeProjectRepoForTasks struct {
+	archivedAt *time.Time
+}
 
 func (f *fakeProjectRepoForTasks) Create(ctx context.Context, project *models.Project) error {
 	return nil
 }
 func (f *fakeProjectRepoForTasks) GetByID(ctx context.Context, id string) (*models.Project, error) {
-	return &models.Project{}, nil
+	return &models.Project{ArchivedAt: f.archivedAt}, nil
 }
 func (f *fakeProjectRepoForTasks) ListByOwner(ctx context.Context, ownerID string) ([]models.Project, error) {
 	return []models.Project{}, nil
@@ -127,9 +231,12 @@ func (f *fakeProjectRepoForTasks) UpdateName(ctx context.Context, id string, nam
 }
 func (f *fakeProjectRepoForTasks) Delete(ctx context.Context, id string) error { return nil }
 
-func (f *fakeProjectRepoForTasks) List(ctx context.Context) ([]models.Project, error) {
+func (f *fakeProjectRepoForTasks) List(ctx context.Context, archived bool) ([]models.Project, error) {
 	return []models.Project{}, nil
 }
+func (f *fakeProjectRepoForTasks) SetArchived(ctx context.Context, id string, archived bool) error {
+	return nil
+}
 
 func ptrUUID(u uuid.UUID) *uuid.UUID { return &u }
 
@@ -147,7 +254,7 @@ func mustCreateTask(t *testing.T, repo *fakeTaskRepo, task models.Task) {
 func TestTaskService_MemberCannotUpdateUnassignedTask(t *testing.T) {
 	taskRepo := newFakeTaskRepo()
 	projectRepo := &fakeProjectRepoForTasks{}
-	svc := NewTaskService(taskRepo, projectRepo)
+	svc := NewTaskService(taskRepo, projectRepo, newFakeChecklistRepo())
 
 	taskID := uuid.New()
 	projectID := uuid.New()
@@ -171,7 +278,7 @@ func TestTaskService_MemberCannotUpdateUnassignedTask(t *testing.T) {
 		Description: "x",
 		Status:      "done",
 		AssigneeID:  nil,
-	})
+	}, false)
 
 	if err != ErrForbidden {
 		t.Fatalf("expected ErrForbidden, got %v", err)
@@ -181,7 +288,7 @@ func TestTaskService_MemberCannotUpdateUnassignedTask(t *testing.T) {
 func TestTaskService_MemberCannotReassignTaskToAnotherUser(t *testing.T) {
 	taskRepo := newFakeTaskRepo()
 	projectRepo := &fakeProjectRepoForTasks{}
-	svc := NewTaskService(taskRepo, projectRepo)
+	svc := NewTaskService(taskRepo, projectRepo, newFakeChecklistRepo())
 
 	taskID := uuid.New()
 	projectID := uuid.New()
@@ -208,7 +315,7 @@ func TestTaskService_MemberCannotReassignTaskToAnotherUser(t *testing.T) {
 		Description: "x",
 		Status:      "in_progress",
 		AssigneeID:  ptrUUID(otherID),
-	})
+	}, false)
 
 	if err != ErrForbidden {
 		t.Fatalf("expected ErrForbidden, got %v", err)
@@ -218,7 +325,7 @@ func TestTaskService_MemberCannotReassignTaskToAnotherUser(t *testing.T) {
 func TestTaskService_AdminCanUpdateAnyTask(t *testing.T) {
 	taskRepo := newFakeTaskRepo()
 	projectRepo := &fakeProjectRepoForTasks{}
-	svc := NewTaskService(taskRepo, projectRepo)
+	svc := NewTaskService(taskRepo, projectRepo, newFakeChecklistRepo())
 
 	taskID := uuid.New()
 	projectID := uuid.New()
@@ -242,7 +349,7 @@ func TestTaskService_AdminCanUpdateAnyTask(t *testing.T) {
 		Description: "x",
 		Status:      "done",
 		AssigneeID:  nil,
-	})
+	}, false)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -252,7 +359,7 @@ func TestTaskService_AdminCanUpdateAnyTask(t *testing.T) {
 func TestTaskService_ListFiltersByStatus(t *testing.T) {
 	taskRepo := newFakeTaskRepo()
 	projectRepo := &fakeProjectRepoForTasks{}
-	svc := NewTaskService(taskRepo, projectRepo)
+	svc := NewTaskService(taskRepo, projectRepo, newFakeChecklistRepo())
 
 	projectID := uuid.New()
 
@@ -297,7 +404,7 @@ func TestTaskService_ListFiltersByStatus(t *testing.T) {
 func TestTaskService_ListRespectsPagination(t *testing.T) {
 	taskRepo := newFakeTaskRepo()
 	projectRepo := &fakeProjectRepoForTasks{}
-	svc := NewTaskService(taskRepo, projectRepo)
+	svc := NewTaskService(taskRepo, projectRepo, newFakeChecklistRepo())
 
 	projectID := uuid.New()
 
@@ -345,3 +452,223 @@ func TestTaskService_ListRespectsPagination(t *testing.T) {
 		t.Fatalf("expected second newest task title t2, got %s", tasks[0].Title)
 	}
 }
+
+func TestTaskService_CreateReportsInvalidFields(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo(), &fakeProjectRepoForTasks{}, newFakeChecklistRepo())
+	admin := models.User{ID: uuid.New().String(), Role: "admin"}
+
+	err := svc.Create(context.Background(), admin, &models.Task{Title: "  ", Status: "later"})
+
+	if !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest, got %v", err)
+	}
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		t.Fatalf("expected *ValidationError, got %T", err)
+	}
+	got := make([]string, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		got = append(got, f.Field+"/"+f.Code)
+	}
+	want := []string{"projectId/required", "title/required", "status/oneof"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestTaskService_ArchivedProjectRejectsTaskWrites(t *testing.T) {
+	taskRepo := newFakeTaskRepo()
+	archivedAt := time.Now()
+	svc := NewTaskService(taskRepo, &fakeProjectRepoForTasks{archivedAt: &archivedAt}, newFakeChecklistRepo())
+
+	projectID := uuid.New()
+	taskID := uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: taskID, ProjectID: projectID, Title: "orig", Status: "todo"})
+
+	admin := models.User{ID: uuid.New().String(), Role: "admin"}
+	ctx := context.Background()
+
+	if err := svc.Create(ctx, admin, &models.Task{ProjectID: projectID, Title: "new"}); err != ErrProjectArchived {
+		t.Fatalf("Create: expected ErrProjectArchived, got %v", err)
+	}
+	if err := svc.Update(ctx, admin, &models.Task{ID: taskID, Title: "updated", Status: "done"}, false); err != ErrProjectArchived {
+		t.Fatalf("Update: expected ErrProjectArchived, got %v", err)
+	}
+	if err := svc.Delete(ctx, admin, taskID); err != ErrProjectArchived {
+		t.Fatalf("Delete: expected ErrProjectArchived, got %v", err)
+	}
+	if _, err := svc.GetByID(ctx, admin, taskID); err != nil {
+		t.Fatalf("GetByID: expected reads to work, got %v", err)
+	}
+}
+
+func TestTaskService_MoveRejectsCyclesAndDeepNesting(t *testing.T) {
+	taskRepo := newFakeTaskRepo()
+	svc := NewTaskService(taskRepo, &fakeProjectRepoForTasks{}, newFakeChecklistRepo())
+
+	projectID := uuid.New()
+	epic, step, subStep, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: epic, ProjectID: projectID, Title: "epic", Status: "todo"})
+	mustCreateTask(t, taskRepo, models.Task{ID: step, ProjectID: projectID, ParentID: ptrUUID(epic), Title: "step", Status: "todo"})
+	mustCreateTask(t, taskRepo, models.Task{ID: subStep, ProjectID: projectID, ParentID: ptrUUID(step), Title: "sub-step", Status: "todo"})
+	mustCreateTask(t, taskRepo, models.Task{ID: other, ProjectID: uuid.New(), Title: "elsewhere", Status: "todo"})
+
+	admin := models.User{ID: uuid.New().String(), Role: "admin"}
+	ctx := context.Background()
+
+	for name, tc := range map[string]struct {
+		id, parent uuid.UUID
+		code       string
+	}{
+		"itself":        {epic, epic, "cycle"},
+		"own subtask":   {epic, subStep, "cycle"},
+		"other project": {step, other, "project"},
+		"missing":       {step, uuid.New(), "exists"},
+		"too deep":      {uuid.Nil, subStep, "depth"},
+	} {
+		var err error
+		if tc.id == uuid.Nil {
+			err = svc.Create(ctx, admin, &models.Task{ProjectID: projectID, ParentID: ptrUUID(tc.parent), Title: "new"})
+		} else {
+			_, err = svc.Move(ctx, admin, tc.id, ptrUUID(tc.parent))
+		}
+		var v *ValidationError
+		if !errors.As(err, &v) || v.Fields[0].Field != "parentId" || v.Fields[0].Code != tc.code {
+			t.Fatalf("%s: expected parentId/%s, got %v", name, tc.code, err)
+		}
+	}
+
+	// moving step with its own subtask under a fresh top-level task fits
+	root := uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: root, ProjectID: projectID, Title: "root", Status: "todo"})
+	moved, err := svc.Move(ctx, admin, step, ptrUUID(root))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if moved.ParentID == nil || *moved.ParentID != root {
+		t.Fatalf("expected parent %s, got %v", root, moved.ParentID)
+	}
+
+	if _, err := svc.Move(ctx, admin, step, nil); err != nil {
+		t.Fatalf("expected to make the task top-level, got %v", err)
+	}
+	if taskRepo.tasks[step].ParentID != nil {
+		t.Fatalf("expected no parent, got %v", taskRepo.tasks[step].ParentID)
+	}
+}
+
+func TestTaskService_UpdateKeepsParent(t *testing.T) {
+	taskRepo := newFakeTaskRepo()
+	svc := NewTaskService(taskRepo, &fakeProjectRepoForTasks{}, newFakeChecklistRepo())
+
+	projectID, parentID, taskID := uuid.New(), uuid.New(), uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: parentID, ProjectID: projectID, Title: "epic", Status: "todo"})
+	mustCreateTask(t, taskRepo, models.Task{ID: taskID, ProjectID: projectID, ParentID: ptrUUID(parentID), Title: "step", Status: "todo"})
+
+	admin := models.User{ID: uuid.New().String(), Role: "admin"}
+	if err := svc.Update(context.Background(), admin, &models.Task{ID: taskID, Title: "renamed", Status: "done"}, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p := taskRepo.tasks[taskID].ParentID; p == nil || *p != parentID {
+		t.Fatalf("expected the parent to be kept, got %v", p)
+	}
+}
+
+func TestTaskService_UpdateKeepsOrClearsDueDate(t *testing.T) {
+	taskRepo := newFakeTaskRepo()
+	svc := NewTaskService(taskRepo, &fakeProjectRepoForTasks{}, newFakeChecklistRepo())
+
+	due := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	projectID, taskID := uuid.New(), uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: taskID, ProjectID: projectID, Title: "t", Status: "todo", DueDate: &due})
+
+	admin := models.User{ID: uuid.New().String(), Role: "admin"}
+	if err := svc.Update(context.Background(), admin, &models.Task{ID: taskID, Title: "renamed", Status: "todo"}, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if d := taskRepo.tasks[taskID].DueDate; d == nil || !d.Equal(due) {
+		t.Fatalf("expected the due date to be kept, got %v", d)
+	}
+
+	if err := svc.Update(context.Background(), admin, &models.Task{ID: taskID, Title: "renamed", Status: "todo"}, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if d := taskRepo.tasks[taskID].DueDate; d != nil {
+		t.Fatalf("expected the due date to be cleared, got %v", d)
+	}
+}
+
+func TestTaskService_GetDetailRollsUpProgress(t *testing.T) {
+	taskRepo := newFakeTaskRepo()
+	checklist := newFakeChecklistRepo()
+	svc := NewTaskService(taskRepo, &fakeProjectRepoForTasks{}, checklist)
+
+	projectID, epic := uuid.New(), uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: epic, ProjectID: projectID, Title: "epic", Status: "in_progress"})
+	for _, status := range []string{"done", "done", "todo"} {
+		mustCreateTask(t, taskRepo, models.Task{ProjectID: projectID, ParentID: ptrUUID(epic), Title: "step", Status: status})
+	}
+
+	admin := models.User{ID: uuid.New().String(), Role: "admin"}
+	ctx := context.Background()
+
+	first, err := svc.AddChecklistItem(ctx, admin, epic, "  write notes ")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if first.Title != "write notes" {
+		t.Fatalf("expected a trimmed title, got %q", first.Title)
+	}
+	if _, err := svc.AddChecklistItem(ctx, admin, epic, "review"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	first.Done = true
+	if err := svc.UpdateChecklistItem(ctx, admin, first); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	detail, err := svc.GetDetail(ctx, admin, epic)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if detail.ID != epic || len(detail.Subtasks) != 3 {
+		t.Fatalf("expected the epic with 3 subtasks, got %+v", detail)
+	}
+	if detail.SubtaskProgress != (models.Progress{Done: 2, Total: 3}) {
+		t.Fatalf("subtask progress = %+v, want 2 of 3", detail.SubtaskProgress)
+	}
+	if detail.ChecklistProgress != (models.Progress{Done: 1, Total: 2}) {
+		t.Fatalf("checklist progress = %+v, want 1 of 2", detail.ChecklistProgress)
+	}
+}
+
+func TestTaskService_ChecklistFollowsTaskPermissions(t *testing.T) {
+	taskRepo := newFakeTaskRepo()
+	svc := NewTaskService(taskRepo, &fakeProjectRepoForTasks{}, newFakeChecklistRepo())
+
+	assignee := uuid.New()
+	taskID, otherID := uuid.New(), uuid.New()
+	mustCreateTask(t, taskRepo, models.Task{ID: taskID, ProjectID: uuid.New(), AssigneeID: ptrUUID(assignee), Title: "mine", Status: "todo"})
+	mustCreateTask(t, taskRepo, models.Task{ID: otherID, ProjectID: uuid.New(), Title: "unassigned", Status: "todo"})
+
+	member := models.User{ID: assignee.String(), Role: "user"}
+	ctx := context.Background()
+
+	if _, err := svc.AddChecklistItem(ctx, member, otherID, "step"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on another task, got %v", err)
+	}
+	if _, err := svc.AddChecklistItem(ctx, member, taskID, " "); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest for a blank title, got %v", err)
+	}
+
+	item, err := svc.AddChecklistItem(ctx, member, taskID, "step")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := svc.DeleteChecklistItem(ctx, member, taskID, uuid.New()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown item, got %v", err)
+	}
+	if err := svc.DeleteChecklistItem(ctx, member, taskID, item.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"task-management-platform/backend/internal/repository"
+)
+
+var tracer = otel.Tracer("task-management-platform/backend/internal/services")
+
+// startSpan starts the span of a service method; the repository and SQL
+// spans it causes become its children.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span with the method's result. Errors that describe a bad or
+// unauthorized request are recorded without failing the span, so error
+// rates in traces reflect faults of the service.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !isClientError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// clientErrors are the errors the handlers report with a 4xx status.
+var clientErrors = []error{
+	ErrBadRequest, ErrForbidden, ErrNotFound, repository.ErrNotFound,
+	ErrInvalidCredentials, ErrEmailAlreadyExists, ErrEmailAndPasswordRequired, ErrInvalidToken,
+	ErrCannotDeleteOwnUser, ErrCannotUpdateOwnRole, ErrUserOwnsProjects, ErrLoginLocked,
+	ErrTwoFactorAlreadyEnabled, ErrTwoFactorNotEnabled, ErrInvalidTwoFactorCode,
+	ErrOIDCState, ErrOIDCEmailNotVerified, ErrOIDCAccountExists, ErrOIDCIdentityLinked, ErrOIDCEmailMismatch,
+	ErrProjectArchived, ErrProjectInTrash, ErrParentTaskInTrash,
+	ErrInvalidImport, ErrInvalidTaskImport, ErrInvalidExportLink,
+}
+
+func isClientError(err error) bool {
+	for _, target := range clientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEndSpan_FailsOnlyOnServiceFaults(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tr := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	for _, err := range []error{nil, ErrForbidden, errors.Join(ErrNotFound, errors.New("no rows")), ErrInvalidCredentials, errors.New("connection reset")} {
+		_, span := tr.Start(context.Background(), "op")
+		endSpan(span, err)
+	}
+
+	want := []codes.Code{codes.Unset, codes.Unset, codes.Unset, codes.Unset, codes.Error}
+	spans := rec.Ended()
+	for i, s := range spans {
+		if s.Status().Code != want[i] {
+			t.Fatalf("span %d status = %v, want %v", i, s.Status().Code, want[i])
+		}
+	}
+	if len(spans[1].Events()) != 1 {
+		t.Fatalf("expected client errors to still be recorded")
+	}
+}
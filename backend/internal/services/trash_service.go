@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"task-management-platform/backend/internal/cache"
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// ErrProjectInTrash rejects restoring a task whose project is deleted; the
+// project has to be restored first.
+var ErrProjectInTrash = errors.New("the task's project is in the trash, restore it first")
+
+// ErrParentTaskInTrash does the same for a subtask whose parent is deleted.
+var ErrParentTaskInTrash = errors.New("the task's parent is in the trash, restore it first")
+
+// Trash is what a caller can restore.
+type Trash struct {
+	Projects []models.Project `json:"projects"`
+	Tasks    []models.Task    `json:"tasks"`
+}
+
+// TrashService lists and restores deleted projects and tasks and purges
+// them once they have been in the trash for the retention period.
+type TrashService struct {
+	trash     repository.TrashRepository
+	projects  repository.ProjectRepository
+	cache     *cache.Loader
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewTrashService(trash repository.TrashRepository, projects repository.ProjectRepository, loader *cache.Loader, retention time.Duration) *TrashService {
+	return &TrashService{trash: trash, projects: projects, cache: loader, retention: retention, now: time.Now}
+}
+
+// List returns the projects the actor owns and the tasks assigned to the
+// actor or in the actor's projects. Admins see everyone's.
+func (s *TrashService) List(ctx context.Context, actor models.User) (_ *Trash, err error) {
+	ctx, span := startSpan(ctx, "TrashService.List")
+	defer func() { endSpan(span, err) }()
+
+	userID := actor.ID
+	if isAdmin(actor.Role) {
+		userID = ""
+	}
+
+	projects, err := s.trash.ListProjects(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.trash.ListTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Trash{Projects: projects, Tasks: tasks}, nil
+}
+
+// RestoreProject brings back a deleted project and the tasks deleted with
+// it. The rules are those of deleting it.
+func (s *TrashService) RestoreProject(ctx context.Context, actor models.User, id string) (_ *models.Project, err error) {
+	ctx, span := startSpan(ctx, "TrashService.RestoreProject", attribute.String("project.id", id))
+	defer func() { endSpan(span, err) }()
+
+	p, err := s.trash.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeProject(actor, p) {
+		return nil, ErrForbidden
+	}
+
+	if err := s.trash.RestoreProject(ctx, id); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+
+	p.DeletedAt = nil
+	return p, nil
+}
+
+// GetTask returns a deleted task the actor may restore.
+func (s *TrashService) GetTask(ctx context.Context, actor models.User, id uuid.UUID) (_ *models.Task, err error) {
+	ctx, span := startSpan(ctx, "TrashService.GetTask", attribute.String("task.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	task, err := s.trash.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.projects.GetByID(ctx, task.ProjectID.String())
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return nil, ErrProjectInTrash
+	case err != nil:
+		return nil, err
+	}
+
+	if !canSeeProject(actor, project) && (task.AssigneeID == nil || task.AssigneeID.String() != actor.ID) {
+		return nil, ErrForbidden
+	}
+	if project.ArchivedAt != nil {
+		return nil, ErrProjectArchived
+	}
+	if task.ParentID != nil {
+		if _, err := s.trash.GetTask(ctx, *task.ParentID); err == nil {
+			return nil, ErrParentTaskInTrash
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return task, nil
+}
+
+// RestoreTask brings back a task deleted on its own, with the subtasks
+// deleted along with it. Admins, the assignee and the project's owner may
+// restore it, once its project and parent task are live and the project is
+// not archived.
+func (s *TrashService) RestoreTask(ctx context.Context, actor models.User, id uuid.UUID) (_ *models.Task, err error) {
+	ctx, span := startSpan(ctx, "TrashService.RestoreTask", attribute.String("task.id", id.String()))
+	defer func() { endSpan(span, err) }()
+
+	task, err := s.GetTask(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.trash.RestoreTask(ctx, id); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+
+	task.DeletedAt = nil
+	return task, nil
+}
+
+// Purge permanently deletes whatever has been in the trash for longer than
+// the retention period.
+func (s *TrashService) Purge(ctx context.Context) (_ repository.TrashPurge, err error) {
+	ctx, span := startSpan(ctx, "TrashService.Purge")
+	defer func() { endSpan(span, err) }()
+
+	return s.trash.Purge(ctx, s.now().Add(-s.retention))
+}
+
+// RunRetention purges the trash now and then every interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+func (s *TrashService) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.Purge(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			slog.Error("purge trash", "error", err)
+		case purged.Projects > 0 || purged.Tasks > 0:
+			slog.Info("trash purged", "projects", purged.Projects, "tasks", purged.Tasks)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// invalidate drops cached reads that a restore makes stale.
+func (s *TrashService) invalidate(ctx context.Context) {
+	if s.cache != nil {
+		s.cache.InvalidatePrefix(ctx, repository.CachePrefixProjects, repository.CachePrefixTasks)
+	}
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
+)
+
+// fakeTrashRepo keeps deleted items apart from the live projects of
+// fakeProjectRepo; restoring moves a project back.
+type fakeTrashRepo struct {
+	live     *fakeProjectRepo
+	projects map[string]models.Project
+	tasks    map[uuid.UUID]models.Task
+	listedBy []string
+	cutoff   time.Time
+}
+
+func (f *fakeTrashRepo) ListProjects(ctx context.Context, ownerID string) ([]models.Project, error) {
+	f.listedBy = append(f.listedBy, ownerID)
+	out := make([]models.Project, 0)
+	for _, p := range f.projects {
+		if ownerID == "" || p.OwnerID == ownerID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTrashRepo) ListTasks(ctx context.Context, userID string) ([]models.Task, error) {
+	out := make([]models.Task, 0)
+	for _, t := range f.tasks {
+		if userID == "" || (t.AssigneeID != nil && t.AssigneeID.String() == userID) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTrashRepo) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	p, ok := f.projects[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (f *fakeTrashRepo) GetTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	t, ok := f.tasks[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (f *fakeTrashRepo) RestoreProject(ctx context.Context, id string) error {
+	p, ok := f.projects[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.projects, id)
+	p.DeletedAt = nil
+	return f.live.Create(ctx, &p)
+}
+
+func (f *fakeTrashRepo) RestoreTask(ctx context.Context, id uuid.UUID) error {
+	if _, ok := f.tasks[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.tasks, id)
+	return nil
+}
+
+func (f *fakeTrashRepo) Purge(ctx context.Context, cutoff time.Time) (repository.TrashPurge, error) {
+	f.cutoff = cutoff
+	return repository.TrashPurge{}, nil
+}
+
+func newTrashFixture(t *testing.T) (*TrashService, *fakeTrashRepo) {
+	t.Helper()
+
+	deletedAt := time.Now().Add(-time.Hour)
+	repo := &fakeTrashRepo{
+		live: newFakeProjectRepo(),
+		projects: map[string]models.Project{
+			"p1": {ID: "p1", OwnerID: "u1", Name: "Launch", DeletedAt: &deletedAt},
+		},
+		tasks: map[uuid.UUID]models.Task{},
+	}
+	return NewTrashService(repo, repo.live, nil, 30*24*time.Hour), repo
+}
+
+func TestTrashService_RestoreProject(t *testing.T) {
+	svc, repo := newTrashFixture(t)
+	ctx := context.Background()
+
+	if _, err := svc.RestoreProject(ctx, models.User{ID: "u2", Role: "user"}, "p1"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another member, got %v", err)
+	}
+
+	p, err := svc.RestoreProject(ctx, models.User{ID: "u1", Role: "user"}, "p1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p.DeletedAt != nil {
+		t.Fatalf("expected the restored project without deletedAt, got %v", p.DeletedAt)
+	}
+	if _, err := repo.live.GetByID(ctx, "p1"); err != nil {
+		t.Fatalf("expected the project to be live again, got %v", err)
+	}
+
+	if _, err := svc.RestoreProject(ctx, models.User{ID: "u1", Role: "user"}, "p1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a live project, got %v", err)
+	}
+}
+
+func TestTrashService_RestoreTaskNeedsLiveProject(t *testing.T) {
+	svc, repo := newTrashFixture(t)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	assignee := uuid.New()
+	deletedAt := time.Now()
+	task := models.Task{ID: uuid.New(), ProjectID: projectID, AssigneeID: &assignee, DeletedAt: &deletedAt}
+	repo.tasks[task.ID] = task
+	repo.projects[projectID.String()] = models.Project{ID: projectID.String(), OwnerID: "owner", DeletedAt: &deletedAt}
+
+	member := models.User{ID: assignee.String(), Role: "user"}
+	if _, err := svc.RestoreTask(ctx, member, task.ID); !errors.Is(err, ErrProjectInTrash) {
+		t.Fatalf("expected ErrProjectInTrash, got %v", err)
+	}
+
+	if _, err := svc.RestoreProject(ctx, models.User{ID: "owner", Role: "user"}, projectID.String()); err != nil {
+		t.Fatalf("expected no error restoring the project, got %v", err)
+	}
+
+	if _, err := svc.RestoreTask(ctx, models.User{ID: "stranger", Role: "user"}, task.ID); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a stranger, got %v", err)
+	}
+
+	restored, err := svc.RestoreTask(ctx, member, task.ID)
+	if err != nil {
+		t.Fatalf("expected the assignee to restore the task, got %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Fatalf("expected the restored task without deletedAt, got %v", restored.DeletedAt)
+	}
+	if _, ok := repo.tasks[task.ID]; ok {
+		t.Fatalf("expected the task to leave the trash")
+	}
+}
+
+func TestTrashService_ListIsScopedToTheCaller(t *testing.T) {
+	svc, repo := newTrashFixture(t)
+	ctx := context.Background()
+
+	trash, err := svc.List(ctx, models.User{ID: "u2", Role: "user"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(trash.Projects) != 0 {
+		t.Fatalf("expected no projects for another member, got %+v", trash.Projects)
+	}
+
+	trash, err = svc.List(ctx, models.User{ID: "admin", Role: "admin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(trash.Projects) != 1 {
+		t.Fatalf("expected admins to see every deleted project, got %+v", trash.Projects)
+	}
+	if repo.listedBy[1] != "" {
+		t.Fatalf("expected the admin listing to be unfiltered, got owner %q", repo.listedBy[1])
+	}
+}
+
+func TestTrashService_PurgeUsesRetention(t *testing.T) {
+	svc, repo := newTrashFixture(t)
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.Purge(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := now.Add(-30 * 24 * time.Hour); !repo.cutoff.Equal(want) {
+		t.Fatalf("expected cutoff %v, got %v", want, repo.cutoff)
+	}
+}
+
+func TestTrashService_RestoreSubtaskNeedsLiveParent(t *testing.T) {
+	svc, repo := newTrashFixture(t)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	_ = repo.live.Create(ctx, &models.Project{ID: projectID.String(), OwnerID: "owner"})
+
+	deletedAt := time.Now()
+	parent := models.Task{ID: uuid.New(), ProjectID: projectID, DeletedAt: &deletedAt}
+	child := models.Task{ID: uuid.New(), ProjectID: projectID, ParentID: &parent.ID, DeletedAt: &deletedAt}
+	repo.tasks[parent.ID] = parent
+	repo.tasks[child.ID] = child
+
+	owner := models.User{ID: "owner", Role: "user"}
+	if _, err := svc.RestoreTask(ctx, owner, child.ID); !errors.Is(err, ErrParentTaskInTrash) {
+		t.Fatalf("expected ErrParentTaskInTrash, got %v", err)
+	}
+	if _, err := svc.RestoreTask(ctx, owner, parent.ID); err != nil {
+		t.Fatalf("expected no error restoring the parent, got %v", err)
+	}
+	if _, err := svc.RestoreTask(ctx, owner, child.ID); err != nil {
+		t.Fatalf("expected the subtask to restore once its parent is live, got %v", err)
+	}
+}
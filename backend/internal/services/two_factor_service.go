@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/crypto/bcrypt"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/pkg/seal"
+	"task-management-platform/backend/pkg/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from one step either side to absorb clock drift.
+	totpSkew = 1
+)
+
+type TwoFactorRepo interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	SetTOTPSecret(ctx context.Context, id string, secret string) error
+	EnableTOTP(ctx context.Context, id string, step int64, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, id string) error
+	AdvanceTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+	// ListPlainTOTPSecrets returns user id -> secret for secrets stored
+	// before sealing was introduced.
+	ListPlainTOTPSecrets(ctx context.Context) (map[string]string, error)
+	// ReplaceTOTPSecret swaps old for new, doing nothing if the stored value
+	// is no longer old.
+	ReplaceTOTPSecret(ctx context.Context, id, old, new string) error
+}
+
+// TwoFactorSetup is handed to the user once so they can add the account to an
+// authenticator app.
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// TwoFactorService manages RFC 6238 TOTP enrollment and verification.
+// Secrets are sealed with box before they are stored.
+type TwoFactorService struct {
+	repo   TwoFactorRepo
+	issuer string
+	box    *seal.Box
+	now    func() time.Time
+}
+
+func NewTwoFactorService(repo TwoFactorRepo, issuer string, box *seal.Box) *TwoFactorService {
+	return &TwoFactorService{repo: repo, issuer: issuer, box: box, now: time.Now}
+}
+
+// SealStoredSecrets seals secrets that were stored in plaintext. It runs at
+// startup and is safe to run from several instances at once.
+func (s *TwoFactorService) SealStoredSecrets(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.SealStoredSecrets")
+	defer func() { endSpan(span, err) }()
+
+	plain, err := s.repo.ListPlainTOTPSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	for id, secret := range plain {
+		sealed, err := s.box.Seal(secret)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.ReplaceTOTPSecret(ctx, id, secret, sealed); err != nil {
+			return err
+		}
+	}
+	if len(plain) > 0 {
+		slog.Info("sealed stored totp secrets", "count", len(plain))
+	}
+	return nil
+}
+
+// secret returns the user's plaintext secret. Values stored before sealing
+// was introduced are used as they are until SealStoredSecrets rewrites them.
+func (s *TwoFactorService) secret(user *models.User) (string, error) {
+	if !seal.IsSealed(*user.TOTPSecret) {
+		return *user.TOTPSecret, nil
+	}
+	return s.box.Open(*user.TOTPSecret)
+}
+
+// BeginEnrollment generates a new secret for the user. Two-factor is not
+// enforced until ConfirmEnrollment succeeds, so an abandoned setup does not
+// lock anyone out.
+func (s *TwoFactorService) BeginEnrollment(ctx context.Context, userID string) (_ *TwoFactorSetup, err error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.BeginEnrollment", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetTOTPSecret(ctx, userID, sealed); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables two-factor once the user enters a valid code for
+// the pending secret, and returns the plaintext recovery codes. They are only
+// stored hashed, so this is the one time they can be shown.
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID, code string) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.ConfirmEnrollment", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	secret, err := s.secret(user)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(secret, code, s.now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor off. It asks for the password as well as a code so
+// a hijacked session alone cannot remove the second factor.
+func (s *TwoFactorService) Disable(ctx context.Context, userID, password, code string) (err error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.Disable", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after a valid code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.RegenerateRecoveryCodes", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if err := s.Verify(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify accepts either a current TOTP code or an unused recovery code. TOTP
+// codes are single use: a code for a step that was already accepted is
+// rejected even while it is still inside the validity window.
+func (s *TwoFactorService) Verify(ctx context.Context, user *models.User, code string) (err error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.Verify", attribute.String("user.id", user.ID))
+	defer func() { endSpan(span, err) }()
+
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	secret, err := s.secret(user)
+	if err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+
+	if step, ok := totp.Validate(secret, code, s.now(), totpSkew); ok {
+		fresh, err := s.repo.AdvanceTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns codes formatted as "xxxxx-xxxxx" (50 bits each)
+// and their hashes. A fast hash is enough at that entropy, and lets a code be
+// looked up directly instead of bcrypt-comparing against every row.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(buf))[:10]
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/pkg/jwt/jwttest"
+	"task-management-platform/backend/pkg/seal"
+	"task-management-platform/backend/pkg/totp"
+)
+
+// fakeTwoFactorRepo extends fakeUserRepo with the TOTP columns.
+type fakeTwoFactorRepo struct {
+	*fakeUserRepo
+	recovery map[string]map[string]bool // user id -> hash -> used
+}
+
+func newFakeTwoFactorRepo() *fakeTwoFactorRepo {
+	return &fakeTwoFactorRepo{
+		fakeUserRepo: newFakeUserRepo(),
+		recovery:     map[string]map[string]bool{},
+	}
+}
+
+func (r *fakeTwoFactorRepo) SetTOTPSecret(ctx context.Context, id string, secret string) error {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.TOTPSecret = &secret
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) EnableTOTP(ctx context.Context, id string, step int64, hashes []string) error {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.TOTPEnabled = true
+	u.TOTPLastStep = step
+	return r.ReplaceRecoveryCodes(ctx, id, hashes)
+}
+
+func (r *fakeTwoFactorRepo) DisableTOTP(ctx context.Context, id string) error {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.TOTPEnabled = false
+	u.TOTPSecret = nil
+	u.TOTPLastStep = 0
+	delete(r.recovery, id)
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) AdvanceTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if step <= u.TOTPLastStep {
+		return false, nil
+	}
+	u.TOTPLastStep = step
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	codes := map[string]bool{}
+	for _, h := range hashes {
+		codes[h] = false
+	}
+	r.recovery[userID] = codes
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID string, hash string) (bool, error) {
+	used, ok := r.recovery[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recovery[userID][hash] = true
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) ListPlainTOTPSecrets(ctx context.Context) (map[string]string, error) {
+	secrets := map[string]string{}
+	for _, u := range r.byID {
+		if u.TOTPSecret != nil && !seal.IsSealed(*u.TOTPSecret) {
+			secrets[u.ID] = *u.TOTPSecret
+		}
+	}
+	return secrets, nil
+}
+
+func (r *fakeTwoFactorRepo) ReplaceTOTPSecret(ctx context.Context, id, old, new string) error {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u.TOTPSecret != nil && *u.TOTPSecret == old {
+		u.TOTPSecret = &new
+	}
+	return nil
+}
+
+func newTestBox(t *testing.T) *seal.Box {
+	t.Helper()
+	box, err := seal.New("test-totp-key-0123456789abcdef012345")
+	if err != nil {
+		t.Fatalf("seal.New() error = %v", err)
+	}
+	return box
+}
+
+func newTwoFactorFixture(t *testing.T) (*fakeTwoFactorRepo, *TwoFactorService, *time.Time) {
+	t.Helper()
+
+	repo := newFakeTwoFactorRepo()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("1234"), bcrypt.MinCost)
+	_ = repo.Create(context.Background(), &models.User{
+		ID:           "u1",
+		Email:        "test@test.com",
+		PasswordHash: string(hash),
+		Role:         "admin",
+		CreatedAt:    time.Now().UTC(),
+	})
+
+	now := time.Unix(1_700_000_000, 0)
+	svc := NewTwoFactorService(repo, "Task Manager", newTestBox(t))
+	svc.now = func() time.Time { return now }
+	return repo, svc, &now
+}
+
+func enroll(t *testing.T, svc *TwoFactorService, now time.Time) (secret string, recovery []string) {
+	t.Helper()
+
+	setup, err := svc.BeginEnrollment(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("BeginEnrollment() error = %v", err)
+	}
+	code, _ := totp.CodeAt(setup.Secret, totp.Step(now))
+
+	recovery, err = svc.ConfirmEnrollment(context.Background(), "u1", code)
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment() error = %v", err)
+	}
+	return setup.Secret, recovery
+}
+
+func TestTwoFactorService_ConfirmRequiresValidCode(t *testing.T) {
+	repo, svc, _ := newTwoFactorFixture(t)
+
+	if _, err := svc.BeginEnrollment(context.Background(), "u1"); err != nil {
+		t.Fatalf("BeginEnrollment() error = %v", err)
+	}
+	if _, err := svc.ConfirmEnrollment(context.Background(), "u1", "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("ConfirmEnrollment() err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	u, _ := repo.GetByID(context.Background(), "u1")
+	if u.TOTPEnabled {
+		t.Fatalf("two-factor enabled after a wrong code")
+	}
+}
+
+func TestTwoFactorService_RejectsReplayedCode(t *testing.T) {
+	repo, svc, now := newTwoFactorFixture(t)
+	secret, recovery := enroll(t, svc, *now)
+
+	if len(recovery) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recovery), recoveryCodeCount)
+	}
+
+	u, _ := repo.GetByID(context.Background(), "u1")
+
+	// the enrollment code's step is already spent
+	code, _ := totp.CodeAt(secret, totp.Step(*now))
+	if err := svc.Verify(context.Background(), u, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("Verify() replay err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	*now = now.Add(totp.Period)
+	code, _ = totp.CodeAt(secret, totp.Step(*now))
+	if err := svc.Verify(context.Background(), u, code); err != nil {
+		t.Fatalf("Verify() next step error = %v", err)
+	}
+}
+
+func TestTwoFactorService_RecoveryCodesAreSingleUse(t *testing.T) {
+	repo, svc, now := newTwoFactorFixture(t)
+	_, recovery := enroll(t, svc, *now)
+
+	u, _ := repo.GetByID(context.Background(), "u1")
+
+	if err := svc.Verify(context.Background(), u, recovery[0]); err != nil {
+		t.Fatalf("Verify() recovery code error = %v", err)
+	}
+	if err := svc.Verify(context.Background(), u, recovery[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("Verify() reused recovery code err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+}
+
+func TestTwoFactorService_StoresSecretSealed(t *testing.T) {
+	repo, svc, now := newTwoFactorFixture(t)
+	secret, _ := enroll(t, svc, *now)
+
+	u, _ := repo.GetByID(context.Background(), "u1")
+	if u.TOTPSecret == nil || *u.TOTPSecret == secret || !seal.IsSealed(*u.TOTPSecret) {
+		t.Fatalf("stored secret = %v, want it sealed", u.TOTPSecret)
+	}
+}
+
+func TestTwoFactorService_SealStoredSecrets(t *testing.T) {
+	repo, svc, now := newTwoFactorFixture(t)
+
+	// a secret enrolled before sealing was introduced
+	secret := "JBSWY3DPEHPK3PXP"
+	u, _ := repo.GetByID(context.Background(), "u1")
+	u.TOTPSecret = &secret
+	u.TOTPEnabled = true
+
+	code, _ := totp.CodeAt(secret, totp.Step(*now))
+	if err := svc.Verify(context.Background(), u, code); err != nil {
+		t.Fatalf("Verify() with a plaintext secret error = %v", err)
+	}
+
+	if err := svc.SealStoredSecrets(context.Background()); err != nil {
+		t.Fatalf("SealStoredSecrets() error = %v", err)
+	}
+	if !seal.IsSealed(*u.TOTPSecret) {
+		t.Fatalf("stored secret was not sealed")
+	}
+
+	*now = now.Add(totp.Period)
+	code, _ = totp.CodeAt(secret, totp.Step(*now))
+	if err := svc.Verify(context.Background(), u, code); err != nil {
+		t.Fatalf("Verify() after sealing error = %v", err)
+	}
+}
+
+func TestTwoFactorService_DisableRequiresPassword(t *testing.T) {
+	repo, svc, now := newTwoFactorFixture(t)
+	_, recovery := enroll(t, svc, *now)
+
+	if err := svc.Disable(context.Background(), "u1", "WRONG", recovery[0]); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Disable() err = %v, want ErrInvalidCredentials", err)
+	}
+	if err := svc.Disable(context.Background(), "u1", "1234", recovery[0]); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+
+	u, _ := repo.GetByID(context.Background(), "u1")
+	if u.TOTPEnabled || u.TOTPSecret != nil {
+		t.Fatalf("two-factor still configured after Disable")
+	}
+}
+
+func TestAuthService_Login_TwoStepWhenEnabled(t *testing.T) {
+	jwttest.Configure()
+
+	repo, twoFactor, now := newTwoFactorFixture(t)
+	secret, _ := enroll(t, twoFactor, *now)
+	svc := NewAuthService(repo, nil, nil, twoFactor)
+
+	result, err := svc.Login(context.Background(), "test@test.com", "1234", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if result.ChallengeToken == "" {
+		t.Fatalf("Login() returned no challenge token for an account with two-factor")
+	}
+
+	if _, err := svc.CompleteTwoFactorLogin(context.Background(), "garbage", "123456", "10.0.0.1"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("CompleteTwoFactorLogin() bad challenge err = %v, want ErrInvalidToken", err)
+	}
+
+	*now = now.Add(totp.Period)
+	code, _ := totp.CodeAt(secret, totp.Step(*now))
+
+	user, err := svc.CompleteTwoFactorLogin(context.Background(), result.ChallengeToken, code, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("CompleteTwoFactorLogin() error = %v", err)
+	}
+	if user.ID != "u1" {
+		t.Fatalf("user.ID = %q, want %q", user.ID, "u1")
+	}
+}
@@ -2,40 +2,171 @@ package services
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/crypto/bcrypt"
+
+	"task-management-platform/backend/internal/cache"
 	"task-management-platform/backend/internal/models"
 	"task-management-platform/backend/internal/repository"
 )
 
+// UserAdminRepo is the user storage UserService needs.
+type UserAdminRepo interface {
+	List(ctx context.Context) ([]models.User, error)
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	// Delete hands the user's projects to newOwnerID, or fails with
+	// repository.ErrOwnsProjects when it is empty and there are some.
+	Delete(ctx context.Context, id string, newOwnerID string) error
+}
+
 type UserService struct {
-	repo *repository.UserRepository
+	repo  UserAdminRepo
+	cache *cache.Loader
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo UserAdminRepo, loader *cache.Loader) *UserService {
+	return &UserService{repo: repo, cache: loader}
 }
 
-func (s *UserService) List(ctx context.Context) ([]models.User, error) {
+func (s *UserService) List(ctx context.Context) (_ []models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.List")
+	defer func() { endSpan(span, err) }()
+
 	return s.repo.List(ctx)
 }
 
-func (s *UserService) GetByID(ctx context.Context, id string) (*models.User, error) {
+func (s *UserService) GetByID(ctx context.Context, id string) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetByID", attribute.String("user.id", id))
+	defer func() { endSpan(span, err) }()
+
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *UserService) Create(ctx context.Context, user *models.User) error {
-	return s.repo.Create(ctx, user)
+func (s *UserService) Create(ctx context.Context, user *models.User) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Create")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return ErrEmailAlreadyExists
+		}
+		return err
+	}
+	s.cache.Invalidate(ctx, repository.CacheKeyMinimalUsers)
+	return nil
+}
+
+// CreateServiceAccount provisions a non-human user for automation. It gets an
+// unusable random password, so it can only authenticate with access tokens.
+func (s *UserService) CreateServiceAccount(ctx context.Context, email, role string) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.CreateServiceAccount")
+	defer func() { endSpan(span, err) }()
+
+	password, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:           uuid.NewString(),
+		Email:        normalizeEmail(email),
+		PasswordHash: string(hash),
+		Role:         role,
+		Kind:         models.UserKindService,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	if err := s.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-func (s *UserService) Update(ctx context.Context, actorID string, user *models.User) error {
+func (s *UserService) Update(ctx context.Context, actorID string, user *models.User) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Update", attribute.String("user.id", user.ID))
+	defer func() { endSpan(span, err) }()
+
 	if actorID == user.ID {
 		return ErrCannotUpdateOwnRole
 	}
-	return s.repo.Update(ctx, user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return ErrEmailAlreadyExists
+		}
+		return err
+	}
+	s.cache.Invalidate(ctx, repository.CacheKeyMinimalUsers)
+	return nil
 }
 
-func (s *UserService) Delete(ctx context.Context, actorID, targetID string) error {
+// SetRole changes a user's role outside of a request, e.g. from the admin CLI,
+// where there is no acting user to protect from demoting themselves.
+func (s *UserService) SetRole(ctx context.Context, id, role string) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.SetRole", attribute.String("user.id", id))
+	defer func() { endSpan(span, err) }()
+
+	if role != "user" && role != "admin" {
+		return nil, ErrBadRequest
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, repository.CacheKeyMinimalUsers)
+	return user, nil
+}
+
+// Delete removes a user. A user who owns projects can only be deleted by
+// naming transferTo, who takes over all of them, including those in the
+// trash; projects are never deleted along with their owner.
+func (s *UserService) Delete(ctx context.Context, actorID, targetID, transferTo string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Delete", attribute.String("user.id", targetID))
+	defer func() { endSpan(span, err) }()
+
 	if actorID == targetID {
 		return ErrCannotDeleteOwnUser
 	}
-	return s.repo.Delete(ctx, targetID)
+	if transferTo != "" {
+		var v ValidationError
+		if _, err := uuid.Parse(transferTo); err != nil {
+			v.add("transferProjectsTo", "uuid", "must be a UUID")
+		} else if transferTo == targetID {
+			v.add("transferProjectsTo", "different", "must be another user")
+		} else if _, err := s.repo.GetByID(ctx, transferTo); errors.Is(err, repository.ErrNotFound) {
+			v.add("transferProjectsTo", "exists", "user not found")
+		} else if err != nil {
+			return err
+		}
+		if err := v.err(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Delete(ctx, targetID, transferTo); err != nil {
+		if errors.Is(err, repository.ErrOwnsProjects) {
+			return ErrUserOwnsProjects
+		}
+		return err
+	}
+	s.cache.InvalidatePrefix(ctx,
+		repository.CachePrefixUsers,
+		repository.CachePrefixProjects,
+		repository.CachePrefixTasks,
+	)
+	return nil
 }
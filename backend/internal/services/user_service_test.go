@@ -2,14 +2,154 @@ package services
 
 import (
 	"context"
+	"errors"
 	"testing"
+
+	"github.com/google/uuid"
+
+	"task-management-platform/backend/internal/models"
+	"task-management-platform/backend/internal/repository"
 )
 
+// fakeUserAdminRepo extends fakeUserRepo with the calls UserService makes and
+// the unique email constraint.
+type fakeUserAdminRepo struct {
+	*fakeUserRepo
+	owned map[string]int // owner id -> projects
+}
+
+func newFakeUserAdminRepo() *fakeUserAdminRepo {
+	return &fakeUserAdminRepo{fakeUserRepo: newFakeUserRepo(), owned: map[string]int{}}
+}
+
+func (r *fakeUserAdminRepo) Create(ctx context.Context, user *models.User) error {
+	if _, ok := r.byEmail[user.Email]; ok {
+		return repository.ErrEmailTaken
+	}
+	return r.fakeUserRepo.Create(ctx, user)
+}
+
+func (r *fakeUserAdminRepo) List(ctx context.Context) ([]models.User, error) {
+	users := make([]models.User, 0, len(r.byID))
+	for _, u := range r.byID {
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+func (r *fakeUserAdminRepo) Update(ctx context.Context, user *models.User) error {
+	if _, ok := r.byID[user.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	if other, ok := r.byEmail[user.Email]; ok && other.ID != user.ID {
+		return repository.ErrEmailTaken
+	}
+	r.byID[user.ID] = user
+	r.byEmail[user.Email] = user
+	return nil
+}
+
+func (r *fakeUserAdminRepo) Delete(ctx context.Context, id string, newOwnerID string) error {
+	u, ok := r.byID[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.owned[id] > 0 {
+		if newOwnerID == "" {
+			return repository.ErrOwnsProjects
+		}
+		r.owned[newOwnerID] += r.owned[id]
+		delete(r.owned, id)
+	}
+	delete(r.byID, id)
+	delete(r.byEmail, u.Email)
+	return nil
+}
+
 func TestUserService_Delete_CannotDeleteSelf(t *testing.T) {
 	svc := &UserService{repo: nil}
 
-	err := svc.Delete(context.Background(), "same-id", "same-id")
+	err := svc.Delete(context.Background(), "same-id", "same-id", "")
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestUserService_Create_DuplicateEmailConflicts(t *testing.T) {
+	repo := newFakeUserAdminRepo()
+	svc := NewUserService(repo, nil)
+
+	if err := svc.Create(context.Background(), &models.User{ID: "u1", Email: "test@test.com", Role: "user"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	err := svc.Create(context.Background(), &models.User{ID: "u2", Email: "test@test.com", Role: "user"})
+	if !errors.Is(err, ErrEmailAlreadyExists) {
+		t.Fatalf("Create() duplicate err = %v, want ErrEmailAlreadyExists", err)
+	}
+
+	_, err = svc.CreateServiceAccount(context.Background(), "Test@Test.com", "user")
+	if !errors.Is(err, ErrEmailAlreadyExists) {
+		t.Fatalf("CreateServiceAccount() duplicate err = %v, want ErrEmailAlreadyExists", err)
+	}
+
+	_ = svc.Create(context.Background(), &models.User{ID: "u3", Email: "other@test.com", Role: "user"})
+	err = svc.Update(context.Background(), "admin", &models.User{ID: "u3", Email: "test@test.com", Role: "user"})
+	if !errors.Is(err, ErrEmailAlreadyExists) {
+		t.Fatalf("Update() to a taken email err = %v, want ErrEmailAlreadyExists", err)
+	}
+}
+
+func TestUserService_Delete_RejectsInvalidTransferTarget(t *testing.T) {
+	ownerID, adminID := uuid.NewString(), uuid.NewString()
+
+	cases := []struct {
+		name       string
+		transferTo string
+		wantCode   string
+	}{
+		{"invalid uuid", "not-a-uuid", "uuid"},
+		{"self transfer", ownerID, "different"},
+		{"unknown target", uuid.NewString(), "exists"},
+	}
+	for _, tc := range cases {
+		repo := newFakeUserAdminRepo()
+		_ = repo.fakeUserRepo.Create(context.Background(), &models.User{ID: ownerID, Email: "owner@test.com", Role: "user"})
+		repo.owned[ownerID] = 2
+		svc := NewUserService(repo, nil)
+
+		err := svc.Delete(context.Background(), adminID, ownerID, tc.transferTo)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || len(verr.Fields) != 1 {
+			t.Fatalf("%s: Delete() error = %v, want one field error", tc.name, err)
+		}
+		if f := verr.Fields[0]; f.Field != "transferProjectsTo" || f.Code != tc.wantCode {
+			t.Fatalf("%s: field error = %+v, want transferProjectsTo/%s", tc.name, f, tc.wantCode)
+		}
+		if _, ok := repo.byID[ownerID]; !ok || repo.owned[ownerID] != 2 {
+			t.Fatalf("%s: rejected delete changed the repo", tc.name)
+		}
+	}
+}
+
+func TestUserService_Delete_OwnerNeedsTransferTarget(t *testing.T) {
+	ownerID, heirID, adminID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	repo := newFakeUserAdminRepo()
+	_ = repo.fakeUserRepo.Create(context.Background(), &models.User{ID: ownerID, Email: "owner@test.com", Role: "user"})
+	_ = repo.fakeUserRepo.Create(context.Background(), &models.User{ID: heirID, Email: "heir@test.com", Role: "user"})
+	repo.owned[ownerID] = 2
+	svc := NewUserService(repo, nil)
+
+	if err := svc.Delete(context.Background(), adminID, ownerID, ""); !errors.Is(err, ErrUserOwnsProjects) {
+		t.Fatalf("Delete() without a target error = %v, want ErrUserOwnsProjects", err)
+	}
+	if _, ok := repo.byID[ownerID]; !ok {
+		t.Fatalf("owner deleted without a transfer target")
+	}
+
+	if err := svc.Delete(context.Background(), adminID, ownerID, heirID); err != nil {
+		t.Fatalf("Delete() with a target error = %v", err)
+	}
+	if _, ok := repo.byID[ownerID]; ok || repo.owned[heirID] != 2 {
+		t.Fatalf("projects not transferred: owner present %v, heir owns %d", ok, repo.owned[heirID])
+	}
+}
@@ -0,0 +1,106 @@
+// Package tracing sets up OpenTelemetry: the global tracer provider and
+// exporter, W3C trace context propagation and traced HTTP clients.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters accepted by Setup.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+type Config struct {
+	// Exporter is "none", "otlp" or "stdout". The OTLP exporter is
+	// configured with the standard OTEL_EXPORTER_OTLP_* variables.
+	Exporter    string
+	ServiceName string
+	// SampleRatio is the share of new traces recorded, 0 to 1. Requests
+	// arriving with a sampled traceparent are always recorded.
+	SampleRatio float64
+	// Stdout receives spans from the stdout exporter.
+	Stdout io.Writer
+}
+
+// Setup installs the global tracer provider and propagator and returns a
+// function that flushes pending spans. Trace context is propagated even with
+// the "none" exporter, so this service does not break traces that pass
+// through it.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(cfg.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// HTTPClient returns a client whose requests are traced and carry the
+// caller's trace context in a traceparent header. Outgoing calls should use
+// it, or wrap their transport with Transport, so traces continue into the
+// services they call.
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: Transport(http.DefaultTransport)}
+}
+
+// Transport wraps rt with tracing and trace context propagation.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(rt)
+}
+
+// Middleware starts a server span per request, continuing the trace of an
+// incoming traceparent header. Spans are named after the route template.
+// Requests to skipRoutes are not traced, for probes and for routes whose
+// path holds a secret.
+func Middleware(serviceName string, skipRoutes ...string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		return !slices.Contains(skipRoutes, c.FullPath())
+	}))
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	if _, err := Setup(context.Background(), Config{Exporter: ExporterNone}); err != nil {
+		t.Fatalf("Setup error = %v", err)
+	}
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func TestMiddleware_ContinuesIncomingTraceIntoOutgoingCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := setupRecorder(t)
+
+	var outgoing string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outgoing = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	r := gin.New()
+	r.Use(Middleware("test"))
+	r.GET("/tasks/:id", func(c *gin.Context) {
+		req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, upstream.URL, nil)
+		resp, err := HTTPClient().Do(req)
+		if err != nil {
+			t.Errorf("outgoing call error = %v", err)
+			return
+		}
+		resp.Body.Close()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/42", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected server and client spans, got %d", len(spans))
+	}
+	var server sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		if s.SpanKind() == trace.SpanKindServer {
+			server = s
+		}
+	}
+	if server == nil || server.Name() != "/tasks/:id" {
+		t.Fatalf("expected a server span named after the route")
+	}
+	if got := server.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected the incoming trace id, got %s", got)
+	}
+	if len(outgoing) < 36 || outgoing[3:35] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected the trace to continue in the outgoing call, got %q", outgoing)
+	}
+}
+
+func TestMiddleware_SkipsRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := setupRecorder(t)
+
+	r := gin.New()
+	r.Use(Middleware("test", "/feeds/:token"))
+	r.GET("/feeds/:token", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/feeds/secret", nil))
+
+	if n := len(rec.Ended()); n != 0 {
+		t.Fatalf("expected no spans for a skipped route, got %d", n)
+	}
+}
+
+func TestSetup_RejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Fatalf("expected an error for an unknown exporter")
+	}
+}
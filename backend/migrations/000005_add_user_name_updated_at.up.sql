@@ -1,3 +1,3 @@
 ALTER TABLE users
   ADD COLUMN IF NOT EXISTS name text NOT NULL DEFAULT '',
-  ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
\ No newline at end of file
+  ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS access_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,19 @@
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS kind text NOT NULL DEFAULT 'human'
+    CHECK (kind IN ('human', 'service'));
+
+CREATE TABLE IF NOT EXISTS access_tokens (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name text NOT NULL,
+  prefix text NOT NULL,
+  token_hash text NOT NULL UNIQUE,
+  scopes text[] NOT NULL DEFAULT '{}',
+  project_id uuid REFERENCES projects(id) ON DELETE CASCADE,
+  expires_at timestamptz NOT NULL,
+  last_used_at timestamptz,
+  revoked_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_user_id ON access_tokens(user_id);
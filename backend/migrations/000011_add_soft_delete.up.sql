@@ -0,0 +1,6 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+-- the trash and the retention purge only ever look at deleted rows
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
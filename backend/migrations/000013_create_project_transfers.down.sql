@@ -0,0 +1,5 @@
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_owner_id_fkey;
+ALTER TABLE projects ADD CONSTRAINT projects_owner_id_fkey
+  FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP TABLE IF EXISTS project_transfers;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS project_transfers (
+  project_id uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+  from_user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  to_user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  requested_by uuid REFERENCES users(id) ON DELETE SET NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_transfers_to_user_id ON project_transfers(to_user_id);
+
+-- deleting a user no longer takes their projects along; their projects are
+-- reassigned first
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_owner_id_fkey;
+ALTER TABLE projects ADD CONSTRAINT projects_owner_id_fkey
+  FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_checklist_items;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,9 @@
+// Package migrations holds the SQL schema migrations, embedded so the api
+// binary can apply them itself. Files follow golang-migrate naming:
+// NNNNNN_description.up.sql and NNNNNN_description.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,128 @@
+// Package ical writes the subset of RFC 5545 iCalendar needed for read-only
+// feeds: components, properties with parameters, text escaping and line
+// folding.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest content line RFC 5545 allows, excluding CRLF.
+const maxLineOctets = 75
+
+// Writer emits content lines. Errors are sticky: after the first failed
+// write the rest are skipped and Flush reports it.
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Begin opens a component such as VCALENDAR or VTODO.
+func (w *Writer) Begin(component string) {
+	w.line("BEGIN:" + component)
+}
+
+// End closes a component opened with Begin.
+func (w *Writer) End(component string) {
+	w.line("END:" + component)
+}
+
+// Prop writes a property whose value is already in its iCalendar form, such
+// as a date or an enumerated value.
+func (w *Writer) Prop(name, value string, params ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	for i := 0; i+1 < len(params); i += 2 {
+		b.WriteString(";")
+		b.WriteString(params[i])
+		b.WriteString("=")
+		b.WriteString(params[i+1])
+	}
+	b.WriteString(":")
+	b.WriteString(value)
+	w.line(b.String())
+}
+
+// Text writes a TEXT property, escaping the value.
+func (w *Writer) Text(name, value string) {
+	w.Prop(name, EscapeText(value))
+}
+
+// DateTime writes a UTC DATE-TIME property.
+func (w *Writer) DateTime(name string, t time.Time) {
+	w.Prop(name, FormatDateTime(t))
+}
+
+// Date writes a DATE property, as used for all-day values.
+func (w *Writer) Date(name string, t time.Time) {
+	w.Prop(name, FormatDate(t), "VALUE", "DATE")
+}
+
+// Flush writes buffered lines and returns the first error seen.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// line folds a content line into chunks of at most 75 octets without
+// splitting a UTF-8 sequence; continuation lines start with a space.
+func (w *Writer) line(s string) {
+	if w.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.write(s[:cut])
+		w.write("\r\n ")
+		s = s[cut:]
+		// the leading space counts towards the continuation line
+		limit = maxLineOctets - 1
+	}
+	w.write(s)
+	w.write("\r\n")
+}
+
+func (w *Writer) write(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.w.WriteString(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// EscapeText escapes a TEXT value (RFC 5545 section 3.3.11).
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// FormatDateTime formats t as a UTC DATE-TIME.
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// FormatDate formats the calendar day of t as a DATE.
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
@@ -0,0 +1,68 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	got := EscapeText("a,b;c\\d\nnext\r\nlast")
+	want := `a\,b\;c\\d\nnext\nlast`
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWriter_FoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Text("SUMMARY", strings.Repeat("é", 100))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("expected CRLF line ending")
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folded output, got %q", out)
+	}
+	for i, l := range lines {
+		if len(l) > maxLineOctets {
+			t.Fatalf("line %d has %d octets", i, len(l))
+		}
+		if i > 0 && !strings.HasPrefix(l, " ") {
+			t.Fatalf("continuation line %d does not start with a space", i)
+		}
+		if !utf8.ValidString(l) {
+			t.Fatalf("line %d splits a UTF-8 sequence", i)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", "")
+	if unfolded != "SUMMARY:"+strings.Repeat("é", 100) {
+		t.Fatalf("unfolding did not restore the line: %q", unfolded)
+	}
+}
+
+func TestWriter_Component(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Begin("VTODO")
+	w.Date("DUE", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	w.DateTime("DTSTAMP", time.Date(2026, 4, 1, 12, 30, 0, 0, time.FixedZone("x", 2*3600)))
+	w.End("VTODO")
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "BEGIN:VTODO\r\nDUE;VALUE=DATE:20260401\r\nDTSTAMP:20260401T103000Z\r\nEND:VTODO\r\n"
+	if buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
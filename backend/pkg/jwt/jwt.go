@@ -2,61 +2,198 @@ package jwt
 
 import (
 	"errors"
-	"os"
+	"sync/atomic"
 	"time"
 
 	jwtlib "github.com/golang-jwt/jwt/v5"
 )
 
 var (
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrJWTSecretNotSet = errors.New("JWT_SECRET is not set")
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrNotConfigured = errors.New("JWT signing key is not configured")
 )
 
+// Settings controls how tokens are issued and verified.
+type Settings struct {
+	// SigningKey signs new tokens; it must hold a private key or secret.
+	SigningKey *Key
+	// VerificationKeys are accepted besides SigningKey, so tokens signed
+	// before a key rotation stay valid until they expire.
+	VerificationKeys []*Key
+	// TTL is the lifetime of access tokens.
+	TTL time.Duration
+	// Issuer and Audience are written to the iss and aud claims and
+	// required of every parsed token; empty values are neither.
+	Issuer   string
+	Audience string
+}
+
+type keyring struct {
+	Settings
+	byID map[string]*Key
+}
+
+var current atomic.Pointer[keyring]
+
+// Configure replaces the keys and claims tokens are issued and verified
+// with. Tokens cannot be issued or parsed before it is called.
+func Configure(s Settings) {
+	ring := &keyring{Settings: s, byID: map[string]*Key{}}
+	for _, k := range s.VerificationKeys {
+		ring.byID[k.id] = k
+	}
+	if s.SigningKey != nil {
+		ring.byID[s.SigningKey.id] = s.SigningKey
+	}
+	current.Store(ring)
+}
+
+func load() (*keyring, error) {
+	ring := current.Load()
+	if ring == nil || ring.SigningKey == nil || ring.SigningKey.sign == nil {
+		return nil, ErrNotConfigured
+	}
+	return ring, nil
+}
+
+// PublicKeys returns the keys other services verify tokens with. HMAC
+// secrets are never published.
+func PublicKeys() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	ring := current.Load()
+	if ring == nil {
+		return set
+	}
+	seen := map[string]bool{}
+	for _, k := range append([]*Key{ring.SigningKey}, ring.VerificationKeys...) {
+		if k == nil || k.jwk == nil || seen[k.id] {
+			continue
+		}
+		seen[k.id] = true
+		set.Keys = append(set.Keys, *k.jwk)
+	}
+	return set
+}
+
+// PurposeTwoFactor marks a challenge token issued after a correct password
+// for an account with two-factor authentication enabled.
+const PurposeTwoFactor = "2fa"
+
+// ChallengeTTL is how long a user has to enter their second factor.
+const ChallengeTTL = 5 * time.Minute
+
 type Claims struct {
 	UserID string `json:"userId"`
 	Role   string `json:"role"`
+	// MFA is set when the session was established with a second factor.
+	MFA bool `json:"mfa,omitempty"`
+	// Purpose restricts a token to one step of a flow. Access tokens have
+	// none, and ParseToken rejects any token that does.
+	Purpose string `json:"purpose,omitempty"`
 	jwtlib.RegisteredClaims
 }
 
-func GenerateToken(userID, role string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", ErrJWTSecretNotSet
-	}
+type TokenOption func(*Claims)
 
-	ttlMinutes := getTTLMinutes()
+// WithMFA marks the access token as issued after a second factor.
+func WithMFA() TokenOption {
+	return func(c *Claims) {
+		c.MFA = true
+	}
+}
 
+func GenerateToken(userID, role string, opts ...TokenOption) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Role:   role,
-		RegisteredClaims: jwtlib.RegisteredClaims{
-			ExpiresAt: jwtlib.NewNumericDate(time.Now().Add(time.Duration(ttlMinutes) * time.Minute)),
-			IssuedAt:  jwtlib.NewNumericDate(time.Now()),
-		},
+	}
+	for _, opt := range opts {
+		opt(&claims)
 	}
 
-	token := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	s, err := load()
+	if err != nil {
+		return "", err
+	}
+	return sign(s, claims, s.TTL)
 }
 
 func ParseToken(tokenString string) (*Claims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, ErrJWTSecretNotSet
+	claims, err := parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// GenerateChallengeToken issues the short-lived token exchanged, together
+// with a valid code, for an access token.
+func GenerateChallengeToken(userID string) (string, error) {
+	s, err := load()
+	if err != nil {
+		return "", err
+	}
+	return sign(s, Claims{UserID: userID, Purpose: PurposeTwoFactor}, ChallengeTTL)
+}
+
+// ParseChallengeToken returns the user id of a valid challenge token.
+func ParseChallengeToken(tokenString string) (string, error) {
+	claims, err := parse(tokenString)
+	if err != nil {
+		return "", err
+	}
+	if claims.Purpose != PurposeTwoFactor || claims.UserID == "" {
+		return "", ErrInvalidToken
+	}
+	return claims.UserID, nil
+}
+
+func sign(ring *keyring, claims Claims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.ExpiresAt = jwtlib.NewNumericDate(now.Add(ttl))
+	claims.IssuedAt = jwtlib.NewNumericDate(now)
+	claims.Issuer = ring.Issuer
+	if ring.Audience != "" {
+		claims.Audience = jwtlib.ClaimStrings{ring.Audience}
+	}
+
+	key := ring.SigningKey
+	token := jwtlib.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.sign)
+}
+
+func parse(tokenString string) (*Claims, error) {
+	ring, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwtlib.ParserOption{jwtlib.WithExpirationRequired()}
+	if ring.Issuer != "" {
+		opts = append(opts, jwtlib.WithIssuer(ring.Issuer))
+	}
+	if ring.Audience != "" {
+		opts = append(opts, jwtlib.WithAudience(ring.Audience))
 	}
 
 	token, err := jwtlib.ParseWithClaims(
 		tokenString,
 		&Claims{},
 		func(token *jwtlib.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwtlib.SigningMethodHMAC); !ok {
+			kid, _ := token.Header["kid"].(string)
+			key, ok := ring.byID[kid]
+			// the key decides the algorithm, never the token
+			if !ok || token.Method.Alg() != key.method.Alg() {
 				return nil, ErrInvalidToken
 			}
-			return []byte(secret), nil
+			return key.verify, nil
 		},
+		opts...,
 	)
-
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
@@ -68,16 +205,3 @@ func ParseToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
-
-func getTTLMinutes() int {
-	ttl := os.Getenv("JWT_TTL_MINUTES")
-	if ttl == "" {
-		return 60
-	}
-
-	if v, err := time.ParseDuration(ttl + "m"); err == nil {
-		return int(v.Minutes())
-	}
-
-	return 60
-}
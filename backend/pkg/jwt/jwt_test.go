@@ -1,13 +1,14 @@
 package jwt
 
 import (
-	"os"
 	"testing"
+	"time"
 )
 
+var testSettings = Settings{SigningKey: HMACKey("test", []byte("test-secret")), TTL: time.Hour}
+
 func TestGenerateAndParseToken_Success(t *testing.T) {
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	Configure(testSettings)
 
 	userID := "user-123"
 	role := "user"
@@ -40,8 +41,7 @@ func TestGenerateAndParseToken_Success(t *testing.T) {
 }
 
 func TestParseToken_InvalidToken(t *testing.T) {
-	t.Setenv("JWT_SECRET", "test-secret")
-	t.Setenv("JWT_TTL_MINUTES", "60")
+	Configure(testSettings)
 
 	token, err := GenerateToken("user-123", "user")
 	if err != nil {
@@ -61,10 +61,89 @@ func TestParseToken_InvalidToken(t *testing.T) {
 }
 
 func TestGenerateToken_MissingSecret(t *testing.T) {
-	_ = os.Unsetenv("JWT_SECRET")
+	Configure(Settings{})
+	t.Cleanup(func() { Configure(testSettings) })
 
 	_, err := GenerateToken("user-123", "user")
 	if err == nil {
 		t.Fatalf("GenerateToken() expected error, got nil")
 	}
 }
+
+func TestChallengeToken_RoundTrip(t *testing.T) {
+	Configure(testSettings)
+
+	token, err := GenerateChallengeToken("user-123")
+	if err != nil {
+		t.Fatalf("GenerateChallengeToken() error = %v", err)
+	}
+
+	userID, err := ParseChallengeToken(token)
+	if err != nil {
+		t.Fatalf("ParseChallengeToken() error = %v", err)
+	}
+	if userID != "user-123" {
+		t.Fatalf("userID = %q, want %q", userID, "user-123")
+	}
+}
+
+func TestParseToken_RejectsChallengeToken(t *testing.T) {
+	Configure(testSettings)
+
+	token, err := GenerateChallengeToken("user-123")
+	if err != nil {
+		t.Fatalf("GenerateChallengeToken() error = %v", err)
+	}
+
+	if _, err := ParseToken(token); err != ErrInvalidToken {
+		t.Fatalf("ParseToken() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestParseChallengeToken_RejectsAccessToken(t *testing.T) {
+	Configure(testSettings)
+
+	token, err := GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	if _, err := ParseChallengeToken(token); err != ErrInvalidToken {
+		t.Fatalf("ParseChallengeToken() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestGenerateToken_WithMFA(t *testing.T) {
+	Configure(testSettings)
+
+	token, err := GenerateToken("user-123", "admin", WithMFA())
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if !claims.MFA {
+		t.Fatalf("claims.MFA = false, want true")
+	}
+}
+
+func TestGenerateToken_UsesConfiguredTTL(t *testing.T) {
+	Configure(Settings{SigningKey: HMACKey("test", []byte("test-secret")), TTL: 5 * time.Minute})
+	t.Cleanup(func() { Configure(testSettings) })
+
+	token, err := GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+
+	if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != 5*time.Minute {
+		t.Fatalf("lifetime = %v, want 5m", got)
+	}
+}
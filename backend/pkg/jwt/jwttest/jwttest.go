@@ -0,0 +1,25 @@
+// Package jwttest configures package jwt for tests that issue or check
+// session tokens.
+package jwttest
+
+import (
+	"time"
+
+	jwtutil "task-management-platform/backend/pkg/jwt"
+)
+
+// Secret and KeyID make up the HS256 key Configure installs.
+const (
+	Secret = "test-secret"
+	KeyID  = "test"
+)
+
+// Settings signs HS256 with the test key; tokens live an hour.
+func Settings() jwtutil.Settings {
+	return jwtutil.Settings{SigningKey: jwtutil.HMACKey(KeyID, []byte(Secret)), TTL: time.Hour}
+}
+
+// Configure installs Settings.
+func Configure() {
+	jwtutil.Configure(Settings())
+}
@@ -0,0 +1,165 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+// MinRSABits is the smallest RSA modulus accepted for RS256.
+const MinRSABits = 2048
+
+// Key is a signing or verification key. Its id is sent as the "kid" header.
+// For public keys it is the RFC 7638 thumbprint, so the same key has the
+// same id wherever it is loaded; HMAC keys take a configured id, as any
+// digest of a shared secret would help guess it.
+type Key struct {
+	id     string
+	method jwtlib.SigningMethod
+	// sign is nil for verification-only keys.
+	sign   any
+	verify any
+	// jwk is the public key as published; nil for HMAC keys.
+	jwk *JWK
+}
+
+func (k *Key) ID() string { return k.id }
+
+// Algorithm is the JWS "alg" the key is used with: HS256, RS256 or EdDSA.
+func (k *Key) Algorithm() string { return k.method.Alg() }
+
+// HMACKey wraps a shared secret for HS256 under the key id id. Every
+// instance sharing the secret must use the same id. Anyone able to verify
+// tokens signed with it can also issue them.
+func HMACKey(id string, secret []byte) *Key {
+	return &Key{id: id, method: jwtlib.SigningMethodHS256, sign: secret, verify: secret}
+}
+
+// ParsePrivateKey reads a PEM-encoded RSA (PKCS #1 or #8) or Ed25519
+// (PKCS #8) private key, used with RS256 or EdDSA.
+func ParsePrivateKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var priv any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key %T", priv)
+	}
+	k, err := publicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	k.sign = priv
+	return k, nil
+}
+
+// ParsePublicKeys reads every PEM "PUBLIC KEY" block in data, for
+// verifying tokens signed by keys that have been rotated out.
+func ParsePublicKeys(data []byte) ([]*Key, error) {
+	var keys []*Key
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k, err := publicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM block found")
+	}
+	return keys, nil
+}
+
+func publicKey(pub any) (*Key, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < MinRSABits {
+			return nil, fmt.Errorf("RSA key has %d bits, want at least %d", pub.N.BitLen(), MinRSABits)
+		}
+		n, e := b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+		id := thumbprint(map[string]string{"kty": "RSA", "n": n, "e": e})
+		return &Key{
+			id:     id,
+			method: jwtlib.SigningMethodRS256,
+			verify: pub,
+			jwk:    &JWK{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: id, N: n, E: e},
+		}, nil
+	case ed25519.PublicKey:
+		x := b64(pub)
+		id := thumbprint(map[string]string{"kty": "OKP", "crv": "Ed25519", "x": x})
+		return &Key{
+			id:     id,
+			method: jwtlib.SigningMethodEdDSA,
+			verify: pub,
+			jwk:    &JWK{Kty: "OKP", Use: "sig", Alg: "EdDSA", Kid: id, Crv: "Ed25519", X: x},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key %T", pub)
+}
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// thumbprint is the RFC 7638 SHA-256 thumbprint of the required members of
+// a JWK. encoding/json sorts map keys, as the RFC requires.
+func thumbprint(members map[string]string) string {
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return b64(sum[:])
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
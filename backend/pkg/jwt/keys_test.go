@@ -0,0 +1,201 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+func pemPrivateKey(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func pemPublicKey(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func newEd25519Key(t *testing.T) (*Key, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key, err := ParsePrivateKey(pemPrivateKey(t, priv))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	return key, pemPublicKey(t, pub)
+}
+
+func useSettings(t *testing.T, s Settings) {
+	t.Helper()
+	Configure(s)
+	t.Cleanup(func() { Configure(testSettings) })
+}
+
+func TestAsymmetricKeys_SignWithKidAndVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	rs256, err := ParsePrivateKey(pemPrivateKey(t, rsaKey))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	eddsa, _ := newEd25519Key(t)
+
+	for _, key := range []*Key{rs256, eddsa} {
+		t.Run(key.Algorithm(), func(t *testing.T) {
+			useSettings(t, Settings{SigningKey: key, TTL: time.Hour, Issuer: "api", Audience: "web"})
+
+			token, err := GenerateToken("user-123", "user")
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			parsed, _, err := jwtlib.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified() error = %v", err)
+			}
+			if parsed.Header["kid"] != key.ID() || parsed.Header["alg"] != key.Algorithm() {
+				t.Fatalf("header = %v, want kid %s and alg %s", parsed.Header, key.ID(), key.Algorithm())
+			}
+
+			claims, err := ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims.Issuer != "api" || len(claims.Audience) != 1 || claims.Audience[0] != "web" {
+				t.Fatalf("iss, aud = %q, %v, want api, [web]", claims.Issuer, claims.Audience)
+			}
+		})
+	}
+}
+
+func TestRotation_OldKeyVerifiesUntilRemoved(t *testing.T) {
+	oldKey, oldPublic := newEd25519Key(t)
+	newKey, _ := newEd25519Key(t)
+
+	useSettings(t, Settings{SigningKey: oldKey, TTL: time.Hour})
+	token, err := GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	verification, err := ParsePublicKeys(oldPublic)
+	if err != nil {
+		t.Fatalf("ParsePublicKeys() error = %v", err)
+	}
+	if verification[0].ID() != oldKey.ID() {
+		t.Fatalf("public key id = %s, want the private key's %s", verification[0].ID(), oldKey.ID())
+	}
+
+	useSettings(t, Settings{SigningKey: newKey, VerificationKeys: verification, TTL: time.Hour})
+	if _, err := ParseToken(token); err != nil {
+		t.Fatalf("ParseToken() during rotation error = %v", err)
+	}
+	if got := PublicKeys().Keys; len(got) != 2 || got[0].Kid != newKey.ID() || got[1].Kid != oldKey.ID() {
+		t.Fatalf("JWKS = %+v, want the new and the old key", got)
+	}
+
+	useSettings(t, Settings{SigningKey: newKey, TTL: time.Hour})
+	if _, err := ParseToken(token); err != ErrInvalidToken {
+		t.Fatalf("ParseToken() after rotation error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestHMACKey_UsesConfiguredKid(t *testing.T) {
+	useSettings(t, Settings{SigningKey: HMACKey("hs-2024", []byte("test-secret")), TTL: time.Hour})
+	token, err := GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	parsed, _, err := jwtlib.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+	if parsed.Header["kid"] != "hs-2024" {
+		t.Fatalf("kid = %v, want hs-2024", parsed.Header["kid"])
+	}
+
+	useSettings(t, Settings{SigningKey: HMACKey("hs-2025", []byte("test-secret")), TTL: time.Hour})
+	if _, err := ParseToken(token); err != ErrInvalidToken {
+		t.Fatalf("ParseToken() with another kid error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestParseToken_RejectsWrongIssuerOrAudience(t *testing.T) {
+	useSettings(t, Settings{SigningKey: HMACKey("test", []byte("test-secret")), TTL: time.Hour, Issuer: "api", Audience: "web"})
+	token, err := GenerateToken("user-123", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	for name, s := range map[string]Settings{
+		"issuer":   {SigningKey: HMACKey("test", []byte("test-secret")), TTL: time.Hour, Issuer: "other", Audience: "web"},
+		"audience": {SigningKey: HMACKey("test", []byte("test-secret")), TTL: time.Hour, Issuer: "api", Audience: "other"},
+	} {
+		useSettings(t, s)
+		if _, err := ParseToken(token); err != ErrInvalidToken {
+			t.Fatalf("%s: ParseToken() error = %v, want %v", name, err, ErrInvalidToken)
+		}
+	}
+}
+
+func TestParseToken_RejectsAlgorithmOtherThanTheKeys(t *testing.T) {
+	key, publicPEM := newEd25519Key(t)
+	useSettings(t, Settings{SigningKey: key, TTL: time.Hour})
+
+	// an HS256 token keyed with the public key, claiming the EdDSA kid
+	forged := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, Claims{
+		UserID:           "user-123",
+		Role:             "admin",
+		RegisteredClaims: jwtlib.RegisteredClaims{ExpiresAt: jwtlib.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	forged.Header["kid"] = key.ID()
+	signed, err := forged.SignedString(publicPEM)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if _, err := ParseToken(signed); err != ErrInvalidToken {
+		t.Fatalf("ParseToken() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestPublicKeys_NeverPublishesHMACSecrets(t *testing.T) {
+	useSettings(t, testSettings)
+
+	if got := PublicKeys().Keys; len(got) != 0 {
+		t.Fatalf("JWKS = %+v, want no keys", got)
+	}
+}
+
+func TestParsePrivateKey_RejectsShortRSAKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	_, err = ParsePrivateKey(pemPrivateKey(t, rsaKey))
+	if err == nil || !strings.Contains(err.Error(), "1024 bits") {
+		t.Fatalf("ParsePrivateKey() error = %v, want a key size error", err)
+	}
+}
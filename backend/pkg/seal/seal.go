@@ -0,0 +1,79 @@
+// Package seal encrypts short secrets for storage with AES-256-GCM, so a
+// database dump alone does not reveal them.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// prefix marks sealed values and their format version. Values without it
+// were stored before sealing was introduced.
+const prefix = "v1:"
+
+// MinKeyLength is the shortest key New accepts.
+const MinKeyLength = 32
+
+var (
+	ErrShortKey = errors.New("seal: key must be at least 32 characters")
+	ErrInvalid  = errors.New("seal: value cannot be opened with this key")
+)
+
+// Box seals and opens values with one key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New derives the AES-256 key from key with SHA-256; key should be random.
+func New(key string) (*Box, error) {
+	if len(key) < MinKeyLength {
+		return nil, ErrShortKey
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext under a fresh random nonce.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+// Open decrypts a value made by Seal.
+func (b *Box) Open(sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", ErrInvalid
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, prefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrInvalid
+	}
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value looks like the output of Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
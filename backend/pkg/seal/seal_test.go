@@ -0,0 +1,61 @@
+package seal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef"
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	b, err := New(testKey)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	first, err := b.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	second, _ := b.Seal("JBSWY3DPEHPK3PXP")
+	if first == second {
+		t.Fatalf("Seal() repeated its output; want a fresh nonce each time")
+	}
+	if strings.Contains(first, "JBSWY3DPEHPK3PXP") || !IsSealed(first) {
+		t.Fatalf("Seal() = %q, want an opaque sealed value", first)
+	}
+
+	got, err := b.Open(first)
+	if err != nil || got != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Open() = %q, %v; want the plaintext", got, err)
+	}
+}
+
+func TestOpenRejectsOtherKeysAndTampering(t *testing.T) {
+	b, _ := New(testKey)
+	other, _ := New(strings.Repeat("x", MinKeyLength))
+
+	sealed, _ := b.Seal("secret")
+	if _, err := other.Open(sealed); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Open() with another key err = %v, want ErrInvalid", err)
+	}
+
+	last := "A"
+	if strings.HasSuffix(sealed, "A") {
+		last = "B"
+	}
+	tampered := sealed[:len(sealed)-1] + last
+	if _, err := b.Open(tampered); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Open() tampered err = %v, want ErrInvalid", err)
+	}
+	if _, err := b.Open("JBSWY3DPEHPK3PXP"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Open() plaintext err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestNewRejectsShortKeys(t *testing.T) {
+	if _, err := New("short"); !errors.Is(err, ErrShortKey) {
+		t.Fatalf("New() err = %v, want ErrShortKey", err)
+	}
+}
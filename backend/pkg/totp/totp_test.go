@@ -0,0 +1,87 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B uses the ASCII secret "12345678901234567890" with
+// 8-digit codes; the 6-digit code is the last six digits.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeAt_RFC6238Vectors(t *testing.T) {
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range cases {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt(%d) error = %v", tc.unix, err)
+		}
+		if got != tc.want {
+			t.Fatalf("CodeAt(%d) = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidate_AllowsSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	prev, _ := CodeAt(rfcSecret, Step(now)-1)
+	step, ok := Validate(rfcSecret, prev, now, 1)
+	if !ok {
+		t.Fatalf("Validate() rejected code from previous step")
+	}
+	if step != Step(now)-1 {
+		t.Fatalf("Validate() step = %d, want %d", step, Step(now)-1)
+	}
+
+	old, _ := CodeAt(rfcSecret, Step(now)-3)
+	if _, ok := Validate(rfcSecret, old, now, 1); ok {
+		t.Fatalf("Validate() accepted code outside skew window")
+	}
+}
+
+func TestValidate_RejectsMalformedCode(t *testing.T) {
+	if _, ok := Validate(rfcSecret, "12345", time.Now(), 1); ok {
+		t.Fatalf("Validate() accepted short code")
+	}
+	if _, ok := Validate("not base32!", "123456", time.Now(), 1); ok {
+		t.Fatalf("Validate() accepted invalid secret")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Fatalf("len(secret) = %d, want 32", len(secret))
+	}
+
+	u, err := url.Parse(URI("Task Manager", "admin@test.com", secret))
+	if err != nil {
+		t.Fatalf("URI() not parseable: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Fatalf("URI() = %s, want otpauth://totp/...", u)
+	}
+	if !strings.Contains(u.Path, "Task Manager:admin@test.com") {
+		t.Fatalf("URI() label = %q", u.Path)
+	}
+	if u.Query().Get("secret") != secret {
+		t.Fatalf("URI() secret = %q, want %q", u.Query().Get("secret"), secret)
+	}
+}
@@ -1,6 +1,9 @@
 import { useState } from "react";
-import { changePassword, exportAdminDataZip } from "./api";
+import { changePassword, exportAdminDataZip, startSsoLink } from "./api";
 import { useAuth } from "../auth/useAuth";
+import { getErrorMessage } from "../../lib/errors";
+
+const ssoEnabled = import.meta.env.VITE_OIDC_ENABLED === "true";
 
 export function AccountPage() {
   const [currentPassword, setCurrentPassword] = useState("");
@@ -37,10 +40,7 @@ export function AccountPage() {
       setNewPassword("");
       setConfirm("");
     } catch (err: any) {
-      const msg =
-        err?.response?.data?.message ??
-        "Failed to update password.";
-      setError(msg);
+      setError(getErrorMessage(err, "Failed to update password."));
     } finally {
       setIsLoading(false);
     }
@@ -107,6 +107,24 @@ export function AccountPage() {
           {isLoading ? "Updating..." : "Update password"}
         </button>
       </form>
+      {ssoEnabled && (
+        <div className="mt-6 rounded-lg border bg-white p-4">
+          <div className="text-sm font-medium">Single sign-on</div>
+          <p className="mt-1 text-sm text-slate-600">
+            Link your identity provider account so you can sign in with SSO. Its email must match this account.
+          </p>
+
+          <div className="mt-3">
+            <button
+              type="button"
+              onClick={() => startSsoLink().catch((err) => setError(getErrorMessage(err, "Failed to start linking.")))}
+              className="rounded-md border px-3 py-2 text-sm hover:bg-slate-50"
+            >
+              Link SSO account
+            </button>
+          </div>
+        </div>
+      )}
       {isAdmin && (
         <div className="mt-6 rounded-lg border bg-white p-4">
           <div className="text-sm font-medium">Admin</div>
@@ -4,6 +4,14 @@ export async function changePassword(currentPassword: string, newPassword: strin
   await api.post("/auth/change-password", { currentPassword, newPassword });
 }
 
+// The provider flow needs a top-level navigation, which cannot carry the
+// bearer token, so the API hands out a short-lived ticket for it instead.
+export async function startSsoLink() {
+  const res = await api.post<{ ticket: string }>("/auth/oidc/link");
+  const ticket = encodeURIComponent(res.data.ticket);
+  window.location.href = `${import.meta.env.VITE_API_URL}/auth/oidc/link?ticket=${ticket}`;
+}
+
 export async function exportAdminDataZip() {
   const res = await api.get("/api/admin/export", { responseType: "blob" });
 
@@ -1,7 +1,7 @@
 import { createContext, useContext, useEffect, useState } from "react";
 import api from "../../lib/api";
 import { storage } from "../../lib/storage";
-import type { AuthContextValue, AuthUser } from "./types";
+import type { AuthContextValue, AuthUser, LoginOutcome } from "./types";
 
 const AuthContext = createContext<AuthContextValue | null>(null);
 
@@ -48,11 +48,36 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
       .finally(() => setIsLoading(false));
   }, []);
 
-  async function login(email: string, password: string) {
+  async function login(email: string, password: string): Promise<LoginOutcome> {
     setIsLoading(true);
     try {
       const res = await api.post("/auth/login", { email, password });
+      if (res.data.twoFactorRequired) {
+        return { challengeToken: res.data.challengeToken };
+      }
       await setSessionFromToken(res.data.accessToken);
+      return {};
+    } finally {
+      setIsLoading(false);
+    }
+  }
+
+  async function completeTwoFactorLogin(challengeToken: string, code: string) {
+    setIsLoading(true);
+    try {
+      const res = await api.post("/auth/login/2fa", { challengeToken, code });
+      await setSessionFromToken(res.data.accessToken);
+    } finally {
+      setIsLoading(false);
+    }
+  }
+
+  // loginWithToken finishes a single sign-on redirect, which hands back the
+  // access token in the URL fragment.
+  async function loginWithToken(token: string) {
+    setIsLoading(true);
+    try {
+      await setSessionFromToken(token);
     } finally {
       setIsLoading(false);
     }
@@ -76,7 +101,7 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
   }
 
   return (
-    <AuthContext.Provider value={{ user, isAuthenticated, isLoading, login, register, logout }}>
+    <AuthContext.Provider value={{ user, isAuthenticated, isLoading, login, completeTwoFactorLogin, loginWithToken, register, logout }}>
       {children}
     </AuthContext.Provider>
   );
@@ -1,18 +1,49 @@
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useAuth } from "./useAuth";
 import { useNavigate } from "react-router-dom";
 import { useSnackbar } from "../../components/snackbar/SnackbarContext";
+import { getErrorMessage } from "../../lib/errors";
 
 type Mode = "login" | "register";
 
+const ssoEnabled = import.meta.env.VITE_OIDC_ENABLED === "true";
+
 export default function LoginPage() {
-  const { login, register, isLoading } = useAuth();
+  const { login, completeTwoFactorLogin, loginWithToken, register, isLoading } = useAuth();
   const navigate = useNavigate();
   const { showError, showSuccess } = useSnackbar();
 
   const [mode, setMode] = useState<Mode>("login");
   const [email, setEmail] = useState("");
   const [password, setPassword] = useState("");
+  const [challengeToken, setChallengeToken] = useState<string | null>(null);
+  const [code, setCode] = useState("");
+
+  // The single sign-on callback redirects here with #accessToken=…, #linked=1
+  // or #error=….
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    const token = params.get("accessToken");
+    const linked = params.get("linked");
+    const error = params.get("error");
+    if (!token && !linked && !error) return;
+
+    window.history.replaceState(null, "", window.location.pathname);
+
+    if (error) {
+      showError(error);
+      return;
+    }
+    if (linked) {
+      showSuccess("Single sign-on linked");
+      navigate("/account");
+      return;
+    }
+    loginWithToken(token!)
+      .then(() => navigate("/"))
+      .catch(() => showError("Single sign-on failed"));
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
 
   function isValidEmail(value: string) {
     return /\S+@\S+\.\S+/.test(value);
@@ -27,8 +58,15 @@ export default function LoginPage() {
   }
 
     try {
-      if (mode === "login") {
-        await login(email.trim(), password);
+      if (challengeToken) {
+        await completeTwoFactorLogin(challengeToken, code.trim());
+        navigate("/");
+      } else if (mode === "login") {
+        const outcome = await login(email.trim(), password);
+        if (outcome.challengeToken) {
+          setChallengeToken(outcome.challengeToken);
+          return;
+        }
         navigate("/");
       } else {
         await register(email.trim(), password);
@@ -37,10 +75,7 @@ export default function LoginPage() {
       }
     } catch (err: any) {
       showError(
-        err?.response?.data?.message ??
-          err?.response?.data?.error ??
-          err?.message ??
-          (mode === "login" ? "Login failed" : "Register failed")
+        getErrorMessage(err, mode === "login" ? "Login failed" : "Register failed")
       );
     }
   }
@@ -51,43 +86,87 @@ export default function LoginPage() {
         {mode === "login" ? "Login" : "Create account"}
       </h1>
 
-      <form onSubmit={handleSubmit} className="space-y-3">
-        <input
-          type="email"
-          className="border p-2 w-full rounded-md"
-          placeholder="Email"
-          value={email}
-          onChange={(e) => setEmail(e.target.value)}
-          disabled={isLoading}
-          required
-        />
-
-        <input
-          className="border p-2 w-full rounded-md"
-          type="password"
-          placeholder="Password"
-          value={password}
-          onChange={(e) => setPassword(e.target.value)}
-          disabled={isLoading}
-        />
-
-        <button
-          className="bg-black text-white px-4 py-2 w-full rounded-md disabled:opacity-60"
-          disabled={isLoading || !email.trim() || !password}
-          type="submit"
-        >
-          {isLoading ? "Loading…" : mode === "login" ? "Login" : "Create account"}
-        </button>
-
-        <button
-          type="button"
-          className="w-full text-sm text-slate-600 hover:text-slate-900"
-          onClick={() => setMode((m) => (m === "login" ? "register" : "login"))}
-          disabled={isLoading}
-        >
-          {mode === "login" ? "No account? Create one" : "Already have an account? Login"}
-        </button>
-      </form>
+      {challengeToken ? (
+        <form onSubmit={handleSubmit} className="space-y-3">
+          <input
+            className="border p-2 w-full rounded-md"
+            inputMode="numeric"
+            autoComplete="one-time-code"
+            placeholder="Authentication or recovery code"
+            value={code}
+            onChange={(e) => setCode(e.target.value)}
+            disabled={isLoading}
+            autoFocus
+          />
+
+          <button
+            className="bg-black text-white px-4 py-2 w-full rounded-md disabled:opacity-60"
+            disabled={isLoading || !code.trim()}
+            type="submit"
+          >
+            {isLoading ? "Loading…" : "Verify"}
+          </button>
+
+          <button
+            type="button"
+            className="w-full text-sm text-slate-600 hover:text-slate-900"
+            onClick={() => {
+              setChallengeToken(null);
+              setCode("");
+            }}
+            disabled={isLoading}
+          >
+            Back to login
+          </button>
+        </form>
+      ) : (
+        <form onSubmit={handleSubmit} className="space-y-3">
+          <input
+            type="email"
+            className="border p-2 w-full rounded-md"
+            placeholder="Email"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+            disabled={isLoading}
+            required
+          />
+
+          <input
+            className="border p-2 w-full rounded-md"
+            type="password"
+            placeholder="Password"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            disabled={isLoading}
+          />
+
+          <button
+            className="bg-black text-white px-4 py-2 w-full rounded-md disabled:opacity-60"
+            disabled={isLoading || !email.trim() || !password}
+            type="submit"
+          >
+            {isLoading ? "Loading…" : mode === "login" ? "Login" : "Create account"}
+          </button>
+
+          <button
+            type="button"
+            className="w-full text-sm text-slate-600 hover:text-slate-900"
+            onClick={() => setMode((m) => (m === "login" ? "register" : "login"))}
+            disabled={isLoading}
+          >
+            {mode === "login" ? "No account? Create one" : "Already have an account? Login"}
+          </button>
+
+          {ssoEnabled && mode === "login" && (
+            <a
+              className="block text-center border px-4 py-2 w-full rounded-md hover:bg-slate-50"
+              href={`${import.meta.env.VITE_API_URL}/auth/oidc/login`}
+            >
+              Sign in with SSO
+            </a>
+          )}
+        </form>
+      )}
     </div>
   );
 }
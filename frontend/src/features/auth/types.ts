@@ -4,11 +4,19 @@ export type AuthUser = {
   role: string;
 };
 
+// challengeToken is set when the account has two-factor enabled and the
+// login has to be finished with completeTwoFactorLogin.
+export type LoginOutcome = {
+  challengeToken?: string;
+};
+
 export interface AuthContextValue {
   user: AuthUser | null;
   isAuthenticated: boolean;
   isLoading: boolean;
-  login: (email: string, password: string) => Promise<void>;
+  login: (email: string, password: string) => Promise<LoginOutcome>;
+  completeTwoFactorLogin: (challengeToken: string, code: string) => Promise<void>;
+  loginWithToken: (token: string) => Promise<void>;
   register: (email: string, password: string) => Promise<void>;
   logout: () => void;
 }
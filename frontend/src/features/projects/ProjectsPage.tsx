@@ -2,6 +2,7 @@ import { useEffect, useState } from "react";
 import { listProjects, createProject } from "./api";
 import type { Project } from "./types";
 import { useSnackbar } from "../../components/snackbar/SnackbarContext";
+import { getErrorMessage } from "../../lib/errors";
 
 export default function ProjectsPage() {
   const { showError, showSuccess } = useSnackbar();
@@ -18,13 +19,7 @@ export default function ProjectsPage() {
       const data = await listProjects();
       setProjects(data);
     } catch (err: any) {
-      const msg =
-        err?.response?.data?.message ??
-        err?.response?.data?.error ??
-        err?.message ??
-        "Failed to load projects.";
-
-      showError(msg);
+      showError(getErrorMessage(err, "Failed to load projects."));
     } finally {
       setLoading(false);
     }
@@ -41,13 +36,7 @@ export default function ProjectsPage() {
       showSuccess("Project created");
       await loadProjects();
     } catch (err: any) {
-      const msg =
-        err?.response?.data?.message ??
-        err?.response?.data?.error ??
-        err?.message ??
-        "Failed to create project.";
-
-      showError(msg);
+      showError(getErrorMessage(err, "Failed to create project."));
     } finally {
       setCreating(false);
     }
@@ -9,6 +9,7 @@ import {
 import type { Project, Task } from "./types";
 import { useSnackbar } from "../../components/snackbar/SnackbarContext";
 import { useAuthContext } from "../auth/AuthContext";
+import { getErrorMessage } from "../../lib/errors";
 
 const STATUS_LABELS: Record<string, string> = {
   todo: "To do",
@@ -54,15 +55,6 @@ function ProjectBadge({ name }: { name: string }) {
   );
 }
 
-function getErrorMessage(err: any, fallback: string) {
-  return (
-    err?.response?.data?.message ??
-    err?.response?.data?.error ??
-    err?.message ??
-    fallback
-  );
-}
-
 type StatusFilter = "all" | "todo" | "in_progress" | "done";
 
 export default function TasksPage() {
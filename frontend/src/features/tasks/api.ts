@@ -41,6 +41,8 @@ export type UpdateTaskInput = {
   description: string;
   status: string;
   assigneeId?: string | null;
+  // omitted keeps the stored due date; null clears it
+  dueDate?: string | null;
 };
 
 export type AssignableUser = { id: string; email: string };
\ No newline at end of file
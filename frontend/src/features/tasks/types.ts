@@ -10,6 +10,7 @@ export type Task = {
   description?: string;
   status: string;
   assigneeId: string | null;
+  dueDate?: string | null;
   createdAt?: string;
   updatedAt?: string;
 };
@@ -19,5 +20,5 @@ export type CreateTaskInput = {
   title: string;
   description?: string;
   status?: string;
-  assignee_id?: string;
+  assigneeId?: string;
 };
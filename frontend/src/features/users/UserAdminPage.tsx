@@ -3,21 +3,13 @@ import { listUsers, updateUserRole } from "./api";
 import type { User, UserRole } from "./types";
 import { useAuthContext } from "../auth/AuthContext";
 import { useSnackbar } from "../../components/snackbar/SnackbarContext";
+import { getErrorMessage } from "../../lib/errors";
 
 const ROLE_OPTIONS: { value: UserRole; label: string }[] = [
   { value: "user", label: "User" },
   { value: "admin", label: "Admin" },
 ];
 
-function getErrorMessage(err: any, fallback: string) {
-  return (
-    err?.response?.data?.message ??
-    err?.response?.data?.error ??
-    err?.message ??
-    fallback
-  );
-}
-
 export default function UsersAdminPage() {
   const { user: sessionUser } = useAuthContext();
   const { showError, showSuccess } = useSnackbar();
@@ -0,0 +1,32 @@
+// Every API error is an RFC 9457 problem+json document; see SOLUTION.md.
+export type FieldError = {
+  field: string;
+  code: string;
+  message: string;
+};
+
+export type Problem = {
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  code: string;
+  requestId?: string;
+  errors?: FieldError[];
+};
+
+export function getProblem(err: any): Problem | undefined {
+  const data = err?.response?.data;
+  return data && typeof data.code === "string" ? (data as Problem) : undefined;
+}
+
+export function getErrorMessage(err: any, fallback: string): string {
+  const problem = getProblem(err);
+  return (
+    problem?.errors?.[0]?.message ??
+    problem?.detail ??
+    problem?.title ??
+    err?.message ??
+    fallback
+  );
+}